@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/service"
+	"io"
+	"os"
+	"strconv"
+)
+
+func runCommand(args []string, transfer service.CatalogTransferService, reviews service.ReviewsService, users service.UserService) error {
+	switch args[0] {
+	case "export":
+		return runExport(args[1:], transfer)
+	case "import":
+		return runImport(args[1:], transfer)
+	case "rebuild-ratings":
+		count, err := reviews.RebuildRatings()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("рейтинги пересчитаны для %d категорий\n", count)
+		return nil
+	case "set-role":
+		return runSetRole(args[1:], users)
+	}
+
+	return fmt.Errorf("неизвестная команда %q, доступны: export, import, rebuild-ratings, set-role", args[0])
+}
+
+// runSetRole changes a role without an admin, which is how the first admin
+// is appointed.
+func runSetRole(args []string, users service.UserService) error {
+	if len(args) != 2 {
+		return errors.New("использование: set-role <id пользователя> <user|coach|admin>")
+	}
+
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("некорректный ID пользователя %q", args[0])
+	}
+
+	user, err := users.SetRole(uint(id), args[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("пользователю %d назначена роль %s\n", user.ID, user.Role)
+	return nil
+}
+
+func runExport(args []string, transfer service.CatalogTransferService) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "json", "формат выгрузки: json или csv (zip-архив)")
+	output := flags.String("o", "", "файл для записи, по умолчанию stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	switch *format {
+	case "csv":
+		return transfer.ExportCSV(w)
+	case "json":
+		doc, err := transfer.Export()
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(doc)
+	}
+
+	return fmt.Errorf("неизвестный формат %q", *format)
+}
+
+func runImport(args []string, transfer service.CatalogTransferService) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "проверить документ без записи в базу")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return errors.New("укажите путь к JSON-документу каталога или - для stdin")
+	}
+
+	var r io.Reader = os.Stdin
+	if path := flags.Arg(0); path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	var doc models.CatalogDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return fmt.Errorf("не удалось прочитать документ каталога: %w", err)
+	}
+
+	report, err := transfer.Import(&doc, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("импорт отклонён: %d ошибок", len(report.Errors))
+	}
+
+	return nil
+}
@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"healthy_body/internal/config"
 	"healthy_body/internal/models"
 	"healthy_body/internal/repository"
 	"healthy_body/internal/service"
+	"healthy_body/internal/storage"
 	"healthy_body/internal/transport"
 	"log"
 	"log/slog"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	_ "healthy_body/internal/docs"
@@ -33,6 +37,14 @@ func main() {
 		MaxAge:           12 * time.Hour,
 	}))
 
+	if err := repository.PrepareReviewTargets(db); err != nil {
+		log.Fatalf("не удалось подготовить отзывы к миграции: %v", err)
+	}
+
+	if err := repository.PrepareMinorUnits(db); err != nil {
+		log.Fatalf("не удалось перевести суммы в копейки: %v", err)
+	}
+
 	if err := db.AutoMigrate(
 		&models.Categories{},
 		&models.Subscription{},
@@ -41,13 +53,41 @@ func main() {
 		&models.UserSubscription{},
 		&models.ExercisePlan{},
 		&models.ExercisePlanItem{},
+		&models.ProgressionRule{},
 		&models.MealPlan{},
 		&models.MealPlanItem{},
 		&models.Reviews{},
+		&models.CatalogVersion{},
+		&models.NotificationOutbox{},
+		&models.NotificationPreference{},
+		&models.InboxNotification{},
+		&models.Gift{},
+		&models.PromoCode{},
+		&models.PromoRestriction{},
+		&models.PromoRedemption{},
+		&models.CartItem{},
+		&models.Order{},
+		&models.OrderItem{},
+		&models.ReceiptCounter{},
+		&models.CatalogPrice{},
+		&models.UserBalance{},
+		&models.CategoryRating{},
+		&models.TargetRating{},
+		&models.ReviewReply{},
+		&models.ReviewVote{},
+		&models.ReviewReport{},
+		&models.Attachment{},
+		&models.CoachProfile{},
+		&models.CoachEarning{},
+		&models.CoachPayout{},
 	); err != nil {
 		log.Fatalf("не удалось выполнить миграции: %v", err)
 	}
 
+	if err := repository.PrepareExternalKeys(db); err != nil {
+		log.Fatalf("не удалось присвоить ключи каталогу: %v", err)
+	}
+
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{}))
 
 	categoryRepo := repository.NewCategoryRepo(db, logger)
@@ -56,21 +96,75 @@ func main() {
 	mealPlanItemRepo := repository.NewMealPlanItemRepository(db, logger)
 	subRepo := repository.NewSubscriptionRepo(db, logger)
 	reviewsRepo := repository.NewReviewsRepository(db, logger)
+	versionRepo := repository.NewCatalogVersionRepository(db, logger)
+	transferRepo := repository.NewCatalogTransferRepository(db, logger)
+	outboxRepo := repository.NewNotificationOutboxRepository(db, logger)
+	notificationRepo := repository.NewNotificationRepository(db, logger)
+	giftRepo := repository.NewGiftRepository(db, logger)
+	promoRepo := repository.NewPromoCodeRepository(db, logger)
+	cartRepo := repository.NewCartRepository(db, logger)
+	orderRepo := repository.NewOrderRepository(db, logger)
+	priceRepo := repository.NewPriceRepository(db, logger)
+	userSubRepo := repository.NewUserSubscriptionRepository(db, logger)
+	attachmentRepo := repository.NewAttachmentRepository(db, logger)
+	coachRepo := repository.NewCoachRepository(db, logger)
+	recommendationRepo := repository.NewRecommendationRepository(db, logger)
 
 	categoryServices := service.NewCategoryServices(categoryRepo, logger)
 	planServices := service.NewExercisePlanServices(planRepo, logger, categoryServices)
 	mealPlanService := service.NewMealPlanService(mealPlanRepo, logger, categoryServices)
-	mealPlanItemService := service.NewMealPlanItemsService(mealPlanItemRepo, logger)
+	mealPlanItemService := service.NewMealPlanItemsService(mealPlanItemRepo, mealPlanRepo, logger)
 	userRepo := repository.NewUserRepository(db, logger)
 	subService := service.NewSubscriptionService(subRepo, logger, categoryServices)
-	notificationService := service.NewEmailNotificationService(
-		os.Getenv("EMAIL_USER"),
-		os.Getenv("EMAIL_PASS"),
-		os.Getenv("EMAIL_HOST"),
-		587,
-		logger)
-	userService := service.NewUserService(userRepo, logger, db, subService, categoryRepo, notificationService)
-	reviewsService := service.NewReviewsService(reviewsRepo, logger)
+	channels := []service.NotificationChannel{
+		service.NewInAppChannel(notificationRepo, logger),
+		service.NewWebhookChannel(&http.Client{Timeout: 10 * time.Second}, logger),
+	}
+	if os.Getenv("EMAIL_HOST") != "" {
+		channels = append(channels, service.NewEmailChannel(
+			os.Getenv("EMAIL_USER"),
+			os.Getenv("EMAIL_PASS"),
+			os.Getenv("EMAIL_HOST"),
+			587,
+			logger))
+	} else {
+		channels = append(channels, service.NewLogChannel(os.Getenv("NOTIFY_LOG_FILE"), logger))
+	}
+	notificationService := service.NewNotificationService(notificationRepo, userRepo, logger, channels...)
+	giftService := service.NewGiftService(giftRepo, userRepo, db, logger)
+	promoService := service.NewPromoService(promoRepo, db, logger)
+	cartService := service.NewCartService(cartRepo, userRepo, db, logger)
+	orderService := service.NewOrderService(orderRepo, userRepo, logger)
+	priceService := service.NewPriceService(priceRepo, db, logger)
+	walletService := service.NewWalletService(userRepo, db, logger)
+	userSubService := service.NewUserSubscriptionService(userSubRepo, userRepo, db, logger)
+	userService := service.NewUserService(userRepo, logger, db, subService, categoryRepo, versionRepo, giftService)
+	versionService := service.NewCatalogVersionService(versionRepo, logger, categoryServices, planServices, mealPlanService)
+	transferService := service.NewCatalogTransferService(transferRepo, logger)
+	outboxService := service.NewNotificationOutboxService(outboxRepo, notificationService, logger)
+	reviewsService := service.NewReviewsService(reviewsRepo, logger, outboxService,
+		service.NewWordListFilter(strings.Split(os.Getenv("REVIEW_STOP_WORDS"), ",")),
+		service.NewLinkFilter(),
+		service.NewDuplicateFilter(reviewsRepo))
+
+	uploadDir := os.Getenv("UPLOAD_DIR")
+	if uploadDir == "" {
+		uploadDir = "uploads"
+	}
+	blobs, err := storage.NewLocalStorage(uploadDir)
+	if err != nil {
+		log.Fatalf("не удалось подготовить хранилище файлов: %v", err)
+	}
+	attachmentService := service.NewAttachmentService(attachmentRepo, reviewsRepo, blobs, logger)
+	coachService := service.NewCoachService(coachRepo, userRepo, categoryRepo, attachmentService, logger)
+	recommendationService := service.NewRecommendationService(recommendationRepo, userRepo, categoryRepo, logger)
+
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], transferService, reviewsService, userService); err != nil {
+			log.Fatalf("ошибка выполнения команды: %v", err)
+		}
+		return
+	}
 
 	if tableList, err := db.Migrator().GetTables(); err == nil {
 		fmt.Println("tables:", tableList)
@@ -86,5 +180,30 @@ func main() {
 		userService,
 		subService,
 		reviewsService,
+		versionService,
+		transferService,
+		outboxService,
+		notificationService,
+		giftService,
+		promoService,
+		cartService,
+		orderService,
+		priceService,
+		walletService,
+		userSubService,
+		attachmentService,
+		coachService,
+		recommendationService,
 	)
 
+	go outboxService.Run(context.Background(), 10*time.Second)
+	go subService.RunExpiryReminders(context.Background(), time.Hour, 3*24*time.Hour)
+	go giftService.RunExpiry(context.Background(), time.Hour)
+	go userSubService.RunRenewals(context.Background(), 10*time.Minute)
+
+	server.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	if err := server.Run(":" + os.Getenv("PORT")); err != nil {
+		log.Fatalf("не удалось запустить сервер: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package calculator
+
+import "healthy_body/internal/models"
+
+// WHO BMI classes.
+const (
+	BMISevereThinness   = "severe_thinness"
+	BMIModerateThinness = "moderate_thinness"
+	BMIMildThinness     = "mild_thinness"
+	BMINormal           = "normal"
+	BMIPreObese         = "pre_obese"
+	BMIObeseI           = "obese_1"
+	BMIObeseII          = "obese_2"
+	BMIObeseIII         = "obese_3"
+)
+
+type BMIInput struct {
+	Units  string  `json:"units"`
+	Height float64 `json:"height"`
+	Weight float64 `json:"weight"`
+}
+
+type BMIResult struct {
+	BMI   float64 `json:"bmi"`
+	Class string  `json:"class"`
+	Band  string  `json:"band"`
+	Label string  `json:"label"`
+}
+
+func BMI(input BMIInput, language string) (*BMIResult, error) {
+	units, err := normalizeUnits(input.Units)
+	if err != nil {
+		return nil, err
+	}
+	heightCm, err := lengthCm("height", input.Height, units, 50, 272)
+	if err != nil {
+		return nil, err
+	}
+	weightKg, err := massKg("weight", input.Weight, units, 2, 650)
+	if err != nil {
+		return nil, err
+	}
+
+	bmi := models.BMI(weightKg, heightCm)
+	class := BMIClass(bmi)
+	return &BMIResult{
+		BMI:   bmi,
+		Class: class,
+		Band:  models.BMIBand(bmi),
+		Label: Label(class, language),
+	}, nil
+}
+
+// BMIClass is the WHO class of an adult BMI.
+func BMIClass(bmi float64) string {
+	switch {
+	case bmi < 16:
+		return BMISevereThinness
+	case bmi < 17:
+		return BMIModerateThinness
+	case bmi < 18.5:
+		return BMIMildThinness
+	case bmi < 25:
+		return BMINormal
+	case bmi < 30:
+		return BMIPreObese
+	case bmi < 35:
+		return BMIObeseI
+	case bmi < 40:
+		return BMIObeseII
+	}
+
+	return BMIObeseIII
+}
@@ -0,0 +1,53 @@
+package calculator
+
+import "testing"
+
+func TestBMIClass(t *testing.T) {
+	tests := []struct {
+		bmi  float64
+		want string
+	}{
+		{15.9, BMISevereThinness},
+		{16, BMIModerateThinness},
+		{17, BMIMildThinness},
+		{18.4, BMIMildThinness},
+		{18.5, BMINormal},
+		{24.9, BMINormal},
+		{25, BMIPreObese},
+		{30, BMIObeseI},
+		{35, BMIObeseII},
+		{39.9, BMIObeseII},
+		{40, BMIObeseIII},
+	}
+
+	for _, tt := range tests {
+		if got := BMIClass(tt.bmi); got != tt.want {
+			t.Errorf("BMIClass(%v) = %q, want %q", tt.bmi, got, tt.want)
+		}
+	}
+}
+
+func TestBMI(t *testing.T) {
+	tests := []struct {
+		name  string
+		input BMIInput
+		want  float64
+		class string
+	}{
+		{"metric", BMIInput{Height: 180, Weight: 100}, 30.86, BMIObeseI},
+		{"imperial", BMIInput{Units: UnitsImperial, Height: 180 / cmPerInch, Weight: 100 / kgPerLb}, 30.86, BMIObeseI},
+		{"normal", BMIInput{Units: UnitsMetric, Height: 170, Weight: 65}, 22.49, BMINormal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BMI(tt.input, "en")
+			if err != nil {
+				t.Fatalf("BMI() error = %v", err)
+			}
+			if got.BMI != tt.want || got.Class != tt.class {
+				t.Errorf("BMI() = %v %q, want %v %q", got.BMI, got.Class, tt.want, tt.class)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package calculator
+
+import "math"
+
+// ACE body fat categories.
+const (
+	BodyFatEssential = "essential"
+	BodyFatAthletes  = "athletes"
+	BodyFatFitness   = "fitness"
+	BodyFatAverage   = "average"
+	BodyFatObese     = "obese"
+)
+
+// BodyFatInput holds the tape measurements of the US Navy method. Hip is
+// required for women only.
+type BodyFatInput struct {
+	Units  string  `json:"units"`
+	Sex    string  `json:"sex"`
+	Height float64 `json:"height"`
+	Neck   float64 `json:"neck"`
+	Waist  float64 `json:"waist"`
+	Hip    float64 `json:"hip"`
+}
+
+type BodyFatResult struct {
+	Percent  float64 `json:"percent"`
+	Category string  `json:"category"`
+	Label    string  `json:"label"`
+}
+
+// BodyFat estimates the body fat percentage with the US Navy circumference
+// method.
+func BodyFat(input BodyFatInput, language string) (*BodyFatResult, error) {
+	units, err := normalizeUnits(input.Units)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSex(input.Sex); err != nil {
+		return nil, err
+	}
+
+	height, err := lengthCm("height", input.Height, units, 100, 272)
+	if err != nil {
+		return nil, err
+	}
+	neck, err := lengthCm("neck", input.Neck, units, 20, 80)
+	if err != nil {
+		return nil, err
+	}
+	waist, err := lengthCm("waist", input.Waist, units, 40, 250)
+	if err != nil {
+		return nil, err
+	}
+
+	var percent float64
+	if input.Sex == SexMale {
+		if waist <= neck {
+			return nil, invalid("waist must be larger than neck")
+		}
+		percent = 495/(1.0324-0.19077*math.Log10(waist-neck)+0.15456*math.Log10(height)) - 450
+	} else {
+		hip, err := lengthCm("hip", input.Hip, units, 50, 250)
+		if err != nil {
+			return nil, err
+		}
+		if waist+hip <= neck {
+			return nil, invalid("waist and hip must be larger than neck")
+		}
+		percent = 495/(1.29579-0.35004*math.Log10(waist+hip-neck)+0.22100*math.Log10(height)) - 450
+	}
+	if percent < 2 || percent > 75 {
+		return nil, invalid("measurements give an implausible body fat of %.1f%%", percent)
+	}
+
+	category := bodyFatCategory(input.Sex, percent)
+	return &BodyFatResult{
+		Percent:  round(percent, 1),
+		Category: category,
+		Label:    Label(category, language),
+	}, nil
+}
+
+func bodyFatCategory(sex string, percent float64) string {
+	// Upper bounds of the essential, athletes, fitness and average ranges.
+	bounds := [4]float64{6, 14, 18, 25}
+	if sex == SexFemale {
+		bounds = [4]float64{14, 21, 25, 32}
+	}
+
+	switch {
+	case percent < bounds[0]:
+		return BodyFatEssential
+	case percent < bounds[1]:
+		return BodyFatAthletes
+	case percent < bounds[2]:
+		return BodyFatFitness
+	case percent < bounds[3]:
+		return BodyFatAverage
+	}
+
+	return BodyFatObese
+}
@@ -0,0 +1,51 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBodyFat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    BodyFatInput
+		want     float64
+		category string
+	}{
+		{"male", BodyFatInput{Sex: SexMale, Height: 178, Neck: 38, Waist: 86}, 17.2, BodyFatFitness},
+		{"female", BodyFatInput{Sex: SexFemale, Height: 165, Neck: 32, Waist: 72, Hip: 98}, 27.4, BodyFatAverage},
+		{"male imperial", BodyFatInput{Units: UnitsImperial, Sex: SexMale, Height: 70, Neck: 15, Waist: 34}, 17.4, BodyFatFitness},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BodyFat(tt.input, "en")
+			if err != nil {
+				t.Fatalf("BodyFat() error = %v", err)
+			}
+			if got.Percent != tt.want || got.Category != tt.category {
+				t.Errorf("BodyFat() = %v %q, want %v %q", got.Percent, got.Category, tt.want, tt.category)
+			}
+		})
+	}
+}
+
+func TestBodyFatInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input BodyFatInput
+	}{
+		{"waist equals neck", BodyFatInput{Sex: SexMale, Height: 178, Neck: 45, Waist: 45}},
+		{"waist below neck", BodyFatInput{Sex: SexMale, Height: 178, Neck: 50, Waist: 45}},
+		{"female without hip", BodyFatInput{Sex: SexFemale, Height: 165, Neck: 32, Waist: 72}},
+		{"unknown sex", BodyFatInput{Sex: "other", Height: 178, Neck: 38, Waist: 86}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := BodyFat(tt.input, "en"); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("BodyFat() error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Package calculator holds the body-composition and training calculators.
+// Every calculator validates its input, accepts metric or imperial units and
+// reports results in the units it was given.
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"math"
+)
+
+const (
+	UnitsMetric   = "metric"
+	UnitsImperial = "imperial"
+)
+
+const (
+	SexMale   = "male"
+	SexFemale = "female"
+)
+
+const (
+	cmPerInch = 2.54
+	kgPerLb   = 0.45359237
+)
+
+// ErrInvalidInput wraps every validation error of the calculators.
+var ErrInvalidInput = errors.New("invalid input")
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidInput, fmt.Sprintf(format, args...))
+}
+
+// normalizeUnits defaults empty units to metric.
+func normalizeUnits(units string) (string, error) {
+	switch units {
+	case "", UnitsMetric:
+		return UnitsMetric, nil
+	case UnitsImperial:
+		return UnitsImperial, nil
+	}
+
+	return "", invalid("units must be %s or %s", UnitsMetric, UnitsImperial)
+}
+
+func checkSex(sex string) error {
+	if sex != SexMale && sex != SexFemale {
+		return invalid("sex must be %s or %s", SexMale, SexFemale)
+	}
+
+	return nil
+}
+
+// lengthCm converts a length in the given units to centimetres and checks it
+// lies within [minCm, maxCm].
+func lengthCm(field string, value float64, units string, minCm, maxCm float64) (float64, error) {
+	cm := value
+	if units == UnitsImperial {
+		cm = value * cmPerInch
+	}
+	if math.IsNaN(cm) || cm < minCm || cm > maxCm {
+		if units == UnitsImperial {
+			return 0, invalid("%s must be between %.1f and %.1f in", field, minCm/cmPerInch, maxCm/cmPerInch)
+		}
+		return 0, invalid("%s must be between %.0f and %.0f cm", field, minCm, maxCm)
+	}
+
+	return cm, nil
+}
+
+// massKg converts a weight in the given units to kilograms and checks it lies
+// within [minKg, maxKg].
+func massKg(field string, value float64, units string, minKg, maxKg float64) (float64, error) {
+	kg := value
+	if units == UnitsImperial {
+		kg = value * kgPerLb
+	}
+	if math.IsNaN(kg) || kg < minKg || kg > maxKg {
+		if units == UnitsImperial {
+			return 0, invalid("%s must be between %.0f and %.0f lb", field, minKg/kgPerLb, maxKg/kgPerLb)
+		}
+		return 0, invalid("%s must be between %.0f and %.0f kg", field, minKg, maxKg)
+	}
+
+	return kg, nil
+}
+
+// fromKg converts kilograms back to the caller's units.
+func fromKg(kg float64, units string) float64 {
+	if units == UnitsImperial {
+		return kg / kgPerLb
+	}
+
+	return kg
+}
+
+func round(value float64, digits int) float64 {
+	factor := math.Pow(10, float64(digits))
+	return math.Round(value*factor) / factor
+}
+
+// Label is the name of a result class in the given language, Russian by
+// default.
+func Label(code, language string) string {
+	if names, ok := labels[language]; ok {
+		return names[code]
+	}
+
+	return labels[models.LanguageRU][code]
+}
+
+var labels = map[string]map[string]string{
+	models.LanguageRU: {
+		BMISevereThinness:   "Выраженный дефицит массы тела",
+		BMIModerateThinness: "Умеренный дефицит массы тела",
+		BMIMildThinness:     "Лёгкий дефицит массы тела",
+		BMINormal:           "Нормальный вес",
+		BMIPreObese:         "Избыточный вес",
+		BMIObeseI:           "Ожирение I степени",
+		BMIObeseII:          "Ожирение II степени",
+		BMIObeseIII:         "Ожирение III степени",
+
+		BodyFatEssential: "Минимально необходимый жир",
+		BodyFatAthletes:  "Уровень спортсменов",
+		BodyFatFitness:   "Хорошая форма",
+		BodyFatAverage:   "Средний уровень",
+		BodyFatObese:     "Ожирение",
+
+		WHtRLow:       "Ниже нормы",
+		WHtRHealthy:   "Норма",
+		WHtRIncreased: "Повышенный риск",
+		WHtRHigh:      "Высокий риск",
+	},
+	models.LanguageEN: {
+		BMISevereThinness:   "Severe thinness",
+		BMIModerateThinness: "Moderate thinness",
+		BMIMildThinness:     "Mild thinness",
+		BMINormal:           "Normal weight",
+		BMIPreObese:         "Pre-obesity",
+		BMIObeseI:           "Obesity class I",
+		BMIObeseII:          "Obesity class II",
+		BMIObeseIII:         "Obesity class III",
+
+		BodyFatEssential: "Essential fat",
+		BodyFatAthletes:  "Athletes",
+		BodyFatFitness:   "Fitness",
+		BodyFatAverage:   "Average",
+		BodyFatObese:     "Obese",
+
+		WHtRLow:       "Below healthy range",
+		WHtRHealthy:   "Healthy",
+		WHtRIncreased: "Increased risk",
+		WHtRHigh:      "High risk",
+	},
+}
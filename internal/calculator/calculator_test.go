@@ -0,0 +1,100 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestUnitsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float64
+	}{
+		{"light", 2},
+		{"average", 72.5},
+		{"heavy", 600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kg, err := massKg("weight", tt.value/kgPerLb, UnitsImperial, 1, 650)
+			if err != nil {
+				t.Fatalf("massKg() error = %v", err)
+			}
+			if math.Abs(kg-tt.value) > 1e-9 {
+				t.Errorf("massKg() = %v, want %v", kg, tt.value)
+			}
+			if lb := fromKg(kg, UnitsImperial); math.Abs(lb-tt.value/kgPerLb) > 1e-9 {
+				t.Errorf("fromKg() = %v, want %v", lb, tt.value/kgPerLb)
+			}
+			if got := fromKg(kg, UnitsMetric); got != kg {
+				t.Errorf("fromKg(metric) = %v, want %v", got, kg)
+			}
+
+			cm, err := lengthCm("length", tt.value/cmPerInch, UnitsImperial, 1, 650)
+			if err != nil {
+				t.Fatalf("lengthCm() error = %v", err)
+			}
+			if math.Abs(cm-tt.value) > 1e-9 {
+				t.Errorf("lengthCm() = %v, want %v", cm, tt.value)
+			}
+		})
+	}
+}
+
+func TestIdealWeightUnitsAgree(t *testing.T) {
+	metric, err := IdealWeight(IdealWeightInput{Sex: SexMale, Height: 180})
+	if err != nil {
+		t.Fatalf("IdealWeight(metric) error = %v", err)
+	}
+	imperial, err := IdealWeight(IdealWeightInput{Units: UnitsImperial, Sex: SexMale, Height: 180 / cmPerInch})
+	if err != nil {
+		t.Fatalf("IdealWeight(imperial) error = %v", err)
+	}
+
+	if lb := metric.Devine / kgPerLb; math.Abs(lb-imperial.Devine) > 0.1 {
+		t.Errorf("Devine = %v kg and %v lb, want %v lb", metric.Devine, imperial.Devine, lb)
+	}
+}
+
+func TestInvalidInput(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"units", func() error {
+			_, err := BMI(BMIInput{Units: "stone", Height: 180, Weight: 80}, "en")
+			return err
+		}},
+		{"height", func() error {
+			_, err := BMI(BMIInput{Height: 20, Weight: 80}, "en")
+			return err
+		}},
+		{"imperial weight", func() error {
+			_, err := BMI(BMIInput{Units: UnitsImperial, Height: 70, Weight: 2000}, "en")
+			return err
+		}},
+		{"NaN", func() error {
+			_, err := WaistHeight(WaistHeightInput{Height: math.NaN(), Waist: 80}, "en")
+			return err
+		}},
+		{"sex", func() error {
+			_, err := IdealWeight(IdealWeightInput{Sex: "other", Height: 170})
+			return err
+		}},
+		{"weight", func() error {
+			_, err := OneRepMax(OneRepMaxInput{Weight: 0, Reps: 5})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("error = %v, want it to wrap ErrInvalidInput", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package calculator
+
+import "math"
+
+const (
+	MaxHeartRateFox    = "fox"
+	MaxHeartRateTanaka = "tanaka"
+)
+
+type HeartRateInput struct {
+	Age int `json:"age"`
+	// RestingHeartRate switches the zones to the Karvonen heart-rate reserve
+	// method when set.
+	RestingHeartRate int    `json:"resting_heart_rate"`
+	Formula          string `json:"formula"`
+}
+
+type HeartRateZone struct {
+	Zone    int    `json:"zone"`
+	Name    string `json:"name"`
+	Percent [2]int `json:"percent"`
+	From    int    `json:"from"`
+	To      int    `json:"to"`
+}
+
+type HeartRateResult struct {
+	MaxHeartRate int             `json:"max_heart_rate"`
+	Formula      string          `json:"formula"`
+	Method       string          `json:"method"`
+	Zones        []HeartRateZone `json:"zones"`
+}
+
+var heartRateZones = []HeartRateZone{
+	{Zone: 1, Name: "recovery", Percent: [2]int{50, 60}},
+	{Zone: 2, Name: "endurance", Percent: [2]int{60, 70}},
+	{Zone: 3, Name: "aerobic", Percent: [2]int{70, 80}},
+	{Zone: 4, Name: "threshold", Percent: [2]int{80, 90}},
+	{Zone: 5, Name: "maximum", Percent: [2]int{90, 100}},
+}
+
+// HeartRateZones splits the range up to the maximum heart rate into the five
+// training zones, as a share of the maximum or, with a resting rate, of the
+// heart-rate reserve.
+func HeartRateZones(input HeartRateInput) (*HeartRateResult, error) {
+	if input.Age < 10 || input.Age > 100 {
+		return nil, invalid("age must be between 10 and 100")
+	}
+
+	result := &HeartRateResult{Formula: input.Formula, Method: "max"}
+	switch input.Formula {
+	case "", MaxHeartRateTanaka:
+		result.Formula = MaxHeartRateTanaka
+		result.MaxHeartRate = int(math.Round(208 - 0.7*float64(input.Age)))
+	case MaxHeartRateFox:
+		result.MaxHeartRate = 220 - input.Age
+	default:
+		return nil, invalid("formula must be %s or %s", MaxHeartRateTanaka, MaxHeartRateFox)
+	}
+
+	resting := 0
+	if input.RestingHeartRate != 0 {
+		if input.RestingHeartRate < 30 || input.RestingHeartRate > 120 {
+			return nil, invalid("resting_heart_rate must be between 30 and 120")
+		}
+		if input.RestingHeartRate >= result.MaxHeartRate {
+			return nil, invalid("resting_heart_rate must be below the maximum heart rate")
+		}
+		resting = input.RestingHeartRate
+		result.Method = "karvonen"
+	}
+
+	reserve := float64(result.MaxHeartRate - resting)
+	for _, zone := range heartRateZones {
+		zone.From = resting + int(math.Round(reserve*float64(zone.Percent[0])/100))
+		zone.To = resting + int(math.Round(reserve*float64(zone.Percent[1])/100))
+		result.Zones = append(result.Zones, zone)
+	}
+
+	return result, nil
+}
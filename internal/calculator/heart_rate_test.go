@@ -0,0 +1,64 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHeartRateZones(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    HeartRateInput
+		max      int
+		formula  string
+		method   string
+		zoneFrom int
+		zoneTo   int
+	}{
+		{"tanaka by default", HeartRateInput{Age: 30}, 187, MaxHeartRateTanaka, "max", 94, 112},
+		{"fox", HeartRateInput{Age: 30, Formula: MaxHeartRateFox}, 190, MaxHeartRateFox, "max", 95, 114},
+		{"karvonen", HeartRateInput{Age: 30, Formula: MaxHeartRateFox, RestingHeartRate: 60}, 190, MaxHeartRateFox, "karvonen", 125, 138},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HeartRateZones(tt.input)
+			if err != nil {
+				t.Fatalf("HeartRateZones() error = %v", err)
+			}
+			if got.MaxHeartRate != tt.max || got.Formula != tt.formula || got.Method != tt.method {
+				t.Errorf("HeartRateZones() = %d %q %q, want %d %q %q",
+					got.MaxHeartRate, got.Formula, got.Method, tt.max, tt.formula, tt.method)
+			}
+			if len(got.Zones) != 5 {
+				t.Fatalf("HeartRateZones() returned %d zones, want 5", len(got.Zones))
+			}
+			if zone := got.Zones[0]; zone.From != tt.zoneFrom || zone.To != tt.zoneTo {
+				t.Errorf("zone 1 = %d-%d, want %d-%d", zone.From, zone.To, tt.zoneFrom, tt.zoneTo)
+			}
+			if top := got.Zones[4].To; top != tt.max {
+				t.Errorf("zone 5 ends at %d, want %d", top, tt.max)
+			}
+		})
+	}
+}
+
+func TestHeartRateZonesInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input HeartRateInput
+	}{
+		{"resting equals max", HeartRateInput{Age: 100, Formula: MaxHeartRateFox, RestingHeartRate: 120}},
+		{"resting out of range", HeartRateInput{Age: 30, RestingHeartRate: 25}},
+		{"unknown formula", HeartRateInput{Age: 30, Formula: "gellish"}},
+		{"too young", HeartRateInput{Age: 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := HeartRateZones(tt.input); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("HeartRateZones() error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
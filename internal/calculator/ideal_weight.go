@@ -0,0 +1,71 @@
+package calculator
+
+type IdealWeightInput struct {
+	Units  string  `json:"units"`
+	Sex    string  `json:"sex"`
+	Height float64 `json:"height"`
+}
+
+// IdealWeightResult holds the estimates of the classic formulas and the
+// weight range of a normal BMI, all in the input units.
+type IdealWeightResult struct {
+	Devine      float64 `json:"devine"`
+	Robinson    float64 `json:"robinson"`
+	Miller      float64 `json:"miller"`
+	Hamwi       float64 `json:"hamwi"`
+	HealthyFrom float64 `json:"healthy_from"`
+	HealthyTo   float64 `json:"healthy_to"`
+}
+
+// idealWeightFormula is a base weight at five feet plus a gain for every inch
+// above it, in kilograms, for men and for women.
+type idealWeightFormula struct {
+	maleBase, malePerInch     float64
+	femaleBase, femalePerInch float64
+}
+
+var (
+	devine   = idealWeightFormula{50, 2.3, 45.5, 2.3}
+	robinson = idealWeightFormula{52, 1.9, 49, 1.7}
+	miller   = idealWeightFormula{56.2, 1.41, 53.1, 1.36}
+	hamwi    = idealWeightFormula{48, 2.7, 45.5, 2.2}
+)
+
+func (f idealWeightFormula) kg(sex string, heightCm float64) float64 {
+	inches := heightCm/cmPerInch - 60
+	if sex == SexMale {
+		return f.maleBase + f.malePerInch*inches
+	}
+
+	return f.femaleBase + f.femalePerInch*inches
+}
+
+// IdealWeight applies the Devine, Robinson, Miller and Hamwi formulas. They
+// are meant for adults of at least five feet, so shorter heights are refused.
+func IdealWeight(input IdealWeightInput) (*IdealWeightResult, error) {
+	units, err := normalizeUnits(input.Units)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSex(input.Sex); err != nil {
+		return nil, err
+	}
+	height, err := lengthCm("height", input.Height, units, 60*cmPerInch, 272)
+	if err != nil {
+		return nil, err
+	}
+
+	meters := height / 100
+	weight := func(kg float64) float64 {
+		return round(fromKg(kg, units), 1)
+	}
+
+	return &IdealWeightResult{
+		Devine:      weight(devine.kg(input.Sex, height)),
+		Robinson:    weight(robinson.kg(input.Sex, height)),
+		Miller:      weight(miller.kg(input.Sex, height)),
+		Hamwi:       weight(hamwi.kg(input.Sex, height)),
+		HealthyFrom: weight(18.5 * meters * meters),
+		HealthyTo:   weight(24.9 * meters * meters),
+	}, nil
+}
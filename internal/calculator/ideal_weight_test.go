@@ -0,0 +1,51 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIdealWeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  IdealWeightInput
+		devine float64
+	}{
+		{"male at five feet", IdealWeightInput{Sex: SexMale, Height: 60 * cmPerInch}, 50},
+		{"imperial minimum", IdealWeightInput{Units: UnitsImperial, Sex: SexMale, Height: 60}, 110.2},
+		{"female imperial", IdealWeightInput{Units: UnitsImperial, Sex: SexFemale, Height: 64}, 120.6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IdealWeight(tt.input)
+			if err != nil {
+				t.Fatalf("IdealWeight() error = %v", err)
+			}
+			if got.Devine != tt.devine {
+				t.Errorf("IdealWeight().Devine = %v, want %v", got.Devine, tt.devine)
+			}
+			if got.HealthyFrom >= got.HealthyTo {
+				t.Errorf("IdealWeight() healthy range %v-%v is empty", got.HealthyFrom, got.HealthyTo)
+			}
+		})
+	}
+}
+
+func TestIdealWeightBelowFiveFeet(t *testing.T) {
+	tests := []struct {
+		name  string
+		input IdealWeightInput
+	}{
+		{"metric", IdealWeightInput{Sex: SexFemale, Height: 152}},
+		{"imperial", IdealWeightInput{Units: UnitsImperial, Sex: SexFemale, Height: 59.9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := IdealWeight(tt.input); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("IdealWeight() error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
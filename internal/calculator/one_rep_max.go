@@ -0,0 +1,75 @@
+package calculator
+
+import "math"
+
+// MaxRepsForOneRepMax is the most repetitions the estimators are trusted
+// with; beyond it they drift apart quickly.
+const MaxRepsForOneRepMax = 12
+
+type OneRepMaxInput struct {
+	Units  string  `json:"units"`
+	Weight float64 `json:"weight"`
+	Reps   int     `json:"reps"`
+}
+
+// OneRepMaxResult holds the estimates of the common formulas and their mean,
+// in the input units.
+type OneRepMaxResult struct {
+	Epley    float64 `json:"epley"`
+	Brzycki  float64 `json:"brzycki"`
+	Lander   float64 `json:"lander"`
+	Lombardi float64 `json:"lombardi"`
+	Mayhew   float64 `json:"mayhew"`
+	OConner  float64 `json:"oconner"`
+	Wathan   float64 `json:"wathan"`
+	Average  float64 `json:"average"`
+}
+
+// OneRepMax estimates the heaviest single repetition from a set of reps with
+// the given weight.
+func OneRepMax(input OneRepMaxInput) (*OneRepMaxResult, error) {
+	units, err := normalizeUnits(input.Units)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := massKg("weight", input.Weight, units, 1, 600); err != nil {
+		return nil, err
+	}
+	if input.Reps < 1 || input.Reps > MaxRepsForOneRepMax {
+		return nil, invalid("reps must be between 1 and %d", MaxRepsForOneRepMax)
+	}
+
+	w, r := input.Weight, float64(input.Reps)
+	estimates := []float64{
+		w * (1 + r/30),
+		w * 36 / (37 - r),
+		100 * w / (101.3 - 2.67123*r),
+		w * math.Pow(r, 0.10),
+		100 * w / (52.2 + 41.9*math.Exp(-0.055*r)),
+		w * (1 + 0.025*r),
+		100 * w / (48.8 + 53.8*math.Exp(-0.075*r)),
+	}
+	if input.Reps == 1 {
+		// A single is the one-rep max; the formulas only approximate it.
+		for i := range estimates {
+			estimates[i] = w
+		}
+	}
+
+	var sum float64
+	for i, estimate := range estimates {
+		estimates[i] = round(estimate, 1)
+		sum += estimate
+	}
+
+	return &OneRepMaxResult{
+		Epley:    estimates[0],
+		Brzycki:  estimates[1],
+		Lander:   estimates[2],
+		Lombardi: estimates[3],
+		Mayhew:   estimates[4],
+		OConner:  estimates[5],
+		Wathan:   estimates[6],
+		Average:  round(sum/float64(len(estimates)), 1),
+	}, nil
+}
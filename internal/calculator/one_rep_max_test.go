@@ -0,0 +1,40 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOneRepMax(t *testing.T) {
+	got, err := OneRepMax(OneRepMaxInput{Weight: 100, Reps: 5})
+	if err != nil {
+		t.Fatalf("OneRepMax() error = %v", err)
+	}
+	if got.Epley != 116.7 || got.Brzycki != 112.5 {
+		t.Errorf("OneRepMax() = Epley %v Brzycki %v, want 116.7 112.5", got.Epley, got.Brzycki)
+	}
+}
+
+func TestOneRepMaxSingle(t *testing.T) {
+	got, err := OneRepMax(OneRepMaxInput{Units: UnitsImperial, Weight: 225, Reps: 1})
+	if err != nil {
+		t.Fatalf("OneRepMax() error = %v", err)
+	}
+
+	for name, estimate := range map[string]float64{
+		"epley": got.Epley, "brzycki": got.Brzycki, "lander": got.Lander, "lombardi": got.Lombardi,
+		"mayhew": got.Mayhew, "oconner": got.OConner, "wathan": got.Wathan, "average": got.Average,
+	} {
+		if estimate != 225 {
+			t.Errorf("OneRepMax() %s = %v, want 225", name, estimate)
+		}
+	}
+}
+
+func TestOneRepMaxInvalidReps(t *testing.T) {
+	for _, reps := range []int{0, MaxRepsForOneRepMax + 1, 20} {
+		if _, err := OneRepMax(OneRepMaxInput{Weight: 100, Reps: reps}); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("OneRepMax(reps %d) error = %v, want ErrInvalidInput", reps, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package calculator
+
+// Waist-to-height ratio classes.
+const (
+	WHtRLow       = "whtr_low"
+	WHtRHealthy   = "whtr_healthy"
+	WHtRIncreased = "whtr_increased"
+	WHtRHigh      = "whtr_high"
+)
+
+type WaistHeightInput struct {
+	Units  string  `json:"units"`
+	Height float64 `json:"height"`
+	Waist  float64 `json:"waist"`
+}
+
+type WaistHeightResult struct {
+	Ratio float64 `json:"ratio"`
+	Class string  `json:"class"`
+	Label string  `json:"label"`
+}
+
+// WaistHeight is the waist-to-height ratio; keeping the waist under half the
+// height is the usual health target.
+func WaistHeight(input WaistHeightInput, language string) (*WaistHeightResult, error) {
+	units, err := normalizeUnits(input.Units)
+	if err != nil {
+		return nil, err
+	}
+	height, err := lengthCm("height", input.Height, units, 50, 272)
+	if err != nil {
+		return nil, err
+	}
+	waist, err := lengthCm("waist", input.Waist, units, 30, 250)
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := waist / height
+	class := WHtRHigh
+	switch {
+	case ratio < 0.4:
+		class = WHtRLow
+	case ratio < 0.5:
+		class = WHtRHealthy
+	case ratio < 0.6:
+		class = WHtRIncreased
+	}
+
+	return &WaistHeightResult{
+		Ratio: round(ratio, 2),
+		Class: class,
+		Label: Label(class, language),
+	}, nil
+}
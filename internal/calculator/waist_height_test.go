@@ -0,0 +1,30 @@
+package calculator
+
+import "testing"
+
+func TestWaistHeight(t *testing.T) {
+	tests := []struct {
+		name  string
+		input WaistHeightInput
+		ratio float64
+		class string
+	}{
+		{"low", WaistHeightInput{Height: 180, Waist: 70}, 0.39, WHtRLow},
+		{"healthy", WaistHeightInput{Height: 180, Waist: 85}, 0.47, WHtRHealthy},
+		{"increased", WaistHeightInput{Height: 170, Waist: 90}, 0.53, WHtRIncreased},
+		{"high", WaistHeightInput{Height: 170, Waist: 102}, 0.6, WHtRHigh},
+		{"imperial", WaistHeightInput{Units: UnitsImperial, Height: 70, Waist: 33}, 0.47, WHtRHealthy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WaistHeight(tt.input, "en")
+			if err != nil {
+				t.Fatalf("WaistHeight() error = %v", err)
+			}
+			if got.Ratio != tt.ratio || got.Class != tt.class {
+				t.Errorf("WaistHeight() = %v %q, want %v %q", got.Ratio, got.Class, tt.ratio, tt.class)
+			}
+		})
+	}
+}
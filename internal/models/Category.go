@@ -4,22 +4,33 @@ import "gorm.io/gorm"
 
 type Categories struct {
 	gorm.Model
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Price       int    `json:"price"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Price       int64    `json:"price" gorm:"column:price_minor"`
+	Status      string   `json:"status" gorm:"default:published"`
+	Version     int      `json:"version"`
+	ExternalKey string   `json:"external_key,omitempty" gorm:"index"`
+	OwnerID     *uint    `json:"owner_id,omitempty" gorm:"index"`
+	Goals       []string `json:"goals" gorm:"serializer:json"`
 
-	ExercisePlans []ExercisePlan `json:"exercise_plans" gorm:"foreignKey:CategoryID"`
-	MealPlans     []MealPlan     `json:"meal_plans" gorm:"foreignKey:CategoryID"`
+	ExercisePlans []ExercisePlan `json:"exercise_plans" gorm:"foreignKey:CategoriesID"`
+	MealPlans     []MealPlan     `json:"meal_plans" gorm:"foreignKey:CategoriesID"`
+
+	Rating *CategoryRating `json:"rating,omitempty" gorm:"-"`
 }
 
 type CreateCategoryRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Price       int    `json:"price"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Price       int64    `json:"price"`
+	OwnerID     *uint    `json:"owner_id"`
+	Goals       []string `json:"goals"`
 }
 
 type UpdateCategoryRequest struct {
-	Name        *string `json:"name"`
-	Description *string `json:"description"`
-	Price       *int    `json:"price"`
+	Name        *string  `json:"name"`
+	Description *string  `json:"description"`
+	Price       *int64   `json:"price"`
+	OwnerID     *uint    `json:"owner_id"`
+	Goals       []string `json:"goals"`
 }
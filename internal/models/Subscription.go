@@ -6,24 +6,28 @@ type Subscription struct {
 	gorm.Model
 	Name         string      `json:"name"`
 	Description  string      `json:"description"`
-	Price        int         `json:"price"`
+	Price        int64       `json:"price" gorm:"column:price_minor"`
 	DurationDays int         `json:"duration_days"`
+	TrialDays    int         `json:"trial_days"`
 	CategoriesID uint        `json:"categories_id"`
+	ExternalKey  string      `json:"external_key,omitempty" gorm:"index"`
 	Categories   *Categories `json:"-"`
 }
 
 type CreateSubscriptionRequest struct {
 	Name         string `json:"name"`
 	Description  string `json:"description"`
-	Price        int    `json:"price"`
+	Price        int64  `json:"price"`
 	DurationDays int    `json:"duration_days"`
+	TrialDays    int    `json:"trial_days"`
 	CategoriesID uint   `json:"categories_id"`
 }
 
 type UpdateSubscriptionRequest struct {
 	Name         *string `json:"name"`
 	Description  *string `json:"description"`
-	Price        *int    `json:"price"`
+	Price        *int64  `json:"price"`
 	DurationDays *int    `json:"duration_days"`
+	TrialDays    *int    `json:"trial_days"`
 	CategoriesID *uint   `json:"categories_id"`
 }
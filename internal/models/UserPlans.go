@@ -6,6 +6,11 @@ type UserPlan struct {
     gorm.Model
     UserID     uint
     CategoriesID uint
+    CategoryVersion int
+    Currency string `gorm:"default:RUB"`
+    PricePaid int64
+    Discount int64
+    PromoCodeID *uint
 
     User     *User     		`gorm:"foreignKey:UserID"`
     Categories *Categories 	`gorm:"foreignKey:CategoriesID"` // обязательно указать foreignKey
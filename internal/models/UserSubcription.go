@@ -6,14 +6,62 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	SubStatusActive  = "active"
+	SubStatusPaused  = "paused"
+	SubStatusExpired = "expired"
+	SubStatusChanged = "changed"
+)
+
+const (
+	EntitlementPlan         = "plan"
+	EntitlementSubscription = "subscription"
+)
+
 type UserSubscription struct {
 	gorm.Model
-	UserID         uint      `json:"user_id"`
-	SubscriptionID uint      `json:"subscription_id"`
-	StartDate      time.Time `json:"start_date"`
-	EndDate        time.Time `json:"end_date"`
-	IsActive       bool      `json:"is_active"`
+	UserID            uint       `json:"user_id" gorm:"index"`
+	SubscriptionID    uint       `json:"subscription_id"`
+	CategoriesID      uint       `json:"categories_id" gorm:"index"`
+	StartDate         time.Time  `json:"start_date"`
+	EndDate           time.Time  `json:"end_date"`
+	IsActive          bool       `json:"is_active"`
+	Status            string     `json:"status" gorm:"default:active;index"`
+	CancelAtPeriodEnd bool       `json:"cancel_at_period_end"`
+	Trial             bool       `json:"trial"`
+	TrialEndsAt       *time.Time `json:"trial_ends_at"`
+	PausedAt          *time.Time `json:"paused_at"`
+	Currency          string     `json:"currency" gorm:"default:RUB"`
+	PricePaid         int64      `json:"price_paid"`
+	Discount          int64      `json:"discount"`
+	PromoCodeID       *uint      `json:"promo_code_id"`
+
+	ExpiryNotifiedAt *time.Time `json:"-"`
 
 	User         *User         `json:"-" gorm:"foreignKey:UserID"`
 	Subscription *Subscription `json:"-" gorm:"foreignKey:SubscriptionID"`
 }
+
+type ChangeSubscriptionRequest struct {
+	SubscriptionID uint `json:"subscription_id" binding:"required"`
+}
+
+// SubscriptionChange is the outcome of an upgrade or downgrade. Charged is taken
+// from the wallet, Refunded is returned to it.
+type SubscriptionChange struct {
+	Previous     UserSubscription `json:"previous"`
+	Subscription UserSubscription `json:"subscription"`
+	Currency     string           `json:"currency"`
+	Credit       int64            `json:"credit"`
+	Cost         int64            `json:"cost"`
+	Charged      int64            `json:"charged"`
+	Refunded     int64            `json:"refunded"`
+}
+
+type Entitlement struct {
+	CategoriesID       uint       `json:"categories_id"`
+	Source             string     `json:"source"`
+	UserPlanID         *uint      `json:"user_plan_id,omitempty"`
+	UserSubscriptionID *uint      `json:"user_subscription_id,omitempty"`
+	Until              *time.Time `json:"until,omitempty"`
+}
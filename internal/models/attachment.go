@@ -0,0 +1,27 @@
+package models
+
+import "gorm.io/gorm"
+
+// Attachment is an uploaded photo. The image and its thumbnail live in blob
+// storage under Key and ThumbnailKey.
+type Attachment struct {
+	gorm.Model
+
+	ReviewID     *uint  `json:"review_id,omitempty" gorm:"index"`
+	UserID       uint   `json:"user_id" gorm:"index"`
+	Key          string `json:"-"`
+	ThumbnailKey string `json:"-"`
+	ContentType  string `json:"content_type"`
+	Size         int64  `json:"size"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+type GetAttachment struct {
+	ID           uint   `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	ContentType  string `json:"content_type"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
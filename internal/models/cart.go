@@ -0,0 +1,42 @@
+package models
+
+import "gorm.io/gorm"
+
+type CartItem struct {
+	gorm.Model
+	UserID     uint   `json:"user_id" gorm:"uniqueIndex:idx_cart_item"`
+	TargetType string `json:"target_type" gorm:"uniqueIndex:idx_cart_item"`
+	TargetID   uint   `json:"target_id" gorm:"uniqueIndex:idx_cart_item"`
+}
+
+type AddCartItemRequest struct {
+	TargetType string `json:"target_type" binding:"required"`
+	TargetID   uint   `json:"target_id" binding:"required"`
+}
+
+type CartLine struct {
+	CartItemID uint   `json:"cart_item_id"`
+	TargetType string `json:"target_type"`
+	TargetID   uint   `json:"target_id"`
+	Name       string `json:"name"`
+	ListPrice  int64  `json:"list_price"`
+	Discount   int64  `json:"discount"`
+	FinalPrice int64  `json:"final_price"`
+}
+
+type CartQuote struct {
+	UserID    uint       `json:"user_id"`
+	Lines     []CartLine `json:"lines"`
+	Currency  string     `json:"currency"`
+	PromoCode string     `json:"promo_code,omitempty"`
+	ListTotal int64      `json:"list_total"`
+	Discount  int64      `json:"discount"`
+	Total     int64      `json:"total"`
+}
+
+type CheckoutResult struct {
+	Order             *Order             `json:"order"`
+	Quote             *CartQuote         `json:"quote"`
+	UserPlans         []UserPlan         `json:"user_plans"`
+	UserSubscriptions []UserSubscription `json:"user_subscriptions"`
+}
@@ -0,0 +1,125 @@
+package models
+
+import "time"
+
+const CatalogFormatVersion = 1
+
+const (
+	TableCategories        = "categories"
+	TableExercisePlans     = "exercise_plans"
+	TableExercisePlanItems = "exercise_plan_items"
+	TableMealPlans         = "meal_plans"
+	TableMealPlanItems     = "meal_plan_items"
+	TableSubscriptions     = "subscriptions"
+	TableProgressionRules  = "progression_rules"
+)
+
+type CatalogDocument struct {
+	FormatVersion int                     `json:"format_version"`
+	ExportedAt    time.Time               `json:"exported_at"`
+	Categories    []CatalogCategoryRecord `json:"categories"`
+}
+
+type CatalogCategoryRecord struct {
+	ExternalKey   string                      `json:"external_key"`
+	Name          string                      `json:"name"`
+	Description   string                      `json:"description"`
+	Price         int64                       `json:"price"`
+	Status        string                      `json:"status"`
+	Goals         []string                    `json:"goals,omitempty"`
+	ExercisePlans []CatalogExercisePlanRecord `json:"exercise_plans"`
+	MealPlans     []CatalogMealPlanRecord     `json:"meal_plans"`
+	Subscriptions []CatalogSubscriptionRecord `json:"subscriptions"`
+}
+
+type CatalogExercisePlanRecord struct {
+	ExternalKey      string                         `json:"external_key"`
+	Name             string                         `json:"name"`
+	Description      string                         `json:"description"`
+	DurationWeeks    int                            `json:"duration_weeks"`
+	Status           string                         `json:"status"`
+	Exercises        []CatalogExerciseRecord        `json:"exercises"`
+	ProgressionRules []CatalogProgressionRuleRecord `json:"progression_rules"`
+}
+
+type CatalogExerciseRecord struct {
+	ExternalKey     string  `json:"external_key"`
+	Name            string  `json:"name"`
+	Sets            int     `json:"sets"`
+	Reps            int     `json:"reps"`
+	DurationMinutes string  `json:"duration_minutes"`
+	EquipmentNeeded string  `json:"equipment_needed"`
+	DayOfWeek       string  `json:"day_of_week"`
+	MuscleGroup     string  `json:"muscle_group"`
+	WeightKg        float64 `json:"weight_kg"`
+}
+
+// CatalogProgressionRuleRecord points at its exercise by external key; an empty
+// key means the rule covers the whole plan.
+type CatalogProgressionRuleRecord struct {
+	ExerciseKey string  `json:"exercise_key,omitempty"`
+	Type        string  `json:"type"`
+	StartWeek   int     `json:"start_week"`
+	EndWeek     int     `json:"end_week"`
+	EveryWeeks  int     `json:"every_weeks"`
+	Value       float64 `json:"value"`
+}
+
+type CatalogMealPlanRecord struct {
+	ExternalKey string              `json:"external_key"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	TotalDays   int                 `json:"total_days"`
+	Status      string              `json:"status"`
+	Meals       []CatalogMealRecord `json:"meals"`
+}
+
+type CatalogMealRecord struct {
+	ExternalKey string  `json:"external_key"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Calories    float64 `json:"calories"`
+	Protein     float64 `json:"protein"`
+	Carbs       float64 `json:"carbs"`
+}
+
+type CatalogSubscriptionRecord struct {
+	ExternalKey  string `json:"external_key"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Price        int64  `json:"price"`
+	DurationDays int    `json:"duration_days"`
+	TrialDays    int    `json:"trial_days,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun  bool             `json:"dry_run"`
+	Created map[string]int   `json:"created"`
+	Updated map[string]int   `json:"updated"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+type ImportRowError struct {
+	Table       string `json:"table"`
+	Row         int    `json:"row"`
+	ExternalKey string `json:"external_key"`
+	Error       string `json:"error"`
+}
+
+func NewImportReport(dryRun bool) *ImportReport {
+	return &ImportReport{
+		DryRun:  dryRun,
+		Created: make(map[string]int),
+		Updated: make(map[string]int),
+		Errors:  []ImportRowError{},
+	}
+}
+
+func (r *ImportReport) AddError(table string, row int, externalKey string, err error) {
+	r.Errors = append(r.Errors, ImportRowError{
+		Table:       table,
+		Row:         row,
+		ExternalKey: externalKey,
+		Error:       err.Error(),
+	})
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
+
+const (
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+const (
+	CatalogCategory         = "category"
+	CatalogExercisePlan     = "exercise_plan"
+	CatalogMealPlan         = "meal_plan"
+	CatalogExercisePlanItem = "exercise_plan_item"
+	CatalogMealPlanItem     = "meal_plan_item"
+	CatalogSubscription     = "subscription"
+)
+
+type CatalogVersion struct {
+	gorm.Model
+	EntityType string          `json:"entity_type" gorm:"uniqueIndex:idx_catalog_version"`
+	EntityID   uint            `json:"entity_id" gorm:"uniqueIndex:idx_catalog_version"`
+	Version    int             `json:"version" gorm:"uniqueIndex:idx_catalog_version"`
+	Snapshot   json.RawMessage `json:"snapshot" gorm:"type:jsonb"`
+}
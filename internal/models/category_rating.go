@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+const SortRating = "rating"
+
+// RatingStats are the review counters kept for a rated item.
+type RatingStats struct {
+	Count   int     `json:"count"`
+	Sum     int     `json:"-"`
+	Average float64 `json:"average"`
+	Stars1  int     `json:"-"`
+	Stars2  int     `json:"-"`
+	Stars3  int     `json:"-"`
+	Stars4  int     `json:"-"`
+	Stars5  int     `json:"-"`
+
+	Histogram map[int]int `json:"histogram" gorm:"-"`
+}
+
+func (r *RatingStats) FillHistogram() {
+	r.Histogram = map[int]int{1: r.Stars1, 2: r.Stars2, 3: r.Stars3, 4: r.Stars4, 5: r.Stars5}
+}
+
+// CategoryRating holds review statistics of a category. It is kept up to date
+// on every review change, so catalog listings do not scan the reviews table.
+type CategoryRating struct {
+	CategoriesID uint `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	RatingStats  `gorm:"embedded"`
+	UpdatedAt    time.Time `json:"-"`
+}
+
+// TargetRating holds review statistics of an exercise or meal plan.
+type TargetRating struct {
+	TargetType  string `json:"-" gorm:"primaryKey"`
+	TargetID    uint   `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	RatingStats `gorm:"embedded"`
+	UpdatedAt   time.Time `json:"-"`
+}
+
+// EmptyCategoryRating is the rating of a category nobody has reviewed yet.
+func EmptyCategoryRating(categoryID uint) *CategoryRating {
+	rating := &CategoryRating{CategoriesID: categoryID}
+	rating.FillHistogram()
+	return rating
+}
@@ -0,0 +1,5 @@
+package models
+
+type ClonePlanRequest struct {
+	CategoriesID *uint `json:"categories_id"`
+}
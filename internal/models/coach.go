@@ -0,0 +1,41 @@
+package models
+
+import "gorm.io/gorm"
+
+type CoachProfile struct {
+	gorm.Model
+
+	UserID      uint        `json:"user_id" gorm:"uniqueIndex"`
+	User        *User       `json:"-" gorm:"foreignKey:UserID"`
+	Bio         string      `json:"bio"`
+	Specialties []string    `json:"specialties" gorm:"serializer:json"`
+	PhotoID     *uint       `json:"-"`
+	Photo       *Attachment `json:"-" gorm:"foreignKey:PhotoID"`
+
+	// RevenueShare is the coach's percentage of sales; nil means
+	// DefaultRevenueShare.
+	RevenueShare *int `json:"revenue_share,omitempty"`
+}
+
+type UpdateCoachProfileRequest struct {
+	Bio         *string  `json:"bio"`
+	Specialties []string `json:"specialties"`
+}
+
+// CoachPage is the public view of a coach with the categories they own.
+type CoachPage struct {
+	ID          uint           `json:"id"`
+	Name        string         `json:"name"`
+	Bio         string         `json:"bio"`
+	Specialties []string       `json:"specialties"`
+	Photo       *GetAttachment `json:"photo,omitempty"`
+	Rating      RatingStats    `json:"rating"`
+	Categories  []Categories   `json:"categories,omitempty"`
+}
+
+type CoachRevenue struct {
+	Currency string `json:"currency"`
+	Items    int64  `json:"items"`
+	Total    int64  `json:"total"`
+	Amount   string `json:"amount"`
+}
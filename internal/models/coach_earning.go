@@ -0,0 +1,100 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultRevenueShare is the percentage of a sale paid to a coach who has no
+// individual share set.
+const DefaultRevenueShare = 70
+
+const (
+	EarningAccrual  = "accrual"
+	EarningReversal = "reversal"
+)
+
+const (
+	PayoutPending = "pending"
+	PayoutPaid    = "paid"
+)
+
+// CoachEarning is one line of the coach ledger: the coach's part of a sold
+// order item, or a negative reversal of it after a refund. Lines stay open
+// until they are closed into a payout.
+type CoachEarning struct {
+	gorm.Model
+	CoachID     uint   `json:"coach_id" gorm:"index"`
+	OrderID     uint   `json:"order_id" gorm:"index"`
+	OrderItemID uint   `json:"order_item_id" gorm:"index"`
+	PayoutID    *uint  `json:"payout_id,omitempty" gorm:"index"`
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Currency    string `json:"currency"`
+	Gross       int64  `json:"gross"`
+	Share       int    `json:"share"`
+	Amount      int64  `json:"amount"`
+}
+
+// CoachPayout is the amount owed to a coach in one currency for a closed
+// monthly statement.
+type CoachPayout struct {
+	gorm.Model
+	CoachID  uint       `json:"coach_id" gorm:"uniqueIndex:idx_coach_payout"`
+	Period   string     `json:"period" gorm:"uniqueIndex:idx_coach_payout"`
+	Currency string     `json:"currency" gorm:"uniqueIndex:idx_coach_payout"`
+	Entries  int64      `json:"entries"`
+	Gross    int64      `json:"gross"`
+	Refunds  int64      `json:"refunds"`
+	Amount   int64      `json:"amount"`
+	Status   string     `json:"status" gorm:"default:pending"`
+	PaidAt   *time.Time `json:"paid_at"`
+}
+
+type SetRevenueShareRequest struct {
+	RevenueShare *int `json:"revenue_share" binding:"required,min=0,max=100"`
+}
+
+// PayoutStatement is the monthly statement of a coach. Until the month is
+// closed it is a preview of the open ledger lines.
+type PayoutStatement struct {
+	CoachID      uint           `json:"coach_id"`
+	Period       string         `json:"period"`
+	Closed       bool           `json:"closed"`
+	RevenueShare int            `json:"revenue_share"`
+	Payouts      []CoachPayout  `json:"payouts"`
+	Entries      []CoachEarning `json:"entries"`
+}
+
+// NewPayouts sums ledger lines into one payout per currency, ordered by
+// currency.
+func NewPayouts(coachID uint, period string, entries []CoachEarning) []CoachPayout {
+	byCurrency := map[string]*CoachPayout{}
+	var currencies []string
+	for _, entry := range entries {
+		payout, ok := byCurrency[entry.Currency]
+		if !ok {
+			payout = &CoachPayout{CoachID: coachID, Period: period, Currency: entry.Currency, Status: PayoutPending}
+			byCurrency[entry.Currency] = payout
+			currencies = append(currencies, entry.Currency)
+		}
+
+		payout.Entries++
+		if entry.Kind == EarningReversal {
+			payout.Refunds -= entry.Gross
+		} else {
+			payout.Gross += entry.Gross
+		}
+		payout.Amount += entry.Amount
+	}
+
+	sort.Strings(currencies)
+	payouts := make([]CoachPayout, 0, len(currencies))
+	for _, currency := range currencies {
+		payouts = append(payouts, *byCurrency[currency])
+	}
+
+	return payouts
+}
@@ -7,8 +7,13 @@ type ExercisePlan struct {
 	Name          string `json:"name"`
 	Description   string `json:"description"`
 	DurationWeeks int    `json:"duration_weeks"`
+	Status        string `json:"status" gorm:"default:published"`
+	Version       int    `json:"version"`
+	ExternalKey   string `json:"external_key,omitempty" gorm:"index"`
+	OwnerID       *uint  `json:"owner_id,omitempty" gorm:"index"`
 
-	Exercises []ExercisePlanItem `json:"exercises" gorm:"foreignKey:ExercisePlanID"`
+	Exercises        []ExercisePlanItem `json:"exercises" gorm:"foreignKey:ExercisePlanID"`
+	ProgressionRules []ProgressionRule  `json:"progression_rules" gorm:"foreignKey:ExercisePlanID"`
 
 	CategoriesID uint        `json:"categories_id"`
 	Categories   *Categories `json:"-"`
@@ -19,6 +24,7 @@ type CreateExercesicePlanRequest struct {
 	Description   string `json:"description"`
 	CategoryID    uint `json:"categories_id"`
 	DurationWeeks int  `json:"duration_weeks"`
+	OwnerID       *uint `json:"owner_id"`
 }
 
 type UpdateExercesicePlanRequest struct {
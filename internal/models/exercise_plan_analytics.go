@@ -0,0 +1,15 @@
+package models
+
+type ExercisePlanAnalytics struct {
+	PlanID              uint           `json:"plan_id"`
+	DurationWeeks       int            `json:"duration_weeks"`
+	WeeklySetsByMuscle  map[string]int `json:"weekly_sets_by_muscle_group"`
+	WeeklyMinutes       int            `json:"weekly_minutes"`
+	TotalMinutes        int            `json:"total_minutes"`
+	ExercisesByDay      map[string]int `json:"exercises_by_day"`
+	TrainingDaysPerWeek int            `json:"training_days_per_week"`
+	RestDaysPerWeek     int            `json:"rest_days_per_week"`
+	PushSets            int            `json:"push_sets"`
+	PullSets            int            `json:"pull_sets"`
+	Warnings            []string       `json:"warnings"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	GiftPending  = "pending"
+	GiftAccepted = "accepted"
+	GiftDeclined = "declined"
+	GiftExpired  = "expired"
+)
+
+type Gift struct {
+	gorm.Model
+	Code            string     `json:"code" gorm:"uniqueIndex"`
+	SenderID        uint       `json:"sender_id" gorm:"index"`
+	RecipientID     *uint      `json:"recipient_id" gorm:"index"`
+	RecipientEmail  string     `json:"recipient_email" gorm:"index"`
+	CategoriesID    uint       `json:"categories_id"`
+	CategoryVersion int        `json:"category_version"`
+	Currency        string     `json:"currency" gorm:"default:RUB"`
+	Amount          int64      `json:"amount"`
+	Message         string     `json:"message"`
+	Status          string     `json:"status" gorm:"index;default:pending"`
+	ExpiresAt       time.Time  `json:"expires_at" gorm:"index"`
+	RespondedAt     *time.Time `json:"responded_at"`
+
+	Sender     *User       `json:"-" gorm:"foreignKey:SenderID"`
+	Recipient  *User       `json:"-" gorm:"foreignKey:RecipientID"`
+	Categories *Categories `json:"-" gorm:"foreignKey:CategoriesID"`
+}
+
+type CreateGiftRequest struct {
+	CategoriesID   uint   `json:"categories_id" binding:"required"`
+	RecipientID    *uint  `json:"recipient_id"`
+	RecipientEmail string `json:"recipient_email"`
+	Message        string `json:"message"`
+}
+
+type GiftActionRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+type RedeemGiftRequest struct {
+	Code   string `json:"code" binding:"required"`
+	UserID uint   `json:"user_id" binding:"required"`
+}
+
+type GiftHistory struct {
+	Sent     []Gift `json:"sent"`
+	Received []Gift `json:"received"`
+}
@@ -8,7 +8,11 @@ type MealPlan struct {
 	Description  string         `json:"description"`
 	CategoriesID *uint          `json:"categories_id"`
 	TotalDays    int            `json:"total_days"`
-	Meals        []MealPlanItem `json:"meals" gorm:"foreignKey:ExercisePlanID`
+	Status       string         `json:"status" gorm:"default:published"`
+	Version      int            `json:"version"`
+	ExternalKey  string         `json:"external_key,omitempty" gorm:"index"`
+	OwnerID      *uint          `json:"owner_id,omitempty" gorm:"index"`
+	Meals        []MealPlanItem `json:"meals" gorm:"foreignKey:MealPlanId"`
 	Categories   *Categories    `json:"-"`
 }
 
@@ -17,6 +21,7 @@ type CreateMealPlanRequest struct {
 	Description  string `json:"description"`
 	CategoriesID *uint  `json:"categories_id"`
 	TotalDays    int    `json:"total_days"`
+	OwnerID      *uint  `json:"owner_id"`
 }
 
 type UpdateMealPlanRequest struct {
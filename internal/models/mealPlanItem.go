@@ -9,6 +9,7 @@ type MealPlanItem struct {
 	Calories    float64   `json:"calories"`
 	Protein     float64   `json:"protein"`
 	Carbs       float64   `json:"carbs"`
+	ExternalKey string    `json:"external_key,omitempty" gorm:"index"`
 	MealPlanId  uint      `json:"meal_plan_id"`
 	MealPlan    *MealPlan `json:"-"`
 }
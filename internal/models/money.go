@@ -0,0 +1,99 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	CurrencyRUB = "RUB"
+	CurrencyKZT = "KZT"
+	CurrencyUSD = "USD"
+	CurrencyEUR = "EUR"
+)
+
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// currencyExponents lists supported ISO 4217 codes and their number of minor digits.
+var currencyExponents = map[string]int{
+	CurrencyRUB: 2,
+	CurrencyKZT: 2,
+	CurrencyUSD: 2,
+	CurrencyEUR: 2,
+}
+
+// Money is an amount in minor units (kopecks, tiyn, cents) of Currency.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func IsSupportedCurrency(currency string) bool {
+	_, ok := currencyExponents[currency]
+	return ok
+}
+
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+func MinorPerMajor(currency string) int64 {
+	factor := int64(1)
+	for range currencyExponents[currency] {
+		factor *= 10
+	}
+
+	return factor
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) String() string {
+	factor := MinorPerMajor(m.Currency)
+	if factor == 1 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	width := currencyExponents[m.Currency]
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/factor, width, amount%factor, m.Currency)
+}
+
+type CatalogPrice struct {
+	gorm.Model
+	TargetType string `json:"target_type" gorm:"uniqueIndex:idx_catalog_price"`
+	TargetID   uint   `json:"target_id" gorm:"uniqueIndex:idx_catalog_price"`
+	Currency   string `json:"currency" gorm:"uniqueIndex:idx_catalog_price"`
+	Amount     int64  `json:"amount"`
+}
+
+type SetPricesRequest struct {
+	Prices []Money `json:"prices" binding:"required"`
+}
+
+type UserBalance struct {
+	gorm.Model
+	UserID   uint   `json:"user_id" gorm:"uniqueIndex:idx_user_balance"`
+	Currency string `json:"currency" gorm:"uniqueIndex:idx_user_balance"`
+	Amount   int64  `json:"amount"`
+}
+
+type TopUpRequest struct {
+	Amount   int64  `json:"amount" binding:"required"`
+	Currency string `json:"currency" binding:"required"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelInApp   = "in_app"
+	ChannelLog     = "log"
+)
+
+const (
+	LanguageRU = "ru"
+	LanguageEN = "en"
+)
+
+const (
+	EventPaymentSuccess        = "payment_success"
+	EventCheckoutReceipt       = "checkout_receipt"
+	EventGiftReceived          = "gift_received"
+	EventGiftAccepted          = "gift_accepted"
+	EventGiftDeclined          = "gift_declined"
+	EventGiftExpired           = "gift_expired"
+	EventSubscriptionActivated = "subscription_activated"
+	EventSubscriptionExpiring  = "subscription_expiring"
+	EventTrialEnding           = "trial_ending"
+	EventReviewCreated         = "review_created"
+	EventReviewReply           = "review_reply"
+)
+
+type NotificationEvent struct {
+	EventType string         `json:"event_type"`
+	UserID    uint           `json:"user_id,omitempty"`
+	Email     string         `json:"email,omitempty"`
+	Language  string         `json:"language,omitempty"`
+	Data      map[string]any `json:"data"`
+}
+
+type NotificationPreference struct {
+	gorm.Model
+	UserID  uint   `json:"user_id" gorm:"uniqueIndex:idx_notification_preference"`
+	Channel string `json:"channel" gorm:"uniqueIndex:idx_notification_preference"`
+	Enabled bool   `json:"enabled"`
+	Target  string `json:"target,omitempty"`
+}
+
+type UpdateNotificationPreferenceRequest struct {
+	Channel string  `json:"channel" binding:"required"`
+	Enabled *bool   `json:"enabled"`
+	Target  *string `json:"target"`
+}
+
+type InboxNotification struct {
+	gorm.Model
+	UserID    uint       `json:"user_id" gorm:"index"`
+	EventType string     `json:"event_type"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at" gorm:"index"`
+}
+
+type InboxPage struct {
+	Items    []InboxNotification `json:"items"`
+	Total    int64               `json:"total"`
+	Unread   int64               `json:"unread"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+}
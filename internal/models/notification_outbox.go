@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxDead    = "dead"
+)
+
+type NotificationOutbox struct {
+	gorm.Model
+	EventType     string          `json:"event_type" gorm:"index"`
+	Payload       json.RawMessage `json:"payload" gorm:"type:jsonb"`
+	Status        string          `json:"status" gorm:"index;default:pending"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" gorm:"index"`
+	LastError     string          `json:"last_error"`
+	Delivered     []string        `json:"delivered" gorm:"serializer:json"`
+	SentAt        *time.Time      `json:"sent_at"`
+}
+
+func NewOutboxMessage(event NotificationEvent) (*NotificationOutbox, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NotificationOutbox{
+		EventType:     event.EventType,
+		Payload:       data,
+		Status:        OutboxPending,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const PaymentSourceBalance = "balance"
+
+const TargetGift = "gift"
+
+type Order struct {
+	gorm.Model
+	UserID        uint       `json:"user_id" gorm:"index"`
+	ReceiptNumber string     `json:"receipt_number" gorm:"uniqueIndex"`
+	PaymentSource string     `json:"payment_source"`
+	PromoCodeID   *uint      `json:"promo_code_id"`
+	Currency      string     `json:"currency" gorm:"default:RUB"`
+	ListTotal     int64      `json:"list_total"`
+	Discount      int64      `json:"discount"`
+	Total         int64      `json:"total"`
+	RefundedAt    *time.Time `json:"refunded_at"`
+
+	Items []OrderItem `json:"items" gorm:"foreignKey:OrderID"`
+}
+
+type OrderItem struct {
+	gorm.Model
+	OrderID            uint   `json:"-" gorm:"index"`
+	TargetType         string `json:"target_type"`
+	TargetID           uint   `json:"target_id"`
+	Name               string `json:"name"`
+	ListPrice          int64  `json:"list_price"`
+	Discount           int64  `json:"discount"`
+	FinalPrice         int64  `json:"final_price"`
+	UserPlanID         *uint  `json:"user_plan_id,omitempty"`
+	UserSubscriptionID *uint  `json:"user_subscription_id,omitempty"`
+	GiftID             *uint  `json:"gift_id,omitempty"`
+	CoachID            *uint  `json:"coach_id,omitempty" gorm:"index"`
+}
+
+type ReceiptCounter struct {
+	Year  int `gorm:"primaryKey;autoIncrement:false"`
+	Value int
+}
@@ -0,0 +1,46 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	ProgressionLinearReps  = "linear_reps"
+	ProgressionPercentLoad = "percent_load"
+	ProgressionDeload      = "deload"
+)
+
+type ProgressionRule struct {
+	gorm.Model
+	Type               string  `json:"type"`
+	StartWeek          int     `json:"start_week"`
+	EndWeek            int     `json:"end_week"`
+	EveryWeeks         int     `json:"every_weeks"`
+	Value              float64 `json:"value"`
+	ExercisePlanItemID *uint   `json:"exercise_plan_item_id"`
+
+	ExercisePlanID uint          `json:"exercise_plan_id"`
+	ExercisePlan   *ExercisePlan `json:"-"`
+}
+
+type CreateProgressionRuleRequest struct {
+	Type               string  `json:"type"`
+	StartWeek          int     `json:"start_week"`
+	EndWeek            int     `json:"end_week"`
+	EveryWeeks         int     `json:"every_weeks"`
+	Value              float64 `json:"value"`
+	ExercisePlanItemID *uint   `json:"exercise_plan_item_id"`
+}
+
+type WeekPrescription struct {
+	Week   int                `json:"week"`
+	Deload bool               `json:"deload"`
+	Items  []ItemPrescription `json:"items"`
+}
+
+type ItemPrescription struct {
+	ExercisePlanItemID uint    `json:"exercise_plan_item_id"`
+	Name               string  `json:"name"`
+	DayOfWeek          string  `json:"day_of_week"`
+	Sets               int     `json:"sets"`
+	Reps               int     `json:"reps"`
+	WeightKg           float64 `json:"weight_kg"`
+}
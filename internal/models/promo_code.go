@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+const (
+	TargetCategory     = "category"
+	TargetSubscription = "subscription"
+	TargetCart         = "cart"
+)
+
+type PromoCode struct {
+	gorm.Model
+	Code           string     `json:"code" gorm:"uniqueIndex"`
+	DiscountType   string     `json:"discount_type"`
+	Value          int64      `json:"value"`
+	Currency       string     `json:"currency,omitempty"`
+	ValidFrom      *time.Time `json:"valid_from"`
+	ValidUntil     *time.Time `json:"valid_until"`
+	MaxUses        int        `json:"max_uses"`
+	MaxUsesPerUser int        `json:"max_uses_per_user"`
+	UsedCount      int        `json:"used_count"`
+
+	Restrictions []PromoRestriction `json:"restrictions" gorm:"foreignKey:PromoCodeID"`
+}
+
+type PromoRestriction struct {
+	gorm.Model
+	PromoCodeID uint   `json:"-" gorm:"index"`
+	TargetType  string `json:"target_type"`
+	TargetID    uint   `json:"target_id"`
+}
+
+type PromoRedemption struct {
+	gorm.Model
+	PromoCodeID uint   `json:"promo_code_id" gorm:"index"`
+	UserID      uint   `json:"user_id" gorm:"index"`
+	TargetType  string `json:"target_type"`
+	TargetID    uint   `json:"target_id"`
+	Currency    string `json:"currency"`
+	Discount    int64  `json:"discount"`
+}
+
+type CreatePromoCodeRequest struct {
+	Code           string     `json:"code" binding:"required"`
+	DiscountType   string     `json:"discount_type" binding:"required"`
+	Value          int64      `json:"value" binding:"required"`
+	Currency       string     `json:"currency"`
+	ValidFrom      *time.Time `json:"valid_from"`
+	ValidUntil     *time.Time `json:"valid_until"`
+	MaxUses        int        `json:"max_uses"`
+	MaxUsesPerUser int        `json:"max_uses_per_user"`
+	Categories     []uint     `json:"categories"`
+	Subscriptions  []uint     `json:"subscriptions"`
+}
+
+type PriceQuote struct {
+	TargetType string `json:"target_type"`
+	TargetID   uint   `json:"target_id"`
+	PromoCode  string `json:"promo_code,omitempty"`
+	Currency   string `json:"currency"`
+	ListPrice  int64  `json:"list_price"`
+	Discount   int64  `json:"discount"`
+	FinalPrice int64  `json:"final_price"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "math"
+
+const (
+	GoalLoseWeight = "lose_weight"
+	GoalGainMuscle = "gain_muscle"
+	GoalMaintain   = "maintain"
+	GoalEndurance  = "endurance"
+)
+
+func IsValidGoal(goal string) bool {
+	switch goal {
+	case GoalLoseWeight, GoalGainMuscle, GoalMaintain, GoalEndurance:
+		return true
+	}
+
+	return false
+}
+
+const (
+	BMIUnderweight = "underweight"
+	BMINormal      = "normal"
+	BMIOverweight  = "overweight"
+	BMIObese       = "obese"
+)
+
+// BMI is the body mass index for a weight in kilograms and a height in
+// centimetres, rounded to two decimals.
+func BMI(weightKg, heightCm float64) float64 {
+	meters := heightCm / 100
+	return math.Round(weightKg/(meters*meters)*100) / 100
+}
+
+// BMIBand is the WHO weight band of a BMI value.
+func BMIBand(bmi float64) string {
+	switch {
+	case bmi < 18.5:
+		return BMIUnderweight
+	case bmi < 25:
+		return BMINormal
+	case bmi < 30:
+		return BMIOverweight
+	}
+
+	return BMIObese
+}
+
+type Recommendation struct {
+	Category Categories `json:"category"`
+	Score    int        `json:"score"`
+	Reasons  []string   `json:"reasons"`
+}
+
+type Recommendations struct {
+	UserID  uint             `json:"user_id"`
+	BMI     *float64         `json:"bmi,omitempty"`
+	BMIBand string           `json:"bmi_band,omitempty"`
+	Goal    string           `json:"goal,omitempty"`
+	Items   []Recommendation `json:"items"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SortHelpful = "helpful"
+	SortRecent  = "recent"
+)
+
+type ReviewReply struct {
+	gorm.Model
+
+	ReviewID uint         `json:"review_id" gorm:"index"`
+	ParentID *uint        `json:"parent_id,omitempty" gorm:"index"`
+	Parent   *ReviewReply `json:"-" gorm:"foreignKey:ParentID"`
+	UserID   uint         `json:"user_id"`
+	User     *User        `json:"-" gorm:"foreignKey:UserID"`
+	Content  string       `json:"content"`
+}
+
+type GetReviewReply struct {
+	ID       uint             `json:"id"`
+	ParentID *uint            `json:"parent_id,omitempty"`
+	UserID   uint             `json:"user_id"`
+	Content  string           `json:"content"`
+	Date     string           `json:"date"`
+	Replies  []GetReviewReply `json:"replies,omitempty"`
+}
+
+type CreateReplyRequest struct {
+	UserID   uint   `json:"user_id"`
+	ParentID *uint  `json:"parent_id"`
+	Content  string `json:"content"`
+}
+
+type ReviewVote struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ReviewID  uint      `json:"review_id" gorm:"uniqueIndex:idx_review_vote"`
+	UserID    uint      `json:"user_id" gorm:"uniqueIndex:idx_review_vote"`
+	Helpful   bool      `json:"helpful"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type VoteReviewRequest struct {
+	UserID  uint  `json:"user_id"`
+	Helpful *bool `json:"helpful"`
+}
+
+type ReviewReport struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	ReviewID   uint       `json:"review_id" gorm:"uniqueIndex:idx_review_report"`
+	UserID     uint       `json:"user_id" gorm:"uniqueIndex:idx_review_report"`
+	Reason     string     `json:"reason"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+type ReportReviewRequest struct {
+	UserID uint   `json:"user_id"`
+	Reason string `json:"reason"`
+}
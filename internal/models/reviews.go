@@ -1,28 +1,81 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ReviewTargetCategory     = CatalogCategory
+	ReviewTargetExercisePlan = CatalogExercisePlan
+	ReviewTargetMealPlan     = CatalogMealPlan
+)
+
+func IsReviewTarget(targetType string) bool {
+	return targetType == ReviewTargetCategory || targetType == ReviewTargetExercisePlan || targetType == ReviewTargetMealPlan
+}
+
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
 
 type Reviews struct {
 	gorm.Model
 
-	CategoriesID uint        `json:"categories_id"`
-	Categories   *Categories `json:"-"`
-	UserID       uint        `json:"-"`
-	User         *User       `json:"-" gorm:"foreignKey:UserID"`
-	Rating       int         `json:"-"`
-	Content      string      `json:"-" `
+	// TargetType and TargetID name the reviewed item; CategoriesID is the
+	// category it belongs to, which decides purchase checks and replies.
+	TargetType       string      `json:"-" gorm:"default:category;uniqueIndex:idx_review_user_target,where:deleted_at IS NULL"`
+	TargetID         uint        `json:"-" gorm:"uniqueIndex:idx_review_user_target,where:deleted_at IS NULL"`
+	CategoriesID     uint        `json:"categories_id" gorm:"index"`
+	Categories       *Categories `json:"-"`
+	UserID           uint        `json:"-" gorm:"uniqueIndex:idx_review_user_target,where:deleted_at IS NULL"`
+	User             *User       `json:"-" gorm:"foreignKey:UserID"`
+	Rating           int         `json:"-"`
+	Content          string      `json:"-" `
+	VerifiedPurchase bool        `json:"-"`
+	Status           string      `json:"-" gorm:"default:approved;index"`
+	FilterFlags      string      `json:"-"`
+	ModerationReason string      `json:"-"`
+	ModeratedAt      *time.Time  `json:"-"`
+	HelpfulCount     int         `json:"-"`
+	NotHelpfulCount  int         `json:"-"`
+	ReportCount      int         `json:"-"`
+
+	Attachments []Attachment `json:"-" gorm:"foreignKey:ReviewID"`
 }
 
 type GetReview struct {
-	ID           uint   `json:"id"`
-	CategoriesID uint   `json:"categories_id"`
-	UserID       uint   `json:"user_id"`
-	Rating       int    `json:"rating"`
-	Content      string `json:"content"`
-	Date         string `json:"date"`
+	ID               uint   `json:"id"`
+	TargetType       string `json:"target_type"`
+	TargetID         uint   `json:"target_id"`
+	CategoriesID     uint   `json:"categories_id"`
+	UserID           uint   `json:"user_id"`
+	Rating           int    `json:"rating"`
+	Content          string `json:"content"`
+	VerifiedPurchase bool   `json:"verified_purchase"`
+	Date             string `json:"date"`
+	Helpful          int    `json:"helpful"`
+	NotHelpful       int    `json:"not_helpful"`
+
+	Photos  []GetAttachment  `json:"photos,omitempty"`
+	Replies []GetReviewReply `json:"replies,omitempty"`
+
+	Status           string   `json:"status,omitempty"`
+	Flags            []string `json:"flags,omitempty"`
+	ModerationReason string   `json:"moderation_reason,omitempty"`
+	Reports          int      `json:"reports,omitempty"`
+}
+
+type ModerateReviewRequest struct {
+	Reason string `json:"reason"`
 }
 
 type CreateReviewRequest struct {
+	TargetType   string `json:"target_type"`
+	TargetID     uint   `json:"target_id"`
 	CategoriesID uint   `json:"categories_id"`
 	UserID       uint   `json:"user_id"`
 	Rating       int    `json:"rating"`
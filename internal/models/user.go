@@ -2,25 +2,58 @@ package models
 
 import "gorm.io/gorm"
 
+const (
+	RoleUser  = "user"
+	RoleCoach = "coach"
+	RoleAdmin = "admin"
+)
+
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleCoach || role == RoleAdmin
+}
+
 type User struct {
 	gorm.Model
 	Name         string      `json:"name"`
-	Balance      int         `json:"balance"`
+	Balance      int64       `json:"balance" gorm:"column:balance_minor"`
 	Email        string      `json:"email"`
+	Language     string      `json:"language" gorm:"default:ru"`
+	Currency     string      `json:"currency" gorm:"default:RUB"`
+	Role         string      `json:"role" gorm:"default:user"`
+	HeightCm     float64     `json:"height_cm"`
+	WeightKg     float64     `json:"weight_kg"`
+	Goal         string      `json:"goal"`
 	CategoriesID uint        `json:"categories_id"`
 	Categories   *Categories `json:"-" gorm:"foreignKey:CategoriesID"`
 
 	UserSubscriptions []UserSubscription `json:"userSubscriptions"`
 	UserPlans         []UserPlan         `json:"userPlans"`
+	Balances          []UserBalance      `json:"balances,omitempty"`
 }
 
 type CreateUserRequest struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name     string  `json:"name"`
+	Email    string  `json:"email"`
+	Language string  `json:"language"`
+	Currency string  `json:"currency"`
+	HeightCm float64 `json:"height_cm"`
+	WeightKg float64 `json:"weight_kg"`
+	Goal     string  `json:"goal"`
 }
 
 type UpdateUserRequest struct {
-	Name    *string `json:"name"`
-	Balance *int    `json:"balance"`
-	Email   *string `json:"email"`
+	Name     *string  `json:"name"`
+	Balance  *int64   `json:"balance"`
+	Email    *string  `json:"email"`
+	Language *string  `json:"language"`
+	Currency *string  `json:"currency"`
+	HeightCm *float64 `json:"height_cm"`
+	WeightKg *float64 `json:"weight_kg"`
+	Goal     *string  `json:"goal"`
+}
+
+// SetRoleRequest is the admin-only role change; the public create and update
+// requests never touch the role.
+type SetRoleRequest struct {
+	Role string `json:"role" binding:"required"`
 }
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"fmt"
+	"healthy_body/internal/models"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository interface {
+	Create(attachment *models.Attachment) error
+	GetByID(id uint) (*models.Attachment, error)
+	Delete(id uint) error
+	CountByReview(reviewID uint) (int64, error)
+}
+
+type attachmentRepository struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewAttachmentRepository(db *gorm.DB, log *slog.Logger) AttachmentRepository {
+	return &attachmentRepository{db: db, log: log}
+}
+
+func (r *attachmentRepository) Create(attachment *models.Attachment) error {
+	if err := r.db.Create(attachment).Error; err != nil {
+		r.log.Error("Ошибка при сохранении вложения",
+			"error", err)
+		return fmt.Errorf("ошибка при сохранении вложения %w", err)
+	}
+
+	return nil
+}
+
+func (r *attachmentRepository) GetByID(id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+
+	if err := r.db.First(&attachment, id).Error; err != nil {
+		r.log.Error("Ошибка при поиске вложения",
+			"id", id,
+			"error", err)
+		return nil, fmt.Errorf("ошибка при поиске вложения %w", err)
+	}
+
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) Delete(id uint) error {
+	if err := r.db.Delete(&models.Attachment{}, id).Error; err != nil {
+		r.log.Error("Ошибка при удалении вложения",
+			"id", id,
+			"error", err)
+		return fmt.Errorf("ошибка при удалении вложения %w", err)
+	}
+
+	return nil
+}
+
+func (r *attachmentRepository) CountByReview(reviewID uint) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&models.Attachment{}).Where("review_id = ?", reviewID).Count(&count).Error; err != nil {
+		r.log.Error("Ошибка при подсчете вложений",
+			"review_id", reviewID,
+			"error", err)
+		return 0, fmt.Errorf("ошибка при подсчете вложений %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"healthy_body/internal/models"
+	"log/slog"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CartRepository interface {
+	List(userID uint) ([]models.CartItem, error)
+	Add(item *models.CartItem) error
+	Remove(userID, id uint) error
+}
+
+type cartRepository struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewCartRepository(db *gorm.DB, log *slog.Logger) CartRepository {
+	return &cartRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *cartRepository) List(userID uint) ([]models.CartItem, error) {
+	var items []models.CartItem
+
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&items).Error; err != nil {
+		r.log.Error("failed to list cart items", "user_id", userID, "err", err)
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *cartRepository) Add(item *models.CartItem) error {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(item).Error; err != nil {
+		r.log.Error("failed to add cart item", "user_id", item.UserID, "err", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *cartRepository) Remove(userID, id uint) error {
+	result := r.db.Unscoped().Where("user_id = ?", userID).Delete(&models.CartItem{}, id)
+	if result.Error != nil {
+		r.log.Error("failed to remove cart item", "user_id", userID, "id", id, "err", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
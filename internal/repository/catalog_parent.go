@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"fmt"
+	"healthy_body/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// catalogParent names the plan an exercise or a meal belongs to, or the
+// category a subscription is sold for.
+func catalogParent(db *gorm.DB, targetType string, targetID uint) (string, uint, error) {
+	var (
+		model      any
+		column     string
+		parentType string
+	)
+	switch targetType {
+	case models.CatalogExercisePlanItem:
+		model, column, parentType = &models.ExercisePlanItem{}, "exercise_plan_id", models.CatalogExercisePlan
+	case models.CatalogMealPlanItem:
+		model, column, parentType = &models.MealPlanItem{}, "meal_plan_id", models.CatalogMealPlan
+	case models.CatalogSubscription:
+		model, column, parentType = &models.Subscription{}, "categories_id", models.CatalogCategory
+	default:
+		return "", 0, fmt.Errorf("catalog item type %q has no parent", targetType)
+	}
+
+	parentID, err := catalogColumn(db, model, column, targetID)
+	if err != nil {
+		return "", 0, err
+	}
+	if parentID == nil {
+		return "", 0, gorm.ErrRecordNotFound
+	}
+
+	return parentType, *parentID, nil
+}
+
+// catalogColumn reads a nullable id column of one catalog row.
+func catalogColumn(db *gorm.DB, model any, column string, id uint) (*uint, error) {
+	var values []*uint
+	if err := db.Model(model).Where("id = ?", id).Limit(1).Pluck(column, &values).Error; err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return values[0], nil
+}
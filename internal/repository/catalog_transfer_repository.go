@@ -0,0 +1,399 @@
+package repository
+
+import (
+	"errors"
+	"healthy_body/internal/models"
+	"log/slog"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errImportRollback = errors.New("catalog import rolled back")
+
+type CatalogTransferRepository interface {
+	LoadCatalog() ([]models.Categories, []models.Subscription, error)
+	Import(doc *models.CatalogDocument, report *models.ImportReport) error
+}
+
+type catalogTransferRepository struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewCatalogTransferRepository(db *gorm.DB, log *slog.Logger) CatalogTransferRepository {
+	return &catalogTransferRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *catalogTransferRepository) LoadCatalog() ([]models.Categories, []models.Subscription, error) {
+	var categories []models.Categories
+	if err := r.db.
+		Preload("ExercisePlans", orderByID).
+		Preload("ExercisePlans.Exercises", orderByID).
+		Preload("ExercisePlans.ProgressionRules", orderByID).
+		Preload("MealPlans", orderByID).
+		Preload("MealPlans.Meals", orderByID).
+		Order("id").
+		Find(&categories).Error; err != nil {
+		r.log.Error("failed to load catalog categories", "err", err)
+		return nil, nil, err
+	}
+
+	var subscriptions []models.Subscription
+	if err := r.db.Order("id").Find(&subscriptions).Error; err != nil {
+		r.log.Error("failed to load catalog subscriptions", "err", err)
+		return nil, nil, err
+	}
+
+	fillExternalKeys(categories, subscriptions)
+	return categories, subscriptions, nil
+}
+
+const (
+	categoryKeyPrefix     = "category-"
+	exercisePlanKeyPrefix = "exercise-plan-"
+	exerciseKeyPrefix     = "exercise-"
+	mealPlanKeyPrefix     = "meal-plan-"
+	mealKeyPrefix         = "meal-"
+	subscriptionKeyPrefix = "subscription-"
+)
+
+// PrepareExternalKeys stores a generated key on every catalog row that has
+// none, so an exported document imports back onto the same rows. It runs
+// after AutoMigrate, since it needs the external_key columns, and again at
+// the start of every import.
+func PrepareExternalKeys(db *gorm.DB) error {
+	tables := []struct {
+		model  any
+		prefix string
+	}{
+		{&models.Categories{}, categoryKeyPrefix},
+		{&models.ExercisePlan{}, exercisePlanKeyPrefix},
+		{&models.ExercisePlanItem{}, exerciseKeyPrefix},
+		{&models.MealPlan{}, mealPlanKeyPrefix},
+		{&models.MealPlanItem{}, mealKeyPrefix},
+		{&models.Subscription{}, subscriptionKeyPrefix},
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range tables {
+			if err := tx.Model(table.model).
+				Where("external_key IS NULL OR external_key = ''").
+				Update("external_key", gorm.Expr("CONCAT(CAST(? AS text), id)", table.prefix)).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// fillExternalKeys gives rows created since the last PrepareExternalKeys the
+// key it would store for them, without writing anything: export stays
+// read-only.
+func fillExternalKeys(categories []models.Categories, subscriptions []models.Subscription) {
+	key := func(current *string, prefix string, id uint) {
+		if *current == "" {
+			*current = prefix + strconv.FormatUint(uint64(id), 10)
+		}
+	}
+
+	for i := range categories {
+		category := &categories[i]
+		key(&category.ExternalKey, categoryKeyPrefix, category.ID)
+		for j := range category.ExercisePlans {
+			plan := &category.ExercisePlans[j]
+			key(&plan.ExternalKey, exercisePlanKeyPrefix, plan.ID)
+			for k := range plan.Exercises {
+				key(&plan.Exercises[k].ExternalKey, exerciseKeyPrefix, plan.Exercises[k].ID)
+			}
+		}
+		for j := range category.MealPlans {
+			mealPlan := &category.MealPlans[j]
+			key(&mealPlan.ExternalKey, mealPlanKeyPrefix, mealPlan.ID)
+			for k := range mealPlan.Meals {
+				key(&mealPlan.Meals[k].ExternalKey, mealKeyPrefix, mealPlan.Meals[k].ID)
+			}
+		}
+	}
+	for i := range subscriptions {
+		key(&subscriptions[i].ExternalKey, subscriptionKeyPrefix, subscriptions[i].ID)
+	}
+}
+
+// Import only ever writes drafts: a category or plan whose key matches a
+// published row gets a new draft next to it, and items are matched within
+// their parent so a draft never takes rows away from a published plan.
+func (r *catalogTransferRepository) Import(doc *models.CatalogDocument, report *models.ImportReport) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// rows created since startup still have no key to be matched on
+		if err := PrepareExternalKeys(tx); err != nil {
+			return err
+		}
+
+		rows := make(map[string]int)
+
+		for _, categoryRecord := range doc.Categories {
+			rows[models.TableCategories]++
+			category := models.Categories{}
+			created, err := upsertByExternalKey(tx, &category, categoryRecord.ExternalKey, draftRow, func() {
+				category.Name = categoryRecord.Name
+				category.Description = categoryRecord.Description
+				category.Price = categoryRecord.Price
+				// documents exported before goals keep the stored ones
+				if categoryRecord.Goals != nil {
+					category.Goals = categoryRecord.Goals
+				}
+			})
+			if err != nil {
+				report.AddError(models.TableCategories, rows[models.TableCategories], categoryRecord.ExternalKey, err)
+				skipCategoryRows(rows, categoryRecord)
+				continue
+			}
+			countImported(report, models.TableCategories, created)
+
+			for _, planRecord := range categoryRecord.ExercisePlans {
+				rows[models.TableExercisePlans]++
+				plan := models.ExercisePlan{}
+				created, err := upsertByExternalKey(tx, &plan, planRecord.ExternalKey, draftIn("categories_id", category.ID), func() {
+					plan.Name = planRecord.Name
+					plan.Description = planRecord.Description
+					plan.DurationWeeks = planRecord.DurationWeeks
+					plan.CategoriesID = category.ID
+				})
+				if err != nil {
+					report.AddError(models.TableExercisePlans, rows[models.TableExercisePlans], planRecord.ExternalKey, err)
+					rows[models.TableExercisePlanItems] += len(planRecord.Exercises)
+					rows[models.TableProgressionRules] += len(planRecord.ProgressionRules)
+					continue
+				}
+				countImported(report, models.TableExercisePlans, created)
+
+				itemIDs := make(map[string]uint, len(planRecord.Exercises))
+				for _, exerciseRecord := range planRecord.Exercises {
+					rows[models.TableExercisePlanItems]++
+					item := models.ExercisePlanItem{}
+					created, err := upsertByExternalKey(tx, &item, exerciseRecord.ExternalKey, childOf("exercise_plan_id", plan.ID), func() {
+						item.Name = exerciseRecord.Name
+						item.Sets = exerciseRecord.Sets
+						item.Reps = exerciseRecord.Reps
+						item.DurationMinutes = exerciseRecord.DurationMinutes
+						item.EquipmentNeeded = exerciseRecord.EquipmentNeeded
+						item.DayOfWeek = exerciseRecord.DayOfWeek
+						item.MuscleGroup = exerciseRecord.MuscleGroup
+						item.WeightKg = exerciseRecord.WeightKg
+						item.ExercisePlanID = plan.ID
+					})
+					if err != nil {
+						report.AddError(models.TableExercisePlanItems, rows[models.TableExercisePlanItems], exerciseRecord.ExternalKey, err)
+						continue
+					}
+					countImported(report, models.TableExercisePlanItems, created)
+					itemIDs[exerciseRecord.ExternalKey] = item.ID
+				}
+
+				if err := importProgressionRules(tx, report, rows, plan.ID, planRecord.ProgressionRules, itemIDs); err != nil {
+					return err
+				}
+			}
+
+			for _, mealPlanRecord := range categoryRecord.MealPlans {
+				rows[models.TableMealPlans]++
+				mealPlan := models.MealPlan{}
+				created, err := upsertByExternalKey(tx, &mealPlan, mealPlanRecord.ExternalKey, draftIn("categories_id", category.ID), func() {
+					mealPlan.Name = mealPlanRecord.Name
+					mealPlan.Description = mealPlanRecord.Description
+					mealPlan.TotalDays = mealPlanRecord.TotalDays
+					mealPlan.CategoriesID = &category.ID
+				})
+				if err != nil {
+					report.AddError(models.TableMealPlans, rows[models.TableMealPlans], mealPlanRecord.ExternalKey, err)
+					rows[models.TableMealPlanItems] += len(mealPlanRecord.Meals)
+					continue
+				}
+				countImported(report, models.TableMealPlans, created)
+
+				for _, mealRecord := range mealPlanRecord.Meals {
+					rows[models.TableMealPlanItems]++
+					meal := models.MealPlanItem{}
+					created, err := upsertByExternalKey(tx, &meal, mealRecord.ExternalKey, childOf("meal_plan_id", mealPlan.ID), func() {
+						meal.Name = mealRecord.Name
+						meal.Description = mealRecord.Description
+						meal.Calories = mealRecord.Calories
+						meal.Protein = mealRecord.Protein
+						meal.Carbs = mealRecord.Carbs
+						meal.MealPlanId = mealPlan.ID
+					})
+					if err != nil {
+						report.AddError(models.TableMealPlanItems, rows[models.TableMealPlanItems], mealRecord.ExternalKey, err)
+						continue
+					}
+					countImported(report, models.TableMealPlanItems, created)
+				}
+			}
+
+			// subscriptions follow their category: under a draft they stay
+			// off sale until the category is published
+			for _, subRecord := range categoryRecord.Subscriptions {
+				rows[models.TableSubscriptions]++
+				sub := models.Subscription{}
+				created, err := upsertByExternalKey(tx, &sub, subRecord.ExternalKey, childOf("categories_id", category.ID), func() {
+					sub.Name = subRecord.Name
+					sub.Description = subRecord.Description
+					sub.Price = subRecord.Price
+					sub.DurationDays = subRecord.DurationDays
+					sub.TrialDays = subRecord.TrialDays
+					sub.CategoriesID = category.ID
+				})
+				if err != nil {
+					report.AddError(models.TableSubscriptions, rows[models.TableSubscriptions], subRecord.ExternalKey, err)
+					continue
+				}
+				countImported(report, models.TableSubscriptions, created)
+			}
+		}
+
+		if report.DryRun || len(report.Errors) > 0 {
+			return errImportRollback
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, errImportRollback) {
+		r.log.Info("catalog import rolled back", "dry_run", report.DryRun, "errors", len(report.Errors))
+		return nil
+	}
+	if err != nil {
+		r.log.Error("failed to import catalog", "err", err)
+		return err
+	}
+
+	r.log.Info("catalog imported", "created", report.Created, "updated", report.Updated)
+	return nil
+}
+
+// importProgressionRules replaces the rules of an imported plan with the ones
+// in the document.
+func importProgressionRules(tx *gorm.DB, report *models.ImportReport, rows map[string]int, planID uint, records []models.CatalogProgressionRuleRecord, itemIDs map[string]uint) error {
+	if err := tx.Where("exercise_plan_id = ?", planID).Delete(&models.ProgressionRule{}).Error; err != nil {
+		return err
+	}
+
+	for _, ruleRecord := range records {
+		rows[models.TableProgressionRules]++
+		rule := models.ProgressionRule{
+			Type:           ruleRecord.Type,
+			StartWeek:      ruleRecord.StartWeek,
+			EndWeek:        ruleRecord.EndWeek,
+			EveryWeeks:     ruleRecord.EveryWeeks,
+			Value:          ruleRecord.Value,
+			ExercisePlanID: planID,
+		}
+		if ruleRecord.ExerciseKey != "" {
+			itemID, ok := itemIDs[ruleRecord.ExerciseKey]
+			if !ok {
+				report.AddError(models.TableProgressionRules, rows[models.TableProgressionRules], ruleRecord.ExerciseKey, errors.New("exercise was not imported"))
+				continue
+			}
+			rule.ExercisePlanItemID = &itemID
+		}
+
+		if err := tx.Create(&rule).Error; err != nil {
+			return err
+		}
+		countImported(report, models.TableProgressionRules, true)
+	}
+
+	return nil
+}
+
+// draftRow matches only draft rows.
+func draftRow(db *gorm.DB) *gorm.DB {
+	return db.Where("status = ?", models.StatusDraft)
+}
+
+// draftIn matches draft rows under the given parent.
+func draftIn(column string, parentID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return draftRow(db).Where(column+" = ?", parentID)
+	}
+}
+
+// childOf matches rows under the given parent.
+func childOf(column string, parentID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(column+" = ?", parentID)
+	}
+}
+
+func upsertByExternalKey[T any](tx *gorm.DB, record *T, externalKey string, scope func(*gorm.DB) *gorm.DB, apply func()) (bool, error) {
+	created := false
+
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		err := tx.Scopes(scope).Where("external_key = ?", externalKey).First(record).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		created = errors.Is(err, gorm.ErrRecordNotFound)
+
+		apply()
+
+		if created {
+			setImportedDefaults(record, externalKey)
+			return tx.Omit(clause.Associations).Create(record).Error
+		}
+
+		return tx.Omit(clause.Associations).Save(record).Error
+	})
+
+	return created, err
+}
+
+func setImportedDefaults(record any, externalKey string) {
+	switch r := record.(type) {
+	case *models.Categories:
+		r.ExternalKey = externalKey
+		r.Status = models.StatusDraft
+	case *models.ExercisePlan:
+		r.ExternalKey = externalKey
+		r.Status = models.StatusDraft
+	case *models.MealPlan:
+		r.ExternalKey = externalKey
+		r.Status = models.StatusDraft
+	case *models.ExercisePlanItem:
+		r.ExternalKey = externalKey
+	case *models.MealPlanItem:
+		r.ExternalKey = externalKey
+	case *models.Subscription:
+		r.ExternalKey = externalKey
+	}
+}
+
+func skipCategoryRows(rows map[string]int, record models.CatalogCategoryRecord) {
+	rows[models.TableExercisePlans] += len(record.ExercisePlans)
+	for _, plan := range record.ExercisePlans {
+		rows[models.TableExercisePlanItems] += len(plan.Exercises)
+		rows[models.TableProgressionRules] += len(plan.ProgressionRules)
+	}
+
+	rows[models.TableMealPlans] += len(record.MealPlans)
+	for _, mealPlan := range record.MealPlans {
+		rows[models.TableMealPlanItems] += len(mealPlan.Meals)
+	}
+
+	rows[models.TableSubscriptions] += len(record.Subscriptions)
+}
+
+func countImported(report *models.ImportReport, table string, created bool) {
+	if created {
+		report.Created[table]++
+		return
+	}
+
+	report.Updated[table]++
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"healthy_body/internal/models"
+	"log/slog"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CatalogVersionRepository interface {
+	Publish(entityType string, id uint) (*models.CatalogVersion, error)
+	SetStatus(entityType string, id uint, status string) error
+	ListVersions(entityType string, id uint) ([]models.CatalogVersion, error)
+	GetVersion(entityType string, id uint, version int) (*models.CatalogVersion, error)
+}
+
+type catalogVersionRepository struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewCatalogVersionRepository(db *gorm.DB, log *slog.Logger) CatalogVersionRepository {
+	return &catalogVersionRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *catalogVersionRepository) Publish(entityType string, id uint) (*models.CatalogVersion, error) {
+	var version *models.CatalogVersion
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		entity, err := loadCatalogEntity(tx.Clauses(clause.Locking{Strength: "UPDATE"}), entityType, id)
+		if err != nil {
+			return err
+		}
+
+		next := catalogEntityVersion(entity) + 1
+		if err := tx.Model(entity).UpdateColumns(map[string]any{
+			"status":  models.StatusPublished,
+			"version": next,
+		}).Error; err != nil {
+			return err
+		}
+
+		entity, err = loadCatalogEntity(tx, entityType, id)
+		if err != nil {
+			return err
+		}
+
+		snapshot, err := json.Marshal(entity)
+		if err != nil {
+			return err
+		}
+
+		version = &models.CatalogVersion{
+			EntityType: entityType,
+			EntityID:   id,
+			Version:    next,
+			Snapshot:   snapshot,
+		}
+
+		return tx.Create(version).Error
+	})
+	if err != nil {
+		r.log.Error("failed to publish catalog entity", "entity_type", entityType, "id", id, "err", err)
+		return nil, err
+	}
+
+	r.log.Info("catalog entity published", "entity_type", entityType, "id", id, "version", version.Version)
+	return version, nil
+}
+
+func (r *catalogVersionRepository) SetStatus(entityType string, id uint, status string) error {
+	model, err := catalogModel(entityType)
+	if err != nil {
+		return err
+	}
+
+	result := r.db.Model(model).Where("id = ?", id).UpdateColumn("status", status)
+	if result.Error != nil {
+		r.log.Error("failed to change catalog status", "entity_type", entityType, "id", id, "err", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	r.log.Info("catalog status changed", "entity_type", entityType, "id", id, "status", status)
+	return nil
+}
+
+func (r *catalogVersionRepository) ListVersions(entityType string, id uint) ([]models.CatalogVersion, error) {
+	var versions []models.CatalogVersion
+
+	if err := r.db.Omit("Snapshot").
+		Where("entity_type = ? AND entity_id = ?", entityType, id).
+		Order("version").
+		Find(&versions).Error; err != nil {
+		r.log.Error("failed to fetch catalog versions", "entity_type", entityType, "id", id, "err", err)
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func (r *catalogVersionRepository) GetVersion(entityType string, id uint, version int) (*models.CatalogVersion, error) {
+	var result models.CatalogVersion
+
+	if err := r.db.Where("entity_type = ? AND entity_id = ? AND version = ?", entityType, id, version).
+		First(&result).Error; err != nil {
+		r.log.Error("failed to fetch catalog version", "entity_type", entityType, "id", id, "version", version, "err", err)
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func catalogModel(entityType string) (any, error) {
+	switch entityType {
+	case models.CatalogCategory:
+		return &models.Categories{}, nil
+	case models.CatalogExercisePlan:
+		return &models.ExercisePlan{}, nil
+	case models.CatalogMealPlan:
+		return &models.MealPlan{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown catalog entity type %q", entityType)
+}
+
+func loadCatalogEntity(tx *gorm.DB, entityType string, id uint) (any, error) {
+	switch entityType {
+	case models.CatalogCategory:
+		var category models.Categories
+		err := tx.
+			Preload("ExercisePlans", orderByID).
+			Preload("ExercisePlans.Exercises", orderByID).
+			Preload("ExercisePlans.ProgressionRules", orderByID).
+			Preload("MealPlans", orderByID).
+			Preload("MealPlans.Meals", orderByID).
+			First(&category, id).Error
+		return &category, err
+	case models.CatalogExercisePlan:
+		var plan models.ExercisePlan
+		err := tx.Preload("Exercises", orderByID).Preload("ProgressionRules", orderByID).First(&plan, id).Error
+		return &plan, err
+	case models.CatalogMealPlan:
+		var mealPlan models.MealPlan
+		err := tx.Preload("Meals", orderByID).First(&mealPlan, id).Error
+		return &mealPlan, err
+	}
+
+	return nil, fmt.Errorf("unknown catalog entity type %q", entityType)
+}
+
+func catalogEntityVersion(entity any) int {
+	switch e := entity.(type) {
+	case *models.Categories:
+		return e.Version
+	case *models.ExercisePlan:
+		return e.Version
+	case *models.MealPlan:
+		return e.Version
+	}
+
+	return 0
+}
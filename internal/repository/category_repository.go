@@ -6,15 +6,18 @@ import (
 	"log/slog"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CategoryRepo interface {
 	 Create(category *models.Categories) error
-	 List() ([]models.Categories, error)
+	 List(status string, ownerID *uint, sort string) ([]models.Categories, error)
+	 Ratings(ids []uint) (map[uint]*models.CategoryRating, error)
 	 GetByID(id uint) (*models.Categories,error)
 	 GetWithPlans(id uint) (*models.Categories, error)
 	 Update(category *models.Categories) error
 	 Delete(id uint) error
+	 Clone(id uint) (*models.Categories, error)
 }
 
 type categoryRepo struct {
@@ -40,9 +43,20 @@ func (c *categoryRepo) Create(category *models.Categories) error {
 }
 
 
-func (c *categoryRepo) List() ([]models.Categories, error){
+func (c *categoryRepo) List(status string, ownerID *uint, sort string) ([]models.Categories, error){
 	var list []models.Categories
-	if err:= c.db.Find(&list).Error; err != nil {
+	query := c.db
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if ownerID != nil {
+		query = query.Where("owner_id = ?", *ownerID)
+	}
+	if sort == models.SortRating {
+		query = query.Select("categories.*").Joins("LEFT JOIN category_ratings ON category_ratings.categories_id = categories.id").
+			Order("COALESCE(category_ratings.average, 0) DESC, COALESCE(category_ratings.count, 0) DESC, categories.id")
+	}
+	if err:= query.Find(&list).Error; err != nil {
 		c.log.Error("error in List function category_repository.go")
 		return nil, err
 	}
@@ -51,6 +65,26 @@ func (c *categoryRepo) List() ([]models.Categories, error){
 }
 
 
+func (c *categoryRepo) Ratings(ids []uint) (map[uint]*models.CategoryRating, error) {
+	var ratings []models.CategoryRating
+	if err := c.db.Where("categories_id IN ?", ids).Find(&ratings).Error; err != nil {
+		c.log.Error("error in Ratings function category_repository.go", "err", err)
+		return nil, err
+	}
+
+	result := make(map[uint]*models.CategoryRating, len(ids))
+	for _, id := range ids {
+		result[id] = models.EmptyCategoryRating(id)
+	}
+	for i := range ratings {
+		ratings[i].FillHistogram()
+		result[ratings[i].CategoriesID] = &ratings[i]
+	}
+
+	return result, nil
+}
+
+
 func (c *categoryRepo) GetByID(id uint) (*models.Categories,error) {
 	var category models.Categories
 	if err := c.db.Preload("ExercisePlans.Exercises").Preload("MealPlans.Meals").First(&category,id).Error; err != nil {
@@ -94,3 +128,57 @@ func (c *categoryRepo) Delete(id uint) error {
 
 	return  nil 
 }
+
+
+func (c *categoryRepo) Clone(id uint) (*models.Categories, error) {
+	var clone *models.Categories
+
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var source models.Categories
+		if err := tx.
+			Preload("ExercisePlans", orderByID).
+			Preload("ExercisePlans.Exercises", orderByID).
+			Preload("ExercisePlans.ProgressionRules", orderByID).
+			Preload("MealPlans", orderByID).
+			Preload("MealPlans.Meals", orderByID).
+			First(&source, id).Error; err != nil {
+			return err
+		}
+
+		clone = &models.Categories{
+			Name:        source.Name,
+			Description: source.Description,
+			Price:       source.Price,
+			Status:      models.StatusDraft,
+			OwnerID:     source.OwnerID,
+			Goals:       source.Goals,
+		}
+		if err := tx.Omit(clause.Associations).Create(clone).Error; err != nil {
+			return err
+		}
+
+		for i := range source.ExercisePlans {
+			plan, err := cloneExercisePlan(tx, &source.ExercisePlans[i], clone.ID)
+			if err != nil {
+				return err
+			}
+			clone.ExercisePlans = append(clone.ExercisePlans, *plan)
+		}
+
+		for i := range source.MealPlans {
+			mealPlan, err := cloneMealPlan(tx, &source.MealPlans[i], &clone.ID)
+			if err != nil {
+				return err
+			}
+			clone.MealPlans = append(clone.MealPlans, *mealPlan)
+		}
+
+		return nil
+	})
+	if err != nil {
+		c.log.Error("error in Clone function category_repository.go", "id", id, "err", err)
+		return nil, err
+	}
+
+	return clone, nil
+}
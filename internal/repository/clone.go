@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"healthy_body/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func orderByID(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+func cloneExercisePlan(tx *gorm.DB, source *models.ExercisePlan, categoryID uint) (*models.ExercisePlan, error) {
+	plan := &models.ExercisePlan{
+		Name:          source.Name,
+		Description:   source.Description,
+		DurationWeeks: source.DurationWeeks,
+		CategoriesID:  categoryID,
+		Status:        models.StatusDraft,
+		OwnerID:       source.OwnerID,
+	}
+
+	if err := tx.Omit(clause.Associations).Create(plan).Error; err != nil {
+		return nil, err
+	}
+
+	itemIDs := make(map[uint]uint, len(source.Exercises))
+	for _, sourceItem := range source.Exercises {
+		item := sourceItem
+		item.ID = 0
+		item.ExternalKey = ""
+		item.ExercisePlanID = plan.ID
+		item.ExercisePlan = nil
+
+		if err := tx.Create(&item).Error; err != nil {
+			return nil, err
+		}
+
+		itemIDs[sourceItem.ID] = item.ID
+		plan.Exercises = append(plan.Exercises, item)
+	}
+
+	for _, sourceRule := range source.ProgressionRules {
+		rule := models.ProgressionRule{
+			Type:           sourceRule.Type,
+			StartWeek:      sourceRule.StartWeek,
+			EndWeek:        sourceRule.EndWeek,
+			EveryWeeks:     sourceRule.EveryWeeks,
+			Value:          sourceRule.Value,
+			ExercisePlanID: plan.ID,
+		}
+
+		if sourceRule.ExercisePlanItemID != nil {
+			// A rule for an item that is no longer in the plan has nothing to
+			// progress in the clone.
+			itemID, ok := itemIDs[*sourceRule.ExercisePlanItemID]
+			if !ok {
+				continue
+			}
+			rule.ExercisePlanItemID = &itemID
+		}
+
+		if err := tx.Create(&rule).Error; err != nil {
+			return nil, err
+		}
+
+		plan.ProgressionRules = append(plan.ProgressionRules, rule)
+	}
+
+	return plan, nil
+}
+
+func cloneMealPlan(tx *gorm.DB, source *models.MealPlan, categoryID *uint) (*models.MealPlan, error) {
+	mealPlan := &models.MealPlan{
+		Name:         source.Name,
+		Description:  source.Description,
+		CategoriesID: categoryID,
+		TotalDays:    source.TotalDays,
+		Status:       models.StatusDraft,
+		OwnerID:      source.OwnerID,
+	}
+
+	if err := tx.Omit(clause.Associations).Create(mealPlan).Error; err != nil {
+		return nil, err
+	}
+
+	for _, sourceMeal := range source.Meals {
+		meal := models.MealPlanItem{
+			Name:        sourceMeal.Name,
+			Description: sourceMeal.Description,
+			Calories:    sourceMeal.Calories,
+			Protein:     sourceMeal.Protein,
+			Carbs:       sourceMeal.Carbs,
+			MealPlanId:  mealPlan.ID,
+		}
+
+		if err := tx.Create(&meal).Error; err != nil {
+			return nil, err
+		}
+
+		mealPlan.Meals = append(mealPlan.Meals, meal)
+	}
+
+	return mealPlan, nil
+}
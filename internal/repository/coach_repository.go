@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPeriodClosed = errors.New("payout period is already closed")
+	ErrPayoutPaid   = errors.New("payout is already paid")
+)
+
+type CoachRepository interface {
+	GetProfile(userID uint) (*models.CoachProfile, error)
+	ListProfiles() ([]models.CoachProfile, error)
+	SaveProfile(profile *models.CoachProfile) error
+	OwnedCategories(userID uint) ([]models.Categories, error)
+	Owner(targetType string, targetID uint) (uint, error)
+	Revenue(coachID uint, from, to time.Time) ([]models.CoachRevenue, error)
+
+	SetRevenueShare(coachID uint, share int) error
+	Earnings(coachID uint, from, to time.Time) ([]models.CoachEarning, error)
+	OpenEarnings(coachID uint, before time.Time) ([]models.CoachEarning, error)
+	Statement(coachID uint, period string) ([]models.CoachPayout, []models.CoachEarning, error)
+	ClosePeriod(coachID uint, period string, before time.Time) ([]models.CoachPayout, error)
+	MarkPaid(coachID, payoutID uint, at time.Time) (*models.CoachPayout, error)
+}
+
+type coachRepository struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewCoachRepository(db *gorm.DB, log *slog.Logger) CoachRepository {
+	return &coachRepository{db: db, log: log}
+}
+
+// GetProfile returns the coach profile, or an empty one for a coach who has
+// not filled it in yet.
+func (r *coachRepository) GetProfile(userID uint) (*models.CoachProfile, error) {
+	profile := models.CoachProfile{UserID: userID}
+
+	err := r.db.Preload("User").Preload("Photo").Where("user_id = ?", userID).First(&profile).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		r.log.Error("failed to load coach profile", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to load coach profile: %w", err)
+	}
+
+	return &profile, nil
+}
+
+func (r *coachRepository) ListProfiles() ([]models.CoachProfile, error) {
+	var profiles []models.CoachProfile
+
+	if err := r.db.Preload("User").Preload("Photo").
+		Joins("JOIN users ON users.id = coach_profiles.user_id AND users.deleted_at IS NULL").
+		Where("users.role = ?", models.RoleCoach).
+		Order("coach_profiles.user_id").
+		Find(&profiles).Error; err != nil {
+		r.log.Error("failed to list coach profiles", "error", err)
+		return nil, fmt.Errorf("failed to list coach profiles: %w", err)
+	}
+
+	return profiles, nil
+}
+
+func (r *coachRepository) SaveProfile(profile *models.CoachProfile) error {
+	if err := r.db.Omit("User", "Photo").Save(profile).Error; err != nil {
+		r.log.Error("failed to save coach profile", "user_id", profile.UserID, "error", err)
+		return fmt.Errorf("failed to save coach profile: %w", err)
+	}
+
+	return nil
+}
+
+func (r *coachRepository) OwnedCategories(userID uint) ([]models.Categories, error) {
+	var categories []models.Categories
+
+	if err := r.db.Where("owner_id = ? AND status = ?", userID, models.StatusPublished).
+		Order("id").Find(&categories).Error; err != nil {
+		r.log.Error("failed to list coach categories", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to list coach categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// Owner returns the owner of a catalog item, or 0 when nobody owns it.
+func (r *coachRepository) Owner(targetType string, targetID uint) (uint, error) {
+	var model any
+	switch targetType {
+	case models.CatalogCategory:
+		model = &models.Categories{}
+	case models.CatalogExercisePlan:
+		model = &models.ExercisePlan{}
+	case models.CatalogMealPlan:
+		model = &models.MealPlan{}
+	case models.CatalogExercisePlanItem, models.CatalogMealPlanItem, models.CatalogSubscription:
+		// exercises, meals and subscriptions belong to whoever owns their
+		// plan or category
+		parentType, parentID, err := catalogParent(r.db, targetType, targetID)
+		if err != nil {
+			return 0, err
+		}
+		return r.Owner(parentType, parentID)
+	default:
+		return 0, fmt.Errorf("unknown catalog item type %q", targetType)
+	}
+
+	owner, err := catalogColumn(r.db, model, "owner_id", targetID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	if err != nil {
+		r.log.Error("failed to load catalog item owner", "type", targetType, "id", targetID, "error", err)
+		return 0, fmt.Errorf("failed to load catalog item owner: %w", err)
+	}
+	if owner == nil {
+		return 0, nil
+	}
+
+	return *owner, nil
+}
+
+// Revenue sums the coach's paid, not refunded order items per currency.
+func (r *coachRepository) Revenue(coachID uint, from, to time.Time) ([]models.CoachRevenue, error) {
+	var revenue []models.CoachRevenue
+
+	if err := r.db.Table("order_items").
+		Select("orders.currency AS currency, COUNT(*) AS items, SUM(order_items.final_price) AS total").
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Where("order_items.coach_id = ? AND order_items.deleted_at IS NULL AND orders.refunded_at IS NULL", coachID).
+		Where("orders.created_at >= ? AND orders.created_at < ?", from, to).
+		Group("orders.currency").
+		Order("orders.currency").
+		Scan(&revenue).Error; err != nil {
+		r.log.Error("failed to load coach revenue", "coach_id", coachID, "error", err)
+		return nil, fmt.Errorf("failed to load coach revenue: %w", err)
+	}
+
+	return revenue, nil
+}
+
+func (r *coachRepository) SetRevenueShare(coachID uint, share int) error {
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]any{"revenue_share": share, "updated_at": time.Now()}),
+	}).Create(&models.CoachProfile{UserID: coachID, RevenueShare: &share}).Error; err != nil {
+		r.log.Error("failed to set coach revenue share", "coach_id", coachID, "error", err)
+		return fmt.Errorf("failed to set coach revenue share: %w", err)
+	}
+
+	return nil
+}
+
+func (r *coachRepository) Earnings(coachID uint, from, to time.Time) ([]models.CoachEarning, error) {
+	var earnings []models.CoachEarning
+
+	if err := r.db.Where("coach_id = ? AND created_at >= ? AND created_at < ?", coachID, from, to).
+		Order("created_at, id").Find(&earnings).Error; err != nil {
+		r.log.Error("failed to list coach earnings", "coach_id", coachID, "error", err)
+		return nil, fmt.Errorf("failed to list coach earnings: %w", err)
+	}
+
+	return earnings, nil
+}
+
+// OpenEarnings lists the ledger lines booked before the given time that are
+// not part of a payout yet.
+func (r *coachRepository) OpenEarnings(coachID uint, before time.Time) ([]models.CoachEarning, error) {
+	var earnings []models.CoachEarning
+
+	if err := r.db.Where("coach_id = ? AND payout_id IS NULL AND created_at < ?", coachID, before).
+		Order("created_at, id").Find(&earnings).Error; err != nil {
+		r.log.Error("failed to list open coach earnings", "coach_id", coachID, "error", err)
+		return nil, fmt.Errorf("failed to list open coach earnings: %w", err)
+	}
+
+	return earnings, nil
+}
+
+// Statement loads the payouts of a closed period with their ledger lines; both
+// are empty while the period is open.
+func (r *coachRepository) Statement(coachID uint, period string) ([]models.CoachPayout, []models.CoachEarning, error) {
+	var payouts []models.CoachPayout
+	if err := r.db.Where("coach_id = ? AND period = ?", coachID, period).
+		Order("currency").Find(&payouts).Error; err != nil {
+		r.log.Error("failed to load coach payouts", "coach_id", coachID, "period", period, "error", err)
+		return nil, nil, fmt.Errorf("failed to load coach payouts: %w", err)
+	}
+	if len(payouts) == 0 {
+		return payouts, nil, nil
+	}
+
+	ids := make([]uint, 0, len(payouts))
+	for _, payout := range payouts {
+		ids = append(ids, payout.ID)
+	}
+
+	var earnings []models.CoachEarning
+	if err := r.db.Where("payout_id IN ?", ids).Order("created_at, id").Find(&earnings).Error; err != nil {
+		r.log.Error("failed to load payout earnings", "coach_id", coachID, "period", period, "error", err)
+		return nil, nil, fmt.Errorf("failed to load payout earnings: %w", err)
+	}
+
+	return payouts, earnings, nil
+}
+
+// ClosePeriod turns the open ledger lines booked before the given time into
+// the period's payouts. Currencies where refunds outweigh sales stay open and
+// are carried over to the next period.
+func (r *coachRepository) ClosePeriod(coachID uint, period string, before time.Time) ([]models.CoachPayout, error) {
+	var payouts []models.CoachPayout
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var closed int64
+		if err := tx.Model(&models.CoachPayout{}).
+			Where("coach_id = ? AND period = ?", coachID, period).
+			Count(&closed).Error; err != nil {
+			return err
+		}
+		if closed > 0 {
+			return ErrPeriodClosed
+		}
+
+		var open []models.CoachEarning
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("coach_id = ? AND payout_id IS NULL AND created_at < ?", coachID, before).
+			Order("created_at, id").Find(&open).Error; err != nil {
+			return err
+		}
+
+		for _, payout := range models.NewPayouts(coachID, period, open) {
+			if payout.Amount <= 0 {
+				continue
+			}
+			if err := tx.Create(&payout).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Model(&models.CoachEarning{}).
+				Where("coach_id = ? AND payout_id IS NULL AND created_at < ? AND currency = ?", coachID, before, payout.Currency).
+				UpdateColumn("payout_id", payout.ID).Error; err != nil {
+				return err
+			}
+			payouts = append(payouts, payout)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrPeriodClosed) {
+			return nil, err
+		}
+		r.log.Error("failed to close coach payout period", "coach_id", coachID, "period", period, "error", err)
+		return nil, fmt.Errorf("failed to close coach payout period: %w", err)
+	}
+
+	return payouts, nil
+}
+
+func (r *coachRepository) MarkPaid(coachID, payoutID uint, at time.Time) (*models.CoachPayout, error) {
+	var payout models.CoachPayout
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND coach_id = ?", payoutID, coachID).
+			First(&payout).Error; err != nil {
+			return err
+		}
+		if payout.Status == models.PayoutPaid {
+			return ErrPayoutPaid
+		}
+
+		payout.Status = models.PayoutPaid
+		payout.PaidAt = &at
+		return tx.Model(&payout).Updates(map[string]any{"status": payout.Status, "paid_at": at}).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, ErrPayoutPaid) {
+			return nil, err
+		}
+		r.log.Error("failed to mark coach payout paid", "payout_id", payoutID, "error", err)
+		return nil, fmt.Errorf("failed to mark coach payout paid: %w", err)
+	}
+
+	return &payout, nil
+}
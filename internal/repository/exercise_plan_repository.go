@@ -15,12 +15,17 @@ type ExercisePlanRepo interface {
 	GetAllExercisePlan() ([]models.ExercisePlan, error)
 	UpdateExercisePlan(exercise *models.ExercisePlan) error
 	DeleteExercisePlan(id uint) error
+	CloneExercisePlan(id uint, categoryID *uint) (*models.ExercisePlan, error)
 
 	CreateExercisePlanItem(item *models.ExercisePlanItem) error
 	GetAllExercisePlanItem() ([]models.ExercisePlanItem, error)
 	GetByIDExercisePlanItem(id uint) (*models.ExercisePlanItem, error)
 	UpdateExercisePlanItem(exercise *models.ExercisePlanItem) error
 	DeleteExercisePlanItem(id uint) error
+
+	CreateProgressionRule(rule *models.ProgressionRule) error
+	GetProgressionRule(id uint) (*models.ProgressionRule, error)
+	DeleteProgressionRule(id uint) error
 }
 
 type exercisePlanRepo struct {
@@ -47,7 +52,7 @@ func (r *exercisePlanRepo) CreateExercisePlan(exercise *models.ExercisePlan) err
 func (r *exercisePlanRepo) GetByIDExercisePlan(id uint) (*models.ExercisePlan, error) {
 	var exercise models.ExercisePlan
 
-	if err := r.db.Preload("Exercises").Preload("Categories").First(&exercise, id).Error; err != nil {
+	if err := r.db.Preload("Exercises").Preload("ProgressionRules").Preload("Categories").First(&exercise, id).Error; err != nil {
 		r.log.Error("error in GetByID function exercise_plan_repository.go")
 		return nil, err
 	}
@@ -93,6 +98,32 @@ func (r *exercisePlanRepo) DeleteExercisePlan(id uint) error {
 	return nil
 }
 
+func (r *exercisePlanRepo) CloneExercisePlan(id uint, categoryID *uint) (*models.ExercisePlan, error) {
+	var clone *models.ExercisePlan
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var source models.ExercisePlan
+		if err := tx.Preload("Exercises", orderByID).Preload("ProgressionRules", orderByID).First(&source, id).Error; err != nil {
+			return err
+		}
+
+		target := source.CategoriesID
+		if categoryID != nil {
+			target = *categoryID
+		}
+
+		var err error
+		clone, err = cloneExercisePlan(tx, &source, target)
+		return err
+	})
+	if err != nil {
+		r.log.Error("error in Clone function exercise_plan_repository.go", "id", id, "err", err)
+		return nil, err
+	}
+
+	return clone, nil
+}
+
 func (r *exercisePlanRepo) CreateExercisePlanItem(item *models.ExercisePlanItem) error {
 	if item == nil {
 		r.log.Error("error in Create function exercise_plan_item_repository.go")
@@ -132,10 +163,46 @@ func (r *exercisePlanRepo) UpdateExercisePlanItem(exercise *models.ExercisePlanI
 }
 
 func (r *exercisePlanRepo) DeleteExercisePlanItem(id uint) error {
-	if err := r.db.Delete(&models.ExercisePlanItem{}, id).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("exercise_plan_item_id = ?", id).Delete(&models.ProgressionRule{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.ExercisePlanItem{}, id).Error
+	})
+	if err != nil {
 		r.log.Error("error in Delete function exercise_plan_item_repository.go")
 		return errors.New("error delete in db")
 	}
 
 	return nil
 }
+
+func (r *exercisePlanRepo) CreateProgressionRule(rule *models.ProgressionRule) error {
+	if rule == nil {
+		r.log.Error("error in CreateProgressionRule function exercise_plan_repository.go")
+		return errors.New("error create in db")
+	}
+
+	return r.db.Create(rule).Error
+}
+
+func (r *exercisePlanRepo) GetProgressionRule(id uint) (*models.ProgressionRule, error) {
+	var rule models.ProgressionRule
+
+	if err := r.db.First(&rule, id).Error; err != nil {
+		r.log.Error("error in GetProgressionRule function exercise_plan_repository.go")
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *exercisePlanRepo) DeleteProgressionRule(id uint) error {
+	if err := r.db.Delete(&models.ProgressionRule{}, id).Error; err != nil {
+		r.log.Error("error in DeleteProgressionRule function exercise_plan_repository.go")
+		return errors.New("error delete in db")
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"healthy_body/internal/models"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type GiftRepository interface {
+	GetByID(id uint) (*models.Gift, error)
+	ListSent(userID uint) ([]models.Gift, error)
+	ListReceived(userID uint, email string) ([]models.Gift, error)
+}
+
+type giftRepository struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewGiftRepository(db *gorm.DB, log *slog.Logger) GiftRepository {
+	return &giftRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *giftRepository) GetByID(id uint) (*models.Gift, error) {
+	var gift models.Gift
+
+	if err := r.db.First(&gift, id).Error; err != nil {
+		r.log.Error("failed to get gift", "id", id, "err", err)
+		return nil, err
+	}
+
+	return &gift, nil
+}
+
+func (r *giftRepository) ListSent(userID uint) ([]models.Gift, error) {
+	var gifts []models.Gift
+
+	if err := r.db.Where("sender_id = ?", userID).Order("id DESC").Find(&gifts).Error; err != nil {
+		r.log.Error("failed to list sent gifts", "user_id", userID, "err", err)
+		return nil, err
+	}
+
+	return gifts, nil
+}
+
+func (r *giftRepository) ListReceived(userID uint, email string) ([]models.Gift, error) {
+	var gifts []models.Gift
+
+	query := r.db.Where("recipient_id = ?", userID)
+	if email != "" {
+		query = query.Or("recipient_id IS NULL AND recipient_email = ?", email)
+	}
+
+	if err := query.Order("id DESC").Find(&gifts).Error; err != nil {
+		r.log.Error("failed to list received gifts", "user_id", userID, "err", err)
+		return nil, err
+	}
+
+	return gifts, nil
+}
@@ -14,6 +14,7 @@ type MealPlanRepository interface {
 	Update(mealPlan *models.MealPlan) error
 	GetMealPlanByID(id uint) (*models.MealPlan, error)
 	Delete(id uint) error
+	Clone(id uint, categoryID *uint) (*models.MealPlan, error)
 }
 
 type gormMealPlanRepository struct {
@@ -44,7 +45,7 @@ func (r *gormMealPlanRepository) Create(mealPlan *models.MealPlan) error {
 func (r *gormMealPlanRepository) List() ([]models.MealPlan, error) {
 	var mealPlans []models.MealPlan
 
-	if err := r.db.Preload("Meals").Find(&mealPlans).Error; err != nil {
+	if err := r.db.Find(&mealPlans).Error; err != nil {
 		r.logger.Error("failed to fetch meal plans")
 		return nil, err
 	}
@@ -87,3 +88,30 @@ func (r *gormMealPlanRepository) Delete(id uint) error {
 	r.logger.Info("meal plan delete successfully")
 	return nil
 }
+
+func (r *gormMealPlanRepository) Clone(id uint, categoryID *uint) (*models.MealPlan, error) {
+	var clone *models.MealPlan
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var source models.MealPlan
+		if err := tx.Preload("Meals", orderByID).First(&source, id).Error; err != nil {
+			return err
+		}
+
+		target := source.CategoriesID
+		if categoryID != nil {
+			target = categoryID
+		}
+
+		var err error
+		clone, err = cloneMealPlan(tx, &source, target)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to clone meal plan", "id", id, "err", err)
+		return nil, err
+	}
+
+	r.logger.Info("meal plan cloned successfully", "id", id, "clone_id", clone.ID)
+	return clone, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"healthy_body/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// legacyRubleColumns held whole rubles and are replaced by a column in
+// kopecks.
+var legacyRubleColumns = []struct {
+	model     any
+	oldColumn string
+	newColumn string
+}{
+	{&models.User{}, "balance", "balance_minor"},
+	{&models.Categories{}, "price", "price_minor"},
+	{&models.Subscription{}, "price", "price_minor"},
+}
+
+type rubleAmounts struct {
+	model   any
+	columns []string
+	where   string
+}
+
+// legacyRubleTables held whole rubles in place. They are still in rubles while
+// the marker model has no currency column; rubles are set as the currency of
+// the rows matched by setCurrency, the others get the column default.
+var legacyRubleTables = []struct {
+	marker      any
+	amounts     []rubleAmounts
+	setCurrency string
+}{
+	{&models.UserPlan{}, []rubleAmounts{{&models.UserPlan{}, []string{"price_paid", "discount"}, ""}}, ""},
+	{&models.UserSubscription{}, []rubleAmounts{{&models.UserSubscription{}, []string{"price_paid", "discount"}, ""}}, ""},
+	{&models.Gift{}, []rubleAmounts{{&models.Gift{}, []string{"amount"}, ""}}, ""},
+	{&models.Order{}, []rubleAmounts{
+		{&models.Order{}, []string{"list_total", "discount", "total"}, ""},
+		{&models.OrderItem{}, []string{"list_price", "discount", "final_price"}, ""},
+	}, ""},
+	{&models.PromoRedemption{}, []rubleAmounts{{&models.PromoRedemption{}, []string{"discount"}, ""}}, "1 = 1"},
+	{&models.PromoCode{}, []rubleAmounts{{&models.PromoCode{}, []string{"value"}, "discount_type = 'fixed'"}}, "discount_type = 'fixed'"},
+}
+
+// PrepareMinorUnits converts amounts stored in whole rubles to kopecks. It
+// runs before AutoMigrate and adds the new columns itself in the same
+// transaction, so a table is never converted twice.
+func PrepareMinorUnits(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+
+		for _, legacy := range legacyRubleColumns {
+			if !migrator.HasTable(legacy.model) || !migrator.HasColumn(legacy.model, legacy.oldColumn) ||
+				migrator.HasColumn(legacy.model, legacy.newColumn) {
+				continue
+			}
+
+			if err := migrator.AddColumn(legacy.model, legacy.newColumn); err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Model(legacy.model).Where("1 = 1").
+				UpdateColumn(legacy.newColumn, gorm.Expr(legacy.oldColumn+" * 100")).Error; err != nil {
+				return err
+			}
+			if err := migrator.DropColumn(legacy.model, legacy.oldColumn); err != nil {
+				return err
+			}
+		}
+
+		for _, legacy := range legacyRubleTables {
+			if !migrator.HasTable(legacy.marker) || migrator.HasColumn(legacy.marker, "currency") {
+				continue
+			}
+
+			for _, amounts := range legacy.amounts {
+				if !migrator.HasTable(amounts.model) {
+					continue
+				}
+
+				updates := make(map[string]any, len(amounts.columns))
+				for _, column := range amounts.columns {
+					updates[column] = gorm.Expr(column + " * 100")
+				}
+				query := tx.Unscoped().Model(amounts.model).Where("1 = 1")
+				if amounts.where != "" {
+					query = query.Where(amounts.where)
+				}
+				if err := query.UpdateColumns(updates).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := migrator.AddColumn(legacy.marker, "currency"); err != nil {
+				return err
+			}
+			if legacy.setCurrency != "" {
+				if err := tx.Unscoped().Model(legacy.marker).Where(legacy.setCurrency).
+					UpdateColumn("currency", models.CurrencyRUB).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
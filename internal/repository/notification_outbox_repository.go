@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"errors"
+	"healthy_body/internal/models"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrOutboxAlreadySent = errors.New("notification already sent")
+
+type NotificationOutboxRepository interface {
+	Enqueue(event models.NotificationEvent) error
+	ClaimDue(limit int, lease time.Duration) ([]models.NotificationOutbox, error)
+	MarkSent(id uint) error
+	MarkFailed(id uint, attempts int, nextAttempt time.Time, lastErr string, delivered []string, dead bool) error
+	List(status string) ([]models.NotificationOutbox, error)
+	Retry(id uint) (*models.NotificationOutbox, error)
+}
+
+type notificationOutboxRepository struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewNotificationOutboxRepository(db *gorm.DB, log *slog.Logger) NotificationOutboxRepository {
+	return &notificationOutboxRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *notificationOutboxRepository) Enqueue(event models.NotificationEvent) error {
+	message, err := models.NewOutboxMessage(event)
+	if err != nil {
+		return err
+	}
+
+	if err := r.db.Create(message).Error; err != nil {
+		r.log.Error("failed to enqueue notification", "event_type", event.EventType, "err", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *notificationOutboxRepository) ClaimDue(limit int, lease time.Duration) ([]models.NotificationOutbox, error) {
+	var messages []models.NotificationOutbox
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.OutboxPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&messages).Error; err != nil {
+			return err
+		}
+
+		if len(messages) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(messages))
+		for _, message := range messages {
+			ids = append(ids, message.ID)
+		}
+
+		return tx.Model(&models.NotificationOutbox{}).
+			Where("id IN ?", ids).
+			UpdateColumn("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		r.log.Error("failed to claim outbox messages", "err", err)
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (r *notificationOutboxRepository) MarkSent(id uint) error {
+	now := time.Now()
+
+	if err := r.db.Model(&models.NotificationOutbox{}).Where("id = ?", id).UpdateColumns(map[string]any{
+		"status":     models.OutboxSent,
+		"sent_at":    &now,
+		"last_error": "",
+	}).Error; err != nil {
+		r.log.Error("failed to mark outbox message as sent", "id", id, "err", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *notificationOutboxRepository) MarkFailed(id uint, attempts int, nextAttempt time.Time, lastErr string, delivered []string, dead bool) error {
+	status := models.OutboxPending
+	if dead {
+		status = models.OutboxDead
+	}
+
+	update := models.NotificationOutbox{
+		Status:        status,
+		Attempts:      attempts,
+		NextAttemptAt: nextAttempt,
+		LastError:     lastErr,
+		Delivered:     delivered,
+	}
+
+	if err := r.db.Model(&models.NotificationOutbox{}).Where("id = ?", id).
+		Select("status", "attempts", "next_attempt_at", "last_error", "delivered").
+		UpdateColumns(&update).Error; err != nil {
+		r.log.Error("failed to mark outbox message as failed", "id", id, "err", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *notificationOutboxRepository) List(status string) ([]models.NotificationOutbox, error) {
+	var messages []models.NotificationOutbox
+
+	query := r.db.Order("id DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Find(&messages).Error; err != nil {
+		r.log.Error("failed to list outbox messages", "err", err)
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (r *notificationOutboxRepository) Retry(id uint) (*models.NotificationOutbox, error) {
+	var message models.NotificationOutbox
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&message, id).Error; err != nil {
+			return err
+		}
+
+		if message.Status == models.OutboxSent {
+			return ErrOutboxAlreadySent
+		}
+
+		message.Status = models.OutboxPending
+		message.Attempts = 0
+		message.NextAttemptAt = time.Now()
+
+		return tx.Model(&message).UpdateColumns(map[string]any{
+			"status":          message.Status,
+			"attempts":        message.Attempts,
+			"next_attempt_at": message.NextAttemptAt,
+		}).Error
+	})
+	if err != nil {
+		r.log.Error("failed to retry outbox message", "id", id, "err", err)
+		return nil, err
+	}
+
+	return &message, nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"errors"
+	"healthy_body/internal/models"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository interface {
+	ListPreferences(userID uint) ([]models.NotificationPreference, error)
+	SavePreference(pref *models.NotificationPreference) error
+	CreateInbox(notification *models.InboxNotification) error
+	ListInbox(userID uint, limit, offset int) ([]models.InboxNotification, int64, int64, error)
+	MarkRead(userID, id uint) error
+	MarkAllRead(userID uint) (int64, error)
+}
+
+type notificationRepository struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewNotificationRepository(db *gorm.DB, log *slog.Logger) NotificationRepository {
+	return &notificationRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *notificationRepository) ListPreferences(userID uint) ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+
+	if err := r.db.Where("user_id = ?", userID).Order("channel").Find(&prefs).Error; err != nil {
+		r.log.Error("failed to list notification preferences", "user_id", userID, "err", err)
+		return nil, err
+	}
+
+	return prefs, nil
+}
+
+func (r *notificationRepository) SavePreference(pref *models.NotificationPreference) error {
+	if pref.ID != 0 {
+		if err := r.db.Save(pref).Error; err != nil {
+			r.log.Error("failed to save notification preference", "user_id", pref.UserID, "channel", pref.Channel, "err", err)
+			return err
+		}
+		return nil
+	}
+
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "target", "updated_at"}),
+	}).Create(pref).Error; err != nil {
+		r.log.Error("failed to save notification preference", "user_id", pref.UserID, "channel", pref.Channel, "err", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) CreateInbox(notification *models.InboxNotification) error {
+	if err := r.db.Create(notification).Error; err != nil {
+		r.log.Error("failed to create inbox notification", "user_id", notification.UserID, "err", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) ListInbox(userID uint, limit, offset int) ([]models.InboxNotification, int64, int64, error) {
+	var items []models.InboxNotification
+	var total, unread int64
+
+	query := r.db.Model(&models.InboxNotification{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		r.log.Error("failed to count inbox notifications", "user_id", userID, "err", err)
+		return nil, 0, 0, err
+	}
+
+	if err := r.db.Model(&models.InboxNotification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&unread).Error; err != nil {
+		r.log.Error("failed to count unread notifications", "user_id", userID, "err", err)
+		return nil, 0, 0, err
+	}
+
+	if err := r.db.Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&items).Error; err != nil {
+		r.log.Error("failed to list inbox notifications", "user_id", userID, "err", err)
+		return nil, 0, 0, err
+	}
+
+	return items, total, unread, nil
+}
+
+func (r *notificationRepository) MarkRead(userID, id uint) error {
+	var notification models.InboxNotification
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.log.Error("failed to find inbox notification", "id", id, "err", err)
+		}
+		return err
+	}
+
+	if notification.ReadAt != nil {
+		return nil
+	}
+
+	if err := r.db.Model(&notification).UpdateColumn("read_at", time.Now()).Error; err != nil {
+		r.log.Error("failed to mark notification as read", "id", id, "err", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) MarkAllRead(userID uint) (int64, error) {
+	result := r.db.Model(&models.InboxNotification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		UpdateColumn("read_at", time.Now())
+	if result.Error != nil {
+		r.log.Error("failed to mark all notifications as read", "user_id", userID, "err", result.Error)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"healthy_body/internal/models"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type OrderRepository interface {
+	ListByUser(userID uint) ([]models.Order, error)
+	GetByID(userID, id uint) (*models.Order, error)
+}
+
+type orderRepository struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewOrderRepository(db *gorm.DB, log *slog.Logger) OrderRepository {
+	return &orderRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *orderRepository) ListByUser(userID uint) ([]models.Order, error) {
+	var orders []models.Order
+
+	if err := r.db.Preload("Items", orderByID).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Find(&orders).Error; err != nil {
+		r.log.Error("failed to list orders", "user_id", userID, "err", err)
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *orderRepository) GetByID(userID, id uint) (*models.Order, error) {
+	var order models.Order
+
+	if err := r.db.Preload("Items", orderByID).
+		Where("user_id = ?", userID).
+		First(&order, id).Error; err != nil {
+		r.log.Error("failed to get order", "user_id", userID, "id", id, "err", err)
+		return nil, err
+	}
+
+	return &order, nil
+}
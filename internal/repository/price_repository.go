@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"healthy_body/internal/models"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type PriceRepository interface {
+	List(targetType string, targetID uint) ([]models.CatalogPrice, error)
+	Replace(targetType string, targetID uint, prices []models.CatalogPrice) error
+}
+
+type priceRepository struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewPriceRepository(db *gorm.DB, log *slog.Logger) PriceRepository {
+	return &priceRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *priceRepository) List(targetType string, targetID uint) ([]models.CatalogPrice, error) {
+	var prices []models.CatalogPrice
+
+	if err := r.db.Where("target_type = ? AND target_id = ?", targetType, targetID).
+		Order("currency").Find(&prices).Error; err != nil {
+		r.log.Error("failed to list prices", "target_type", targetType, "target_id", targetID, "err", err)
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+func (r *priceRepository) Replace(targetType string, targetID uint, prices []models.CatalogPrice) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("target_type = ? AND target_id = ?", targetType, targetID).
+			Delete(&models.CatalogPrice{}).Error; err != nil {
+			return err
+		}
+
+		if len(prices) == 0 {
+			return nil
+		}
+
+		return tx.Create(&prices).Error
+	})
+	if err != nil {
+		r.log.Error("failed to replace prices", "target_type", targetType, "target_id", targetID, "err", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"healthy_body/internal/models"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type PromoCodeRepository interface {
+	Create(promo *models.PromoCode) error
+	List() ([]models.PromoCode, error)
+	Delete(id uint) error
+}
+
+type promoCodeRepository struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewPromoCodeRepository(db *gorm.DB, log *slog.Logger) PromoCodeRepository {
+	return &promoCodeRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *promoCodeRepository) Create(promo *models.PromoCode) error {
+	if err := r.db.Create(promo).Error; err != nil {
+		r.log.Error("failed to create promo code", "code", promo.Code, "err", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *promoCodeRepository) List() ([]models.PromoCode, error) {
+	var promos []models.PromoCode
+
+	if err := r.db.Preload("Restrictions").Order("id DESC").Find(&promos).Error; err != nil {
+		r.log.Error("failed to list promo codes", "err", err)
+		return nil, err
+	}
+
+	return promos, nil
+}
+
+func (r *promoCodeRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.PromoCode{}, id)
+	if result.Error != nil {
+		r.log.Error("failed to delete promo code", "id", id, "err", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
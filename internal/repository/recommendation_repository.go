@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"fmt"
+	"healthy_body/internal/models"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecommendationRepository interface {
+	Candidates() ([]models.Categories, error)
+	Purchased(userID uint) ([]models.Categories, error)
+	Popularity(since time.Time) (map[uint]int64, error)
+}
+
+type recommendationRepository struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewRecommendationRepository(db *gorm.DB, log *slog.Logger) RecommendationRepository {
+	return &recommendationRepository{db: db, log: log}
+}
+
+func (r *recommendationRepository) Candidates() ([]models.Categories, error) {
+	var categories []models.Categories
+
+	if err := r.db.Where("status = ?", models.StatusPublished).Order("id").Find(&categories).Error; err != nil {
+		r.log.Error("failed to list recommendation candidates", "error", err)
+		return nil, fmt.Errorf("failed to list recommendation candidates: %w", err)
+	}
+
+	return categories, nil
+}
+
+// Purchased lists the categories the user has bought, oldest purchase first.
+func (r *recommendationRepository) Purchased(userID uint) ([]models.Categories, error) {
+	var categories []models.Categories
+
+	if err := r.db.
+		Joins("JOIN (SELECT categories_id, MIN(id) AS first_plan FROM user_plans WHERE user_id = ? AND deleted_at IS NULL GROUP BY categories_id) plans ON plans.categories_id = categories.id", userID).
+		Order("plans.first_plan").
+		Find(&categories).Error; err != nil {
+		r.log.Error("failed to list purchased categories", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to list purchased categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// Popularity counts purchases per category since the given time.
+func (r *recommendationRepository) Popularity(since time.Time) (map[uint]int64, error) {
+	var rows []struct {
+		CategoriesID uint
+		Purchases    int64
+	}
+
+	if err := r.db.Model(&models.UserPlan{}).
+		Select("categories_id, COUNT(*) AS purchases").
+		Where("created_at >= ?", since).
+		Group("categories_id").
+		Scan(&rows).Error; err != nil {
+		r.log.Error("failed to count category purchases", "error", err)
+		return nil, fmt.Errorf("failed to count category purchases: %w", err)
+	}
+
+	popularity := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		popularity[row.CategoriesID] = row.Purchases
+	}
+
+	return popularity, nil
+}
@@ -1,11 +1,15 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"healthy_body/internal/models"
 	"log/slog"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ReviewsRepository interface {
@@ -15,7 +19,25 @@ type ReviewsRepository interface {
 	Delete(id uint) error
 
 	GetByUserID(userID uint) ([]models.Reviews, error)
-	GetByCategoryID(categoryID uint) ([]models.Reviews, error)
+	GetByTarget(targetType string, targetID uint, sort string) ([]models.Reviews, error)
+	ExistsForUser(userID uint, targetType string, targetID uint) (bool, error)
+	TargetCategoryID(targetType string, targetID uint) (uint, error)
+	Rating(targetType string, targetID uint) (*models.RatingStats, error)
+	HasPurchase(userID, categoryID uint) (bool, error)
+	RebuildRatings() (int64, error)
+	HasSameContent(content string, excludeID uint) (bool, error)
+	ListByStatus(status string) ([]models.Reviews, error)
+	SetModeration(id uint, status, reason string) (*models.Reviews, error)
+
+	CategoryOwnerID(categoryID uint) (uint, error)
+	UserRole(userID uint) (string, error)
+	CreateReply(reply *models.ReviewReply) error
+	GetReply(id uint) (*models.ReviewReply, error)
+	DeleteReply(id uint) error
+	ListReplies(reviewID uint) ([]models.ReviewReply, error)
+	Vote(reviewID, userID uint, helpful *bool) (*models.Reviews, error)
+	HasReported(reviewID, userID uint) (bool, error)
+	Report(report *models.ReviewReport, threshold int) (*models.Reviews, error)
 }
 
 type reviewsRepository struct {
@@ -29,7 +51,16 @@ func NewReviewsRepository(reviews *gorm.DB, log *slog.Logger) ReviewsRepository
 
 func (r *reviewsRepository) CreateReviews(req *models.Reviews) error {
 
-	if err := r.reviews.Create(req).Error; err != nil {
+	if err := r.reviews.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(req).Error; err != nil {
+			// a concurrent review of the same target trips the unique index
+			if translator, ok := tx.Dialector.(gorm.ErrorTranslator); ok {
+				return translator.Translate(err)
+			}
+			return err
+		}
+		return applyRating(tx, req, ratedStars(req), 1)
+	}); err != nil {
 		r.log.Error("Ошибка создания отзыва",
 			"error", err.Error())
 		return fmt.Errorf("ошибка создания отзыва: %w", err)
@@ -57,7 +88,7 @@ func (r *reviewsRepository) GetAllReviews() ([]models.Reviews, error) {
 func (r *reviewsRepository) GetReviewsByID(id uint) (*models.Reviews, error) {
 	var reviews models.Reviews
 
-	if err := r.reviews.First(&reviews, id).Error; err != nil {
+	if err := r.reviews.Preload("Attachments").First(&reviews, id).Error; err != nil {
 		r.log.Error("Ошибка при выводе отзыва",
 			"error", err.Error())
 		return nil, fmt.Errorf("ошибка при выводе отзыва %w", err)
@@ -68,7 +99,22 @@ func (r *reviewsRepository) GetReviewsByID(id uint) (*models.Reviews, error) {
 }
 
 func (r *reviewsRepository) UpdateReviews(req *models.Reviews) error {
-	if err := r.reviews.Model(&req).Updates(req).Error; err != nil {
+	if err := r.reviews.Transaction(func(tx *gorm.DB) error {
+		var stored models.Reviews
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stored, req.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&req).Updates(map[string]any{
+			"rating":       req.Rating,
+			"content":      req.Content,
+			"status":       req.Status,
+			"filter_flags": req.FilterFlags,
+			"moderated_at": req.ModeratedAt,
+		}).Error; err != nil {
+			return err
+		}
+		return moveRating(tx, &stored, req)
+	}); err != nil {
 		r.log.Error("Ошибка при обновлении отзыва",
 			"error", err.Error())
 		return fmt.Errorf("ошибка при обновлении отзыва %w", err)
@@ -80,7 +126,16 @@ func (r *reviewsRepository) UpdateReviews(req *models.Reviews) error {
 
 func (r *reviewsRepository) Delete(id uint) error {
 
-	if err := r.reviews.Delete(id).Error; err != nil {
+	if err := r.reviews.Transaction(func(tx *gorm.DB) error {
+		var stored models.Reviews
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stored, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&stored).Error; err != nil {
+			return err
+		}
+		return applyRating(tx, &stored, ratedStars(&stored), -1)
+	}); err != nil {
 		r.log.Error("Ошибка при удалении отзыва",
 			"error", err)
 		return fmt.Errorf("ошибка при удалении отзыва %w", err)
@@ -93,7 +148,8 @@ func (r *reviewsRepository) Delete(id uint) error {
 
 func (r *reviewsRepository) GetByUserID(userID uint) ([]models.Reviews, error) {
 	var reviews []models.Reviews
-	if err := r.reviews.Where("user_id = ?", userID).Find(&reviews).Error; err != nil {
+	if err := r.reviews.Preload("Attachments").
+		Where("user_id = ? AND status = ?", userID, models.ReviewApproved).Find(&reviews).Error; err != nil {
 		r.log.Error("Ошибка при поиске отзывов",
 			"error", err)
 		return nil, fmt.Errorf("ошибка при поиске отзывов %w", err)
@@ -103,10 +159,16 @@ func (r *reviewsRepository) GetByUserID(userID uint) ([]models.Reviews, error) {
 	return reviews, nil
 }
 
-func (r *reviewsRepository) GetByCategoryID(categoryID uint) ([]models.Reviews, error) {
+func (r *reviewsRepository) GetByTarget(targetType string, targetID uint, sort string) ([]models.Reviews, error) {
 	var reviews []models.Reviews
 
-	if err := r.reviews.Where("category_id = ?", categoryID).Find(&reviews).Error; err != nil {
+	query := r.reviews.Preload("Attachments").
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, models.ReviewApproved)
+	if sort == models.SortHelpful {
+		query = query.Order("helpful_count - not_helpful_count DESC")
+	}
+
+	if err := query.Order("created_at DESC").Find(&reviews).Error; err != nil {
 		r.log.Error("Ошибка при поиске отзывов",
 			"error", err)
 		return nil, fmt.Errorf("ошибка при поиске отзывов %w", err)
@@ -115,3 +177,551 @@ func (r *reviewsRepository) GetByCategoryID(categoryID uint) ([]models.Reviews,
 	r.log.Info("Отзывы получены")
 	return reviews, nil
 }
+
+func (r *reviewsRepository) ExistsForUser(userID uint, targetType string, targetID uint) (bool, error) {
+	var count int64
+
+	if err := r.reviews.Model(&models.Reviews{}).
+		Where("user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).
+		Count(&count).Error; err != nil {
+		r.log.Error("Ошибка при проверке отзыва",
+			"error", err)
+		return false, fmt.Errorf("ошибка при проверке отзыва %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// HasPurchase reports whether the user bought the category or holds a running
+// subscription to it.
+func (r *reviewsRepository) HasPurchase(userID, categoryID uint) (bool, error) {
+	var plans int64
+	if err := r.reviews.Model(&models.UserPlan{}).
+		Where("user_id = ? AND categories_id = ?", userID, categoryID).
+		Count(&plans).Error; err != nil {
+		r.log.Error("Ошибка при проверке покупки",
+			"error", err)
+		return false, fmt.Errorf("ошибка при проверке покупки %w", err)
+	}
+	if plans > 0 {
+		return true, nil
+	}
+
+	var subs int64
+	now := time.Now()
+	if err := r.reviews.Model(&models.UserSubscription{}).
+		Where("user_id = ? AND categories_id = ? AND status = ? AND start_date <= ? AND end_date > ?",
+			userID, categoryID, models.SubStatusActive, now, now).
+		Count(&subs).Error; err != nil {
+		r.log.Error("Ошибка при проверке подписки",
+			"error", err)
+		return false, fmt.Errorf("ошибка при проверке подписки %w", err)
+	}
+
+	return subs > 0, nil
+}
+
+// RebuildRatings recomputes every category and plan rating from the reviews
+// table.
+func (r *reviewsRepository) RebuildRatings() (int64, error) {
+	var rows int64
+
+	err := r.reviews.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.CategoryRating{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("1 = 1").Delete(&models.TargetRating{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(`INSERT INTO category_ratings
+			(categories_id, count, sum, average, stars1, stars2, stars3, stars4, stars5, updated_at)
+			SELECT categories_id, COUNT(*), SUM(rating), AVG(rating),
+				COUNT(*) FILTER (WHERE rating = 1), COUNT(*) FILTER (WHERE rating = 2),
+				COUNT(*) FILTER (WHERE rating = 3), COUNT(*) FILTER (WHERE rating = 4),
+				COUNT(*) FILTER (WHERE rating = 5), NOW()
+			FROM reviews
+			WHERE deleted_at IS NULL AND status = 'approved' AND rating BETWEEN 1 AND 5
+				AND target_type = 'category'
+			GROUP BY categories_id`)
+		if result.Error != nil {
+			return result.Error
+		}
+		rows = result.RowsAffected
+
+		result = tx.Exec(`INSERT INTO target_ratings
+			(target_type, target_id, count, sum, average, stars1, stars2, stars3, stars4, stars5, updated_at)
+			SELECT target_type, target_id, COUNT(*), SUM(rating), AVG(rating),
+				COUNT(*) FILTER (WHERE rating = 1), COUNT(*) FILTER (WHERE rating = 2),
+				COUNT(*) FILTER (WHERE rating = 3), COUNT(*) FILTER (WHERE rating = 4),
+				COUNT(*) FILTER (WHERE rating = 5), NOW()
+			FROM reviews
+			WHERE deleted_at IS NULL AND status = 'approved' AND rating BETWEEN 1 AND 5
+				AND target_type <> 'category'
+			GROUP BY target_type, target_id`)
+		rows += result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		r.log.Error("Ошибка при пересчете рейтингов",
+			"error", err)
+		return 0, fmt.Errorf("ошибка при пересчете рейтингов %w", err)
+	}
+
+	r.log.Info("Рейтинги пересчитаны", "targets", rows)
+	return rows, nil
+}
+
+func (r *reviewsRepository) HasSameContent(content string, excludeID uint) (bool, error) {
+	var count int64
+
+	if err := r.reviews.Model(&models.Reviews{}).
+		Where("LOWER(TRIM(content)) = LOWER(TRIM(?)) AND id <> ?", content, excludeID).
+		Count(&count).Error; err != nil {
+		r.log.Error("Ошибка при поиске дубликатов отзыва",
+			"error", err)
+		return false, fmt.Errorf("ошибка при поиске дубликатов отзыва %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *reviewsRepository) ListByStatus(status string) ([]models.Reviews, error) {
+	var reviews []models.Reviews
+
+	if err := r.reviews.Preload("Attachments").Where("status = ?", status).Order("id").Find(&reviews).Error; err != nil {
+		r.log.Error("Ошибка при получении очереди модерации",
+			"error", err)
+		return nil, fmt.Errorf("ошибка при получении очереди модерации %w", err)
+	}
+
+	return reviews, nil
+}
+
+func (r *reviewsRepository) SetModeration(id uint, status, reason string) (*models.Reviews, error) {
+	var review models.Reviews
+
+	err := r.reviews.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, id).Error; err != nil {
+			return err
+		}
+
+		stored := review
+		now := time.Now()
+		review.Status = status
+		review.ModerationReason = reason
+		review.ModeratedAt = &now
+		review.ReportCount = 0
+		if err := tx.Model(&review).Updates(map[string]any{
+			"status":            status,
+			"moderation_reason": reason,
+			"moderated_at":      now,
+			"report_count":      0,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ReviewReport{}).
+			Where("review_id = ? AND resolved_at IS NULL", id).
+			Update("resolved_at", now).Error; err != nil {
+			return err
+		}
+
+		return moveRating(tx, &stored, &review)
+	})
+	if err != nil {
+		r.log.Error("Ошибка при модерации отзыва",
+			"id", id,
+			"error", err)
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// ratedStars is the rating a review contributes to its category statistics;
+// only approved reviews count.
+func ratedStars(review *models.Reviews) int {
+	if review.Status != models.ReviewApproved {
+		return 0
+	}
+
+	return review.Rating
+}
+
+func moveRating(tx *gorm.DB, before, after *models.Reviews) error {
+	if ratedStars(before) == ratedStars(after) {
+		return nil
+	}
+	if err := applyRating(tx, before, ratedStars(before), -1); err != nil {
+		return err
+	}
+
+	return applyRating(tx, after, ratedStars(after), 1)
+}
+
+// applyRating adds delta reviews with the given number of stars to the
+// statistics of the reviewed item. Postgres evaluates every SET expression
+// against the old row, so the average is derived from the same counters being
+// changed.
+func applyRating(tx *gorm.DB, review *models.Reviews, stars, delta int) error {
+	if stars < 1 || stars > 5 {
+		return nil
+	}
+
+	var rating *gorm.DB
+	if review.TargetType == models.ReviewTargetCategory {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.CategoryRating{CategoriesID: review.CategoriesID}).Error; err != nil {
+			return err
+		}
+		rating = tx.Model(&models.CategoryRating{}).Where("categories_id = ?", review.CategoriesID)
+	} else {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.TargetRating{TargetType: review.TargetType, TargetID: review.TargetID}).Error; err != nil {
+			return err
+		}
+		rating = tx.Model(&models.TargetRating{}).Where("target_type = ? AND target_id = ?", review.TargetType, review.TargetID)
+	}
+
+	column := fmt.Sprintf("stars%d", stars)
+	return rating.Updates(map[string]any{
+		"count":   gorm.Expr("count + ?", delta),
+		"sum":     gorm.Expr("sum + ?", delta*stars),
+		column:    gorm.Expr(column+" + ?", delta),
+		"average": gorm.Expr("CASE WHEN count + ? > 0 THEN (sum + ?)::numeric / (count + ?) ELSE 0 END", delta, delta*stars, delta),
+	}).Error
+}
+
+// CategoryOwnerID returns the owner of the category, or 0 when it has none.
+func (r *reviewsRepository) CategoryOwnerID(categoryID uint) (uint, error) {
+	var category models.Categories
+
+	if err := r.reviews.Select("id", "owner_id").First(&category, categoryID).Error; err != nil {
+		r.log.Error("Ошибка при поиске категории",
+			"category_id", categoryID,
+			"error", err)
+		return 0, fmt.Errorf("ошибка при поиске категории %w", err)
+	}
+	if category.OwnerID == nil {
+		return 0, nil
+	}
+
+	return *category.OwnerID, nil
+}
+
+func (r *reviewsRepository) UserRole(userID uint) (string, error) {
+	var user models.User
+
+	if err := r.reviews.Select("id", "role").First(&user, userID).Error; err != nil {
+		r.log.Error("Ошибка при поиске пользователя",
+			"user_id", userID,
+			"error", err)
+		return "", fmt.Errorf("ошибка при поиске пользователя %w", err)
+	}
+
+	return user.Role, nil
+}
+
+func (r *reviewsRepository) CreateReply(reply *models.ReviewReply) error {
+	if err := r.reviews.Create(reply).Error; err != nil {
+		r.log.Error("Ошибка при создании ответа на отзыв",
+			"review_id", reply.ReviewID,
+			"error", err)
+		return fmt.Errorf("ошибка при создании ответа на отзыв %w", err)
+	}
+
+	r.log.Info("Ответ на отзыв создан", "review_id", reply.ReviewID)
+	return nil
+}
+
+func (r *reviewsRepository) GetReply(id uint) (*models.ReviewReply, error) {
+	var reply models.ReviewReply
+
+	if err := r.reviews.First(&reply, id).Error; err != nil {
+		r.log.Error("Ошибка при выводе ответа на отзыв",
+			"id", id,
+			"error", err)
+		return nil, fmt.Errorf("ошибка при выводе ответа на отзыв %w", err)
+	}
+
+	return &reply, nil
+}
+
+// DeleteReply removes the reply together with the thread below it.
+func (r *reviewsRepository) DeleteReply(id uint) error {
+	if err := r.reviews.Exec(`UPDATE review_replies SET deleted_at = NOW()
+		WHERE deleted_at IS NULL AND id IN (
+			WITH RECURSIVE thread AS (
+				SELECT id FROM review_replies WHERE id = ?
+				UNION ALL
+				SELECT rr.id FROM review_replies rr JOIN thread ON rr.parent_id = thread.id
+			)
+			SELECT id FROM thread)`, id).Error; err != nil {
+		r.log.Error("Ошибка при удалении ответа на отзыв",
+			"id", id,
+			"error", err)
+		return fmt.Errorf("ошибка при удалении ответа на отзыв %w", err)
+	}
+
+	r.log.Info("Ответ на отзыв удален", "id", id)
+	return nil
+}
+
+func (r *reviewsRepository) ListReplies(reviewID uint) ([]models.ReviewReply, error) {
+	var replies []models.ReviewReply
+
+	if err := r.reviews.Where("review_id = ?", reviewID).Order("id").Find(&replies).Error; err != nil {
+		r.log.Error("Ошибка при получении ответов на отзыв",
+			"review_id", reviewID,
+			"error", err)
+		return nil, fmt.Errorf("ошибка при получении ответов на отзыв %w", err)
+	}
+
+	return replies, nil
+}
+
+// Vote records the user's helpful/not-helpful vote, replacing an earlier one;
+// a nil vote withdraws it. The review counters move in the same transaction.
+func (r *reviewsRepository) Vote(reviewID, userID uint, helpful *bool) (*models.Reviews, error) {
+	var review models.Reviews
+
+	err := r.reviews.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, reviewID).Error; err != nil {
+			return err
+		}
+
+		var vote models.ReviewVote
+		err := tx.Where("review_id = ? AND user_id = ?", reviewID, userID).First(&vote).Error
+		found := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		counters := map[string]int{}
+		switch {
+		case found && helpful == nil:
+			if err := tx.Delete(&vote).Error; err != nil {
+				return err
+			}
+			counters[voteColumn(vote.Helpful)]--
+		case found && vote.Helpful != *helpful:
+			if err := tx.Model(&vote).Update("helpful", *helpful).Error; err != nil {
+				return err
+			}
+			counters[voteColumn(vote.Helpful)]--
+			counters[voteColumn(*helpful)]++
+		case !found && helpful != nil:
+			if err := tx.Create(&models.ReviewVote{ReviewID: reviewID, UserID: userID, Helpful: *helpful}).Error; err != nil {
+				return err
+			}
+			counters[voteColumn(*helpful)]++
+		}
+		if len(counters) == 0 {
+			return nil
+		}
+
+		updates := map[string]any{}
+		for column, delta := range counters {
+			updates[column] = gorm.Expr(column+" + ?", delta)
+		}
+		if err := tx.Model(&review).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+
+		return tx.First(&review, reviewID).Error
+	})
+	if err != nil {
+		r.log.Error("Ошибка при голосовании за отзыв",
+			"review_id", reviewID,
+			"user_id", userID,
+			"error", err)
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func voteColumn(helpful bool) string {
+	if helpful {
+		return "helpful_count"
+	}
+
+	return "not_helpful_count"
+}
+
+func (r *reviewsRepository) HasReported(reviewID, userID uint) (bool, error) {
+	var count int64
+
+	if err := r.reviews.Model(&models.ReviewReport{}).
+		Where("review_id = ? AND user_id = ?", reviewID, userID).
+		Count(&count).Error; err != nil {
+		r.log.Error("Ошибка при проверке жалобы",
+			"error", err)
+		return false, fmt.Errorf("ошибка при проверке жалобы %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// Report stores an abuse report. Once a published review collects threshold
+// open reports it goes back to the moderation queue and leaves the rating.
+func (r *reviewsRepository) Report(report *models.ReviewReport, threshold int) (*models.Reviews, error) {
+	var review models.Reviews
+
+	err := r.reviews.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, report.ReviewID).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(report).Error; err != nil {
+			return err
+		}
+
+		stored := review
+		review.ReportCount++
+		updates := map[string]any{"report_count": review.ReportCount}
+		if review.Status == models.ReviewApproved && review.ReportCount >= threshold {
+			review.Status = models.ReviewPending
+			if !strings.Contains(","+review.FilterFlags+",", ",reported,") {
+				review.FilterFlags = strings.Trim(review.FilterFlags+",reported", ",")
+			}
+			updates["status"] = review.Status
+			updates["filter_flags"] = review.FilterFlags
+		}
+		if err := tx.Model(&review).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return moveRating(tx, &stored, &review)
+	})
+	if err != nil {
+		r.log.Error("Ошибка при сохранении жалобы на отзыв",
+			"review_id", report.ReviewID,
+			"error", err)
+		return nil, err
+	}
+
+	r.log.Info("Жалоба на отзыв сохранена",
+		"review_id", report.ReviewID,
+		"reports", review.ReportCount)
+	return &review, nil
+}
+
+// TargetCategoryID returns the category the reviewed item belongs to, or 0
+// for a plan outside any category.
+func (r *reviewsRepository) TargetCategoryID(targetType string, targetID uint) (uint, error) {
+	var (
+		categoryID uint
+		err        error
+	)
+
+	switch targetType {
+	case models.ReviewTargetCategory:
+		var category models.Categories
+		err = r.reviews.Select("id").First(&category, targetID).Error
+		categoryID = category.ID
+	case models.ReviewTargetExercisePlan:
+		var plan models.ExercisePlan
+		err = r.reviews.Select("id", "categories_id").First(&plan, targetID).Error
+		categoryID = plan.CategoriesID
+	case models.ReviewTargetMealPlan:
+		var plan models.MealPlan
+		err = r.reviews.Select("id", "categories_id").First(&plan, targetID).Error
+		if plan.CategoriesID != nil {
+			categoryID = *plan.CategoriesID
+		}
+	default:
+		return 0, fmt.Errorf("неизвестный тип объекта отзыва %q", targetType)
+	}
+	if err != nil {
+		r.log.Error("Ошибка при поиске объекта отзыва",
+			"target_type", targetType,
+			"target_id", targetID,
+			"error", err)
+		return 0, fmt.Errorf("ошибка при поиске объекта отзыва %w", err)
+	}
+
+	return categoryID, nil
+}
+
+func (r *reviewsRepository) Rating(targetType string, targetID uint) (*models.RatingStats, error) {
+	var stats models.RatingStats
+
+	var err error
+	if targetType == models.ReviewTargetCategory {
+		var rating models.CategoryRating
+		err = r.reviews.Where("categories_id = ?", targetID).Limit(1).Find(&rating).Error
+		stats = rating.RatingStats
+	} else {
+		var rating models.TargetRating
+		err = r.reviews.Where("target_type = ? AND target_id = ?", targetType, targetID).Limit(1).Find(&rating).Error
+		stats = rating.RatingStats
+	}
+	if err != nil {
+		r.log.Error("Ошибка при получении рейтинга",
+			"target_type", targetType,
+			"target_id", targetID,
+			"error", err)
+		return nil, fmt.Errorf("ошибка при получении рейтинга %w", err)
+	}
+
+	stats.FillHistogram()
+	return &stats, nil
+}
+
+// PrepareReviewTargets backfills the target columns of reviews written before
+// plans could be reviewed and removes duplicate reviews, so that AutoMigrate
+// can build the per-target unique index in place of the per-category one.
+func PrepareReviewTargets(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.Reviews{}) || migrator.HasIndex(&models.Reviews{}, "idx_review_user_target") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+		if !migrator.HasColumn(&models.Reviews{}, "TargetID") {
+			if err := migrator.AddColumn(&models.Reviews{}, "TargetType"); err != nil {
+				return err
+			}
+			if err := migrator.AddColumn(&models.Reviews{}, "TargetID"); err != nil {
+				return err
+			}
+			if err := tx.Exec("UPDATE reviews SET target_type = ?, target_id = categories_id",
+				models.ReviewTargetCategory).Error; err != nil {
+				return err
+			}
+		}
+		if migrator.HasIndex(&models.Reviews{}, "idx_review_user_category") {
+			if err := migrator.DropIndex(&models.Reviews{}, "idx_review_user_category"); err != nil {
+				return err
+			}
+		}
+
+		return dedupeReviews(tx)
+	})
+}
+
+// dedupeReviews keeps the latest review of every user and target. The older
+// ones are deleted and taken out of the ratings.
+func dedupeReviews(tx *gorm.DB) error {
+	var duplicates []models.Reviews
+	if err := tx.Where(`id NOT IN (SELECT MAX(id) FROM reviews WHERE deleted_at IS NULL
+		GROUP BY user_id, target_type, target_id)`).Find(&duplicates).Error; err != nil {
+		return err
+	}
+
+	migrator := tx.Migrator()
+	rated := migrator.HasTable(&models.CategoryRating{}) && migrator.HasTable(&models.TargetRating{})
+	for i := range duplicates {
+		if rated {
+			if err := applyRating(tx, &duplicates[i], ratedStars(&duplicates[i]), -1); err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&duplicates[i]).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
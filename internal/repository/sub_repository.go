@@ -4,8 +4,10 @@ import (
 	"errors"
 	"healthy_body/internal/models"
 	"log/slog"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SubscriptionRepo interface {
@@ -14,6 +16,7 @@ type SubscriptionRepo interface {
 	GetList() ([]models.Subscription, error)
 	Update(up *models.Subscription) error
 	Delete(id uint) error
+	EnqueueExpiryReminders(within time.Duration) (int, error)
 }
 
 type subscriptionRepo struct {
@@ -74,3 +77,60 @@ func (r *subscriptionRepo) Delete(id uint) error {
 
 	return nil
 }
+
+func (r *subscriptionRepo) EnqueueExpiryReminders(within time.Duration) (int, error) {
+	count := 0
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var due []models.UserSubscription
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Preload("Subscription").
+			Where("is_active = ? AND expiry_notified_at IS NULL AND end_date > ? AND end_date <= ?", true, now, now.Add(within)).
+			// a renewing subscription does not expire, only the end of a
+			// trial is worth a warning before the first charge
+			Where("cancel_at_period_end = ? OR trial = ?", true, true).
+			Find(&due).Error; err != nil {
+			return err
+		}
+
+		for _, userSub := range due {
+			eventType := models.EventSubscriptionExpiring
+			if userSub.Trial && !userSub.CancelAtPeriodEnd {
+				eventType = models.EventTrialEnding
+			}
+
+			event := models.NotificationEvent{
+				EventType: eventType,
+				UserID:    userSub.UserID,
+				Data: map[string]any{
+					"subscription_id": userSub.SubscriptionID,
+					"end_date":        userSub.EndDate.Format("02.01.2006"),
+				},
+			}
+			if userSub.Subscription != nil {
+				event.Data["subscription_name"] = userSub.Subscription.Name
+			}
+
+			message, err := models.NewOutboxMessage(event)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(message).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&userSub).UpdateColumn("expiry_notified_at", now).Error; err != nil {
+				return err
+			}
+			count++
+		}
+
+		return nil
+	})
+	if err != nil {
+		r.log.Error("error EnqueueExpiryReminders function in sub_repository.go", "err", err)
+		return 0, err
+	}
+
+	return count, nil
+}
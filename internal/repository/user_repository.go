@@ -73,7 +73,7 @@ func (r *gormUserRepository) GetUserByID(id uint) (*models.User, error) {
 		r.log.Error("Ошибка при получении пользователя по ID",
 			"id", id,
 			"error", err.Error())
-		return nil, fmt.Errorf("ошибка при получении пользователя по %d", id)
+		return nil, fmt.Errorf("ошибка при получении пользователя по %d: %w", id, err)
 	}
 
 	r.log.Info("Пользователь найден успешно",
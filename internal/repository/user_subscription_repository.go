@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"fmt"
+	"healthy_body/internal/models"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type UserSubscriptionRepository interface {
+	ListByUser(userID uint) ([]models.UserSubscription, error)
+	ListDueRenewals(limit int) ([]uint, error)
+	Entitlements(userID uint) ([]models.Entitlement, error)
+	CatalogCategory(targetType string, targetID uint) (uint, error)
+}
+
+type userSubscriptionRepository struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewUserSubscriptionRepository(db *gorm.DB, log *slog.Logger) UserSubscriptionRepository {
+	return &userSubscriptionRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *userSubscriptionRepository) ListByUser(userID uint) ([]models.UserSubscription, error) {
+	var subs []models.UserSubscription
+
+	if err := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&subs).Error; err != nil {
+		r.log.Error("failed to list user subscriptions", "user_id", userID, "err", err)
+		return nil, err
+	}
+
+	return subs, nil
+}
+
+func (r *userSubscriptionRepository) ListDueRenewals(limit int) ([]uint, error) {
+	var ids []uint
+
+	if err := r.db.Model(&models.UserSubscription{}).
+		Where("status = ? AND end_date <= ?", models.SubStatusActive, time.Now()).
+		Order("end_date").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		r.log.Error("failed to list due subscriptions", "err", err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Entitlements lists the categories a user can open: every purchased plan and
+// the category of the subscription that is running right now.
+func (r *userSubscriptionRepository) Entitlements(userID uint) ([]models.Entitlement, error) {
+	var plans []models.UserPlan
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&plans).Error; err != nil {
+		r.log.Error("failed to list user plans", "user_id", userID, "err", err)
+		return nil, err
+	}
+
+	var subs []models.UserSubscription
+	now := time.Now()
+	if err := r.db.Where("user_id = ? AND status = ? AND start_date <= ? AND end_date > ?",
+		userID, models.SubStatusActive, now, now).Order("id").Find(&subs).Error; err != nil {
+		r.log.Error("failed to list active subscriptions", "user_id", userID, "err", err)
+		return nil, err
+	}
+
+	seen := make(map[uint]bool)
+	entitlements := make([]models.Entitlement, 0, len(plans)+len(subs))
+	for _, plan := range plans {
+		if seen[plan.CategoriesID] {
+			continue
+		}
+		seen[plan.CategoriesID] = true
+		entitlements = append(entitlements, models.Entitlement{
+			CategoriesID: plan.CategoriesID,
+			Source:       models.EntitlementPlan,
+			UserPlanID:   &plan.ID,
+		})
+	}
+	for _, sub := range subs {
+		if seen[sub.CategoriesID] {
+			continue
+		}
+		seen[sub.CategoriesID] = true
+		entitlements = append(entitlements, models.Entitlement{
+			CategoriesID:       sub.CategoriesID,
+			Source:             models.EntitlementSubscription,
+			UserSubscriptionID: &sub.ID,
+			Until:              &sub.EndDate,
+		})
+	}
+
+	return entitlements, nil
+}
+
+// CatalogCategory returns the category a catalog item is sold in, or 0 when
+// the item is not part of any category.
+func (r *userSubscriptionRepository) CatalogCategory(targetType string, targetID uint) (uint, error) {
+	var model any
+	switch targetType {
+	case models.CatalogCategory:
+		return targetID, nil
+	case models.CatalogExercisePlan:
+		model = &models.ExercisePlan{}
+	case models.CatalogMealPlan:
+		model = &models.MealPlan{}
+	case models.CatalogExercisePlanItem, models.CatalogMealPlanItem, models.CatalogSubscription:
+		parentType, parentID, err := catalogParent(r.db, targetType, targetID)
+		if err != nil {
+			return 0, err
+		}
+		return r.CatalogCategory(parentType, parentID)
+	default:
+		return 0, fmt.Errorf("unknown catalog item type %q", targetType)
+	}
+
+	categoryID, err := catalogColumn(r.db, model, "categories_id", targetID)
+	if err != nil {
+		return 0, err
+	}
+	if categoryID == nil {
+		return 0, nil
+	}
+
+	return *categoryID, nil
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"healthy_body/internal/storage"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MaxPhotoSize       = 5 << 20
+	maxPhotoPixels     = 40_000_000
+	maxPhotosPerReview = 5
+	thumbnailSize      = 320
+)
+
+var (
+	ErrPhotoTooLarge      = errors.New("фото больше 5 МБ или 40 мегапикселей")
+	ErrPhotoType          = errors.New("поддерживаются только изображения JPEG и PNG")
+	ErrPhotoLimit         = fmt.Errorf("к отзыву можно прикрепить не больше %d фото", maxPhotosPerReview)
+	ErrPhotoForbidden     = errors.New("управлять фото может только автор отзыва")
+	ErrAttachmentNotFound = errors.New("вложение не найдено")
+	photoExtensions       = map[string]string{"image/jpeg": ".jpg", "image/png": ".png"}
+)
+
+type AttachmentService interface {
+	UploadReviewPhoto(ctx context.Context, reviewID, userID uint, body io.Reader) (*models.GetAttachment, error)
+	DeleteReviewPhoto(ctx context.Context, reviewID, attachmentID, userID uint) error
+	UploadUserPhoto(ctx context.Context, userID uint, body io.Reader) (*models.GetAttachment, error)
+	Open(ctx context.Context, id uint, thumbnail bool) (io.ReadCloser, string, error)
+}
+
+type attachmentService struct {
+	repo    repository.AttachmentRepository
+	reviews repository.ReviewsRepository
+	store   storage.Storage
+	log     *slog.Logger
+}
+
+func NewAttachmentService(repo repository.AttachmentRepository, reviews repository.ReviewsRepository, store storage.Storage, log *slog.Logger) AttachmentService {
+	return &attachmentService{repo: repo, reviews: reviews, store: store, log: log}
+}
+
+func (s *attachmentService) UploadReviewPhoto(ctx context.Context, reviewID, userID uint, body io.Reader) (*models.GetAttachment, error) {
+	review, err := s.authorReview(reviewID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountByReview(review.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxPhotosPerReview {
+		return nil, ErrPhotoLimit
+	}
+
+	attachment := models.Attachment{ReviewID: &review.ID, UserID: userID}
+	if err := s.save(ctx, &attachment, fmt.Sprintf("reviews/%d", review.ID), body); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Фото добавлено к отзыву",
+		"review_id", review.ID,
+		"attachment_id", attachment.ID)
+	view := attachmentView(&attachment)
+	return &view, nil
+}
+
+func (s *attachmentService) UploadUserPhoto(ctx context.Context, userID uint, body io.Reader) (*models.GetAttachment, error) {
+	attachment := models.Attachment{UserID: userID}
+	if err := s.save(ctx, &attachment, fmt.Sprintf("users/%d", userID), body); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Фото пользователя загружено",
+		"user_id", userID,
+		"attachment_id", attachment.ID)
+	view := attachmentView(&attachment)
+	return &view, nil
+}
+
+// save processes the photo, stores the image and its thumbnail under prefix
+// and records the attachment.
+func (s *attachmentService) save(ctx context.Context, attachment *models.Attachment, prefix string, body io.Reader) error {
+	data, err := io.ReadAll(io.LimitReader(body, MaxPhotoSize+1))
+	if err != nil {
+		return fmt.Errorf("ошибка при чтении фото: %w", err)
+	}
+	if len(data) > MaxPhotoSize {
+		return ErrPhotoTooLarge
+	}
+
+	photo, err := processPhoto(data)
+	if err != nil {
+		s.log.Warn("Фото отклонено",
+			"prefix", prefix,
+			"error", err.Error())
+		return err
+	}
+
+	name, err := newPhotoName()
+	if err != nil {
+		return err
+	}
+	ext := photoExtensions[photo.contentType]
+	attachment.Key = fmt.Sprintf("%s/%s%s", prefix, name, ext)
+	attachment.ThumbnailKey = fmt.Sprintf("%s/%s_thumb%s", prefix, name, ext)
+	attachment.ContentType = photo.contentType
+	attachment.Size = int64(len(photo.full))
+	attachment.Width = photo.width
+	attachment.Height = photo.height
+
+	if err := s.store.Put(ctx, attachment.Key, bytes.NewReader(photo.full), photo.contentType); err != nil {
+		s.log.Error("Ошибка при сохранении фото",
+			"key", attachment.Key,
+			"error", err.Error())
+		return fmt.Errorf("ошибка при сохранении фото: %w", err)
+	}
+	if err := s.store.Put(ctx, attachment.ThumbnailKey, bytes.NewReader(photo.thumbnail), photo.contentType); err != nil {
+		s.removeBlobs(ctx, attachment)
+		s.log.Error("Ошибка при сохранении миниатюры",
+			"key", attachment.ThumbnailKey,
+			"error", err.Error())
+		return fmt.Errorf("ошибка при сохранении фото: %w", err)
+	}
+
+	if err := s.repo.Create(attachment); err != nil {
+		s.removeBlobs(ctx, attachment)
+		return err
+	}
+
+	return nil
+}
+
+func (s *attachmentService) DeleteReviewPhoto(ctx context.Context, reviewID, attachmentID, userID uint) error {
+	if _, err := s.authorReview(reviewID, userID); err != nil {
+		return err
+	}
+
+	attachment, err := s.repo.GetByID(attachmentID)
+	if err != nil || attachment.ReviewID == nil || *attachment.ReviewID != reviewID {
+		return ErrAttachmentNotFound
+	}
+
+	if err := s.repo.Delete(attachment.ID); err != nil {
+		return err
+	}
+	s.removeBlobs(ctx, attachment)
+
+	s.log.Info("Фото удалено из отзыва",
+		"review_id", reviewID,
+		"attachment_id", attachmentID)
+	return nil
+}
+
+func (s *attachmentService) Open(ctx context.Context, id uint, thumbnail bool) (io.ReadCloser, string, error) {
+	attachment, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrAttachmentNotFound
+		}
+		return nil, "", err
+	}
+
+	key := attachment.Key
+	if thumbnail {
+		key = attachment.ThumbnailKey
+	}
+
+	body, err := s.store.Open(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, "", ErrAttachmentNotFound
+		}
+		return nil, "", err
+	}
+
+	return body, attachment.ContentType, nil
+}
+
+func (s *attachmentService) authorReview(reviewID, userID uint) (*models.Reviews, error) {
+	review, err := s.reviews.GetReviewsByID(reviewID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	if review.UserID != userID {
+		s.log.Warn("Попытка изменить фото чужого отзыва",
+			"review_id", reviewID,
+			"user_id", userID)
+		return nil, ErrPhotoForbidden
+	}
+
+	return review, nil
+}
+
+func (s *attachmentService) removeBlobs(ctx context.Context, attachment *models.Attachment) {
+	for _, key := range []string{attachment.Key, attachment.ThumbnailKey} {
+		if err := s.store.Delete(ctx, key); err != nil {
+			s.log.Error("Ошибка при удалении файла",
+				"key", key,
+				"error", err.Error())
+		}
+	}
+}
+
+type processedPhoto struct {
+	contentType   string
+	width, height int
+	full          []byte
+	thumbnail     []byte
+}
+
+// processPhoto validates the upload and re-encodes it. The encoders write
+// pixels only, so EXIF (GPS position, camera serial) and other metadata are
+// dropped along the way; the EXIF orientation is applied to the pixels first.
+func processPhoto(data []byte) (*processedPhoto, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := photoExtensions[contentType]; !ok {
+		return nil, ErrPhotoType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrPhotoType
+	}
+	if config.Width*config.Height > maxPhotoPixels {
+		return nil, ErrPhotoTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrPhotoType
+	}
+	img = orient(img, photoOrientation(data, contentType))
+	bounds := img.Bounds()
+
+	full, err := encodePhoto(img, contentType)
+	if err != nil {
+		return nil, err
+	}
+	thumb, err := encodePhoto(thumbnail(img, thumbnailSize), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &processedPhoto{
+		contentType: contentType,
+		width:       bounds.Dx(),
+		height:      bounds.Dy(),
+		full:        full,
+		thumbnail:   thumb,
+	}, nil
+}
+
+func encodePhoto(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var err error
+	if contentType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при обработке фото: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// thumbnail scales img down so that its longer side is size pixels, averaging
+// every source pixel that falls into a target one.
+func thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+
+	tw, th := size, size
+	if w >= h {
+		th = max(1, h*size/w)
+	} else {
+		tw = max(1, w*size/h)
+	}
+
+	dst := image.NewRGBA64(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := bounds.Min.Y+y*h/th, bounds.Min.Y+(y+1)*h/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := bounds.Min.X+x*w/tw, bounds.Min.X+(x+1)*w/tw
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+
+	return dst
+}
+
+func newPhotoName() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+func attachmentView(attachment *models.Attachment) models.GetAttachment {
+	return models.GetAttachment{
+		ID:           attachment.ID,
+		URL:          fmt.Sprintf("/attachments/%d", attachment.ID),
+		ThumbnailURL: fmt.Sprintf("/attachments/%d/thumbnail", attachment.ID),
+		ContentType:  attachment.ContentType,
+		Width:        attachment.Width,
+		Height:       attachment.Height,
+	}
+}
+
+func attachmentViews(attachments []models.Attachment) []models.GetAttachment {
+	var views []models.GetAttachment
+	for i := range attachments {
+		views = append(views, attachmentView(&attachments[i]))
+	}
+
+	return views
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"log/slog"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CartService interface {
+	Quote(userID uint, promoCode, currency string) (*models.CartQuote, error)
+	AddItem(userID uint, req models.AddCartItemRequest) (*models.CartQuote, error)
+	RemoveItem(userID, itemID uint) (*models.CartQuote, error)
+	Checkout(userID uint, promoCode, currency string) (*models.CheckoutResult, error)
+}
+
+type cartService struct {
+	repo  repository.CartRepository
+	users repository.UserRepository
+	db    *gorm.DB
+	log   *slog.Logger
+}
+
+func NewCartService(repo repository.CartRepository, users repository.UserRepository, db *gorm.DB, log *slog.Logger) CartService {
+	return &cartService{
+		repo:  repo,
+		users: users,
+		db:    db,
+		log:   log,
+	}
+}
+
+type cartEntry struct {
+	line         *models.CartLine
+	category     *models.Categories
+	subscription *models.Subscription
+}
+
+func (s *cartService) Quote(userID uint, promoCode, currency string) (*models.CartQuote, error) {
+	user, err := s.users.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, err = purchaseCurrency(user, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, _, _, err := quoteCart(s.db, userID, items, promoCode, currency, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+func (s *cartService) AddItem(userID uint, req models.AddCartItemRequest) (*models.CartQuote, error) {
+	if _, err := s.users.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	switch req.TargetType {
+	case models.TargetCategory:
+		var category models.Categories
+		if err := s.db.First(&category, req.TargetID).Error; err != nil {
+			return nil, fmt.Errorf("категория не найдена")
+		}
+		if category.Status != models.StatusPublished {
+			return nil, fmt.Errorf("категория недоступна для покупки")
+		}
+	case models.TargetSubscription:
+		var sub models.Subscription
+		if err := s.db.First(&sub, req.TargetID).Error; err != nil {
+			return nil, fmt.Errorf("подписка не найдена")
+		}
+		if err := ensureOnSale(s.db, &sub); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("неизвестный тип товара %q", req.TargetType)
+	}
+
+	if err := s.repo.Add(&models.CartItem{
+		UserID:     userID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.Quote(userID, "", "")
+}
+
+func (s *cartService) RemoveItem(userID, itemID uint) (*models.CartQuote, error) {
+	if err := s.repo.Remove(userID, itemID); err != nil {
+		return nil, err
+	}
+
+	return s.Quote(userID, "", "")
+}
+
+func (s *cartService) Checkout(userID uint, promoCode, currency string) (*models.CheckoutResult, error) {
+	result := &models.CheckoutResult{}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("пользователь не найден")
+			}
+			return fmt.Errorf("ошибка при поиске пользователя %w", err)
+		}
+
+		var items []models.CartItem
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).Order("id").Find(&items).Error; err != nil {
+			return err
+		}
+
+		if len(items) == 0 {
+			return fmt.Errorf("корзина пуста")
+		}
+
+		currency, err := purchaseCurrency(&user, currency)
+		if err != nil {
+			return err
+		}
+
+		quote, promo, entries, err := quoteCart(tx, userID, items, promoCode, currency, true)
+		if err != nil {
+			return err
+		}
+		result.Quote = quote
+
+		if err := debitWallet(tx, &user, quote.Total, currency); err != nil {
+			s.log.Warn("Не удалось списать средства", "user_id", userID, "error", err.Error())
+			return err
+		}
+
+		var promoID *uint
+		if promo != nil {
+			promoID = &promo.ID
+		}
+
+		order := &models.Order{UserID: userID, PromoCodeID: promoID, Currency: currency}
+		receipt := make([]map[string]any, 0, len(entries))
+		for _, entry := range entries {
+			line := entry.line
+			linePromo := promoID
+			if line.Discount == 0 {
+				linePromo = nil
+			}
+
+			item := models.OrderItem{
+				TargetType: line.TargetType,
+				TargetID:   line.TargetID,
+				Name:       line.Name,
+				ListPrice:  line.ListPrice,
+				Discount:   line.Discount,
+				FinalPrice: line.FinalPrice,
+			}
+
+			switch {
+			case entry.category != nil:
+				userPlan := models.UserPlan{
+					UserID:          userID,
+					CategoriesID:    entry.category.ID,
+					CategoryVersion: entry.category.Version,
+					Currency:        currency,
+					PricePaid:       line.FinalPrice,
+					Discount:        line.Discount,
+					PromoCodeID:     linePromo,
+				}
+				if err := tx.Create(&userPlan).Error; err != nil {
+					return fmt.Errorf("ошибка при записи покупки пользователя %w", err)
+				}
+				user.CategoriesID = entry.category.ID
+				item.UserPlanID = &userPlan.ID
+				result.UserPlans = append(result.UserPlans, userPlan)
+			case entry.subscription != nil:
+				userSub := models.UserSubscription{
+					UserID:      userID,
+					Currency:    currency,
+					PricePaid:   line.FinalPrice,
+					Discount:    line.Discount,
+					PromoCodeID: linePromo,
+				}
+				if err := activateSubscription(tx, &userSub, entry.subscription); err != nil {
+					return fmt.Errorf("ошибка при оформлении подписки %w", err)
+				}
+				item.UserSubscriptionID = &userSub.ID
+				result.UserSubscriptions = append(result.UserSubscriptions, userSub)
+			}
+
+			order.Items = append(order.Items, item)
+
+			receipt = append(receipt, map[string]any{
+				"name":  line.Name,
+				"price": models.Money{Amount: line.FinalPrice, Currency: currency}.String(),
+			})
+		}
+
+		if err := tx.Model(&user).UpdateColumn("categories_id", user.CategoriesID).Error; err != nil {
+			return fmt.Errorf("ошибка при сохранении пользователя %w", err)
+		}
+
+		if err := createOrder(tx, order); err != nil {
+			return fmt.Errorf("ошибка при создании заказа %w", err)
+		}
+		result.Order = order
+
+		if promo != nil {
+			if err := redeemPromo(tx, promo, userID, models.TargetCart, order.ID, quote.Discount, currency); err != nil {
+				return fmt.Errorf("ошибка при применении промокода %w", err)
+			}
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+
+		data := map[string]any{
+			"items":          receipt,
+			"receipt_number": order.ReceiptNumber,
+			"total":          models.Money{Amount: quote.Total, Currency: currency}.String(),
+		}
+		if quote.Discount > 0 {
+			data["discount"] = models.Money{Amount: quote.Discount, Currency: currency}.String()
+		}
+
+		return enqueueNotification(tx, models.NotificationEvent{
+			EventType: models.EventCheckoutReceipt,
+			UserID:    userID,
+			Data:      data,
+		})
+	})
+	if err != nil {
+		s.log.Error("Ошибка при оформлении заказа", "user_id", userID, "error", err.Error())
+		return nil, err
+	}
+
+	s.log.Info("Заказ оформлен", "user_id", userID, "total", result.Quote.Total)
+	return result, nil
+}
+
+func quoteCart(tx *gorm.DB, userID uint, items []models.CartItem, promoCode, currency string, lock bool) (*models.CartQuote, *models.PromoCode, []cartEntry, error) {
+	quote := &models.CartQuote{UserID: userID, Currency: currency, Lines: []models.CartLine{}}
+	entries := make([]cartEntry, 0, len(items))
+
+	for _, item := range items {
+		line := models.CartLine{
+			CartItemID: item.ID,
+			TargetType: item.TargetType,
+			TargetID:   item.TargetID,
+		}
+		entry := cartEntry{}
+
+		switch item.TargetType {
+		case models.TargetCategory:
+			var category models.Categories
+			if err := tx.First(&category, item.TargetID).Error; err != nil {
+				return nil, nil, nil, fmt.Errorf("категория %d не найдена", item.TargetID)
+			}
+			if category.Status != models.StatusPublished {
+				return nil, nil, nil, fmt.Errorf("категория %q недоступна для покупки", category.Name)
+			}
+			price, err := priceFor(tx, models.TargetCategory, category.ID, category.Price, currency)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("%s: %w", category.Name, err)
+			}
+			line.Name = category.Name
+			line.ListPrice = price
+			entry.category = &category
+		case models.TargetSubscription:
+			var sub models.Subscription
+			if err := tx.First(&sub, item.TargetID).Error; err != nil {
+				return nil, nil, nil, fmt.Errorf("подписка %d не найдена", item.TargetID)
+			}
+			if err := ensureOnSale(tx, &sub); err != nil {
+				return nil, nil, nil, fmt.Errorf("%s: %w", sub.Name, err)
+			}
+			price, err := priceFor(tx, models.TargetSubscription, sub.ID, sub.Price, currency)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("%s: %w", sub.Name, err)
+			}
+			line.Name = sub.Name
+			line.ListPrice = price
+			entry.subscription = &sub
+		default:
+			continue
+		}
+
+		line.FinalPrice = line.ListPrice
+		quote.Lines = append(quote.Lines, line)
+		entries = append(entries, entry)
+	}
+
+	var promo *models.PromoCode
+	if promoCode != "" {
+		var err error
+		promo, err = loadPromo(tx, promoCode, userID, lock)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if err := checkPromoCurrency(promo, currency); err != nil {
+			return nil, nil, nil, err
+		}
+
+		// a fixed discount is spent once across the eligible lines
+		remaining := promo.Value
+		applied := false
+		for i := range quote.Lines {
+			line := &quote.Lines[i]
+			if !promoApplies(promo, line.TargetType, line.TargetID) {
+				continue
+			}
+			applied = true
+
+			discount := promoDiscount(promo, line.ListPrice, currency)
+			if promo.DiscountType == models.DiscountFixed {
+				discount = min(remaining, line.ListPrice)
+				remaining -= discount
+			}
+
+			line.Discount = discount
+			line.FinalPrice = line.ListPrice - discount
+		}
+
+		if !applied {
+			return nil, nil, nil, fmt.Errorf("промокод не действует для этой покупки")
+		}
+		quote.PromoCode = promo.Code
+	}
+
+	for i := range quote.Lines {
+		entries[i].line = &quote.Lines[i]
+		quote.ListTotal += quote.Lines[i].ListPrice
+		quote.Discount += quote.Lines[i].Discount
+		quote.Total += quote.Lines[i].FinalPrice
+	}
+
+	return quote, promo, entries, nil
+}
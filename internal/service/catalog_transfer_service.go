@@ -0,0 +1,361 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CatalogTransferService interface {
+	Export() (*models.CatalogDocument, error)
+	ExportCSV(w io.Writer) error
+	Import(doc *models.CatalogDocument, dryRun bool) (*models.ImportReport, error)
+}
+
+type catalogTransferService struct {
+	repo repository.CatalogTransferRepository
+	log  *slog.Logger
+}
+
+func NewCatalogTransferService(repo repository.CatalogTransferRepository, log *slog.Logger) CatalogTransferService {
+	return &catalogTransferService{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (s *catalogTransferService) Export() (*models.CatalogDocument, error) {
+	categories, subscriptions, err := s.repo.LoadCatalog()
+	if err != nil {
+		s.log.Error("error Export in catalog_transfer_service.go", "err", err)
+		return nil, err
+	}
+
+	subsByCategory := make(map[uint][]models.Subscription)
+	for _, sub := range subscriptions {
+		subsByCategory[sub.CategoriesID] = append(subsByCategory[sub.CategoriesID], sub)
+	}
+
+	doc := &models.CatalogDocument{
+		FormatVersion: models.CatalogFormatVersion,
+		ExportedAt:    time.Now().UTC(),
+		Categories:    make([]models.CatalogCategoryRecord, 0, len(categories)),
+	}
+
+	for _, category := range categories {
+		record := models.CatalogCategoryRecord{
+			ExternalKey:   category.ExternalKey,
+			Name:          category.Name,
+			Description:   category.Description,
+			Price:         category.Price,
+			Status:        category.Status,
+			Goals:         category.Goals,
+			ExercisePlans: []models.CatalogExercisePlanRecord{},
+			MealPlans:     []models.CatalogMealPlanRecord{},
+			Subscriptions: []models.CatalogSubscriptionRecord{},
+		}
+
+		for _, plan := range category.ExercisePlans {
+			planRecord := models.CatalogExercisePlanRecord{
+				ExternalKey:      plan.ExternalKey,
+				Name:             plan.Name,
+				Description:      plan.Description,
+				DurationWeeks:    plan.DurationWeeks,
+				Status:           plan.Status,
+				Exercises:        []models.CatalogExerciseRecord{},
+				ProgressionRules: []models.CatalogProgressionRuleRecord{},
+			}
+			itemKeys := make(map[uint]string, len(plan.Exercises))
+			for _, item := range plan.Exercises {
+				itemKeys[item.ID] = item.ExternalKey
+				planRecord.Exercises = append(planRecord.Exercises, models.CatalogExerciseRecord{
+					ExternalKey:     item.ExternalKey,
+					Name:            item.Name,
+					Sets:            item.Sets,
+					Reps:            item.Reps,
+					DurationMinutes: item.DurationMinutes,
+					EquipmentNeeded: item.EquipmentNeeded,
+					DayOfWeek:       item.DayOfWeek,
+					MuscleGroup:     item.MuscleGroup,
+					WeightKg:        item.WeightKg,
+				})
+			}
+			for _, rule := range plan.ProgressionRules {
+				ruleRecord := models.CatalogProgressionRuleRecord{
+					Type:       rule.Type,
+					StartWeek:  rule.StartWeek,
+					EndWeek:    rule.EndWeek,
+					EveryWeeks: rule.EveryWeeks,
+					Value:      rule.Value,
+				}
+				if rule.ExercisePlanItemID != nil {
+					ruleRecord.ExerciseKey = itemKeys[*rule.ExercisePlanItemID]
+				}
+				planRecord.ProgressionRules = append(planRecord.ProgressionRules, ruleRecord)
+			}
+			record.ExercisePlans = append(record.ExercisePlans, planRecord)
+		}
+
+		for _, mealPlan := range category.MealPlans {
+			mealPlanRecord := models.CatalogMealPlanRecord{
+				ExternalKey: mealPlan.ExternalKey,
+				Name:        mealPlan.Name,
+				Description: mealPlan.Description,
+				TotalDays:   mealPlan.TotalDays,
+				Status:      mealPlan.Status,
+				Meals:       []models.CatalogMealRecord{},
+			}
+			for _, meal := range mealPlan.Meals {
+				mealPlanRecord.Meals = append(mealPlanRecord.Meals, models.CatalogMealRecord{
+					ExternalKey: meal.ExternalKey,
+					Name:        meal.Name,
+					Description: meal.Description,
+					Calories:    meal.Calories,
+					Protein:     meal.Protein,
+					Carbs:       meal.Carbs,
+				})
+			}
+			record.MealPlans = append(record.MealPlans, mealPlanRecord)
+		}
+
+		for _, sub := range subsByCategory[category.ID] {
+			record.Subscriptions = append(record.Subscriptions, models.CatalogSubscriptionRecord{
+				ExternalKey:  sub.ExternalKey,
+				Name:         sub.Name,
+				Description:  sub.Description,
+				Price:        sub.Price,
+				DurationDays: sub.DurationDays,
+				TrialDays:    sub.TrialDays,
+			})
+		}
+
+		doc.Categories = append(doc.Categories, record)
+	}
+
+	s.log.Info("catalog exported", "categories", len(doc.Categories))
+	return doc, nil
+}
+
+func (s *catalogTransferService) ExportCSV(w io.Writer) error {
+	doc, err := s.Export()
+	if err != nil {
+		return err
+	}
+
+	tables := map[string][][]string{
+		models.TableCategories:        {{"external_key", "name", "description", "price", "status", "goals"}},
+		models.TableExercisePlans:     {{"external_key", "category_key", "name", "description", "duration_weeks", "status"}},
+		models.TableExercisePlanItems: {{"external_key", "exercise_plan_key", "name", "sets", "reps", "duration_minutes", "equipment_needed", "day_of_week", "muscle_group", "weight_kg"}},
+		models.TableMealPlans:         {{"external_key", "category_key", "name", "description", "total_days", "status"}},
+		models.TableMealPlanItems:     {{"external_key", "meal_plan_key", "name", "description", "calories", "protein", "carbs"}},
+		models.TableSubscriptions:     {{"external_key", "category_key", "name", "description", "price", "duration_days"}},
+		models.TableProgressionRules:  {{"exercise_plan_key", "exercise_key", "type", "start_week", "end_week", "every_weeks", "value"}},
+	}
+
+	for _, category := range doc.Categories {
+		tables[models.TableCategories] = append(tables[models.TableCategories], []string{
+			category.ExternalKey, category.Name, category.Description, strconv.FormatInt(category.Price, 10), category.Status,
+			strings.Join(category.Goals, ";"),
+		})
+
+		for _, plan := range category.ExercisePlans {
+			tables[models.TableExercisePlans] = append(tables[models.TableExercisePlans], []string{
+				plan.ExternalKey, category.ExternalKey, plan.Name, plan.Description, strconv.Itoa(plan.DurationWeeks), plan.Status,
+			})
+			for _, item := range plan.Exercises {
+				tables[models.TableExercisePlanItems] = append(tables[models.TableExercisePlanItems], []string{
+					item.ExternalKey, plan.ExternalKey, item.Name, strconv.Itoa(item.Sets), strconv.Itoa(item.Reps),
+					item.DurationMinutes, item.EquipmentNeeded, item.DayOfWeek, item.MuscleGroup, formatFloat(item.WeightKg),
+				})
+			}
+			for _, rule := range plan.ProgressionRules {
+				tables[models.TableProgressionRules] = append(tables[models.TableProgressionRules], []string{
+					plan.ExternalKey, rule.ExerciseKey, rule.Type, strconv.Itoa(rule.StartWeek), strconv.Itoa(rule.EndWeek),
+					strconv.Itoa(rule.EveryWeeks), formatFloat(rule.Value),
+				})
+			}
+		}
+
+		for _, mealPlan := range category.MealPlans {
+			tables[models.TableMealPlans] = append(tables[models.TableMealPlans], []string{
+				mealPlan.ExternalKey, category.ExternalKey, mealPlan.Name, mealPlan.Description, strconv.Itoa(mealPlan.TotalDays), mealPlan.Status,
+			})
+			for _, meal := range mealPlan.Meals {
+				tables[models.TableMealPlanItems] = append(tables[models.TableMealPlanItems], []string{
+					meal.ExternalKey, mealPlan.ExternalKey, meal.Name, meal.Description,
+					formatFloat(meal.Calories), formatFloat(meal.Protein), formatFloat(meal.Carbs),
+				})
+			}
+		}
+
+		for _, sub := range category.Subscriptions {
+			tables[models.TableSubscriptions] = append(tables[models.TableSubscriptions], []string{
+				sub.ExternalKey, category.ExternalKey, sub.Name, sub.Description, strconv.FormatInt(sub.Price, 10), strconv.Itoa(sub.DurationDays),
+			})
+		}
+	}
+
+	archive := zip.NewWriter(w)
+	for _, table := range []string{
+		models.TableCategories,
+		models.TableExercisePlans,
+		models.TableExercisePlanItems,
+		models.TableProgressionRules,
+		models.TableMealPlans,
+		models.TableMealPlanItems,
+		models.TableSubscriptions,
+	} {
+		file, err := archive.Create(table + ".csv")
+		if err != nil {
+			s.log.Error("error ExportCSV in catalog_transfer_service.go", "table", table, "err", err)
+			return err
+		}
+
+		writer := csv.NewWriter(file)
+		if err := writer.WriteAll(tables[table]); err != nil {
+			s.log.Error("error ExportCSV in catalog_transfer_service.go", "table", table, "err", err)
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func (s *catalogTransferService) Import(doc *models.CatalogDocument, dryRun bool) (*models.ImportReport, error) {
+	if doc == nil {
+		return nil, errors.New("empty catalog document")
+	}
+
+	if doc.FormatVersion != models.CatalogFormatVersion {
+		return nil, fmt.Errorf("unsupported catalog format version %d, expected %d", doc.FormatVersion, models.CatalogFormatVersion)
+	}
+
+	report := models.NewImportReport(dryRun)
+	validateCatalogDocument(doc, report)
+	if len(report.Errors) > 0 {
+		s.log.Warn("catalog import rejected", "errors", len(report.Errors))
+		return report, nil
+	}
+
+	if err := s.repo.Import(doc, report); err != nil {
+		s.log.Error("error Import in catalog_transfer_service.go", "err", err)
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func validateCatalogDocument(doc *models.CatalogDocument, report *models.ImportReport) {
+	rows := make(map[string]int)
+	seen := make(map[string]map[string]bool)
+
+	check := func(table, key string, errs ...error) {
+		rows[table]++
+		if seen[table] == nil {
+			seen[table] = make(map[string]bool)
+		}
+
+		if key == "" {
+			errs = append(errs, errors.New("external_key is required"))
+		} else if seen[table][key] {
+			errs = append(errs, errors.New("duplicate external_key"))
+		}
+		seen[table][key] = true
+
+		for _, err := range errs {
+			if err != nil {
+				report.AddError(table, rows[table], key, err)
+			}
+		}
+	}
+
+	for _, category := range doc.Categories {
+		check(models.TableCategories, category.ExternalKey,
+			requireText(category.Name, "name"),
+			requireText(category.Description, "description"),
+			requirePositive(category.Price, "price"),
+			validateGoals(category.Goals),
+		)
+
+		for _, plan := range category.ExercisePlans {
+			check(models.TableExercisePlans, plan.ExternalKey,
+				requirePositive(plan.DurationWeeks, "duration_weeks"),
+			)
+
+			exerciseKeys := make(map[string]bool, len(plan.Exercises))
+			for _, item := range plan.Exercises {
+				check(models.TableExercisePlanItems, item.ExternalKey,
+					requireText(item.Name, "name"),
+					requirePositive(item.Sets, "sets"),
+					requirePositive(item.Reps, "reps"),
+				)
+				exerciseKeys[item.ExternalKey] = true
+			}
+
+			for _, rule := range plan.ProgressionRules {
+				rows[models.TableProgressionRules]++
+				if err := validateProgressionRule(&models.ExercisePlan{DurationWeeks: plan.DurationWeeks}, &models.ProgressionRule{
+					Type:       rule.Type,
+					StartWeek:  rule.StartWeek,
+					EndWeek:    rule.EndWeek,
+					EveryWeeks: rule.EveryWeeks,
+					Value:      rule.Value,
+				}); err != nil {
+					report.AddError(models.TableProgressionRules, rows[models.TableProgressionRules], rule.ExerciseKey, err)
+				}
+				if rule.ExerciseKey != "" && !exerciseKeys[rule.ExerciseKey] {
+					report.AddError(models.TableProgressionRules, rows[models.TableProgressionRules], rule.ExerciseKey,
+						errors.New("exercise_key is not an exercise of this plan"))
+				}
+			}
+		}
+
+		for _, mealPlan := range category.MealPlans {
+			check(models.TableMealPlans, mealPlan.ExternalKey,
+				requirePositive(mealPlan.TotalDays, "total_days"),
+			)
+
+			for _, meal := range mealPlan.Meals {
+				check(models.TableMealPlanItems, meal.ExternalKey,
+					requireText(meal.Name, "name"),
+				)
+			}
+		}
+
+		for _, sub := range category.Subscriptions {
+			check(models.TableSubscriptions, sub.ExternalKey,
+				requireText(sub.Name, "name"),
+				requirePositive(sub.Price, "price"),
+				requirePositive(sub.DurationDays, "duration_days"),
+			)
+		}
+	}
+}
+
+func requireText(value, field string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required", field)
+	}
+
+	return nil
+}
+
+func requirePositive[T int | int64](value T, field string) error {
+	if value <= 0 {
+		return fmt.Errorf("%s must be greater than zero", field)
+	}
+
+	return nil
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"log/slog"
+)
+
+// ErrPublished is returned for edits of a published catalog item: it has to be
+// unpublished or cloned into a draft first.
+var ErrPublished = errors.New("published items are read-only, unpublish or clone it to edit")
+
+type CatalogVersionService interface {
+	Publish(entityType string, id uint) (*models.CatalogVersion, error)
+	Unpublish(entityType string, id uint) error
+	Archive(entityType string, id uint) error
+	ListVersions(entityType string, id uint) ([]models.CatalogVersion, error)
+	GetVersion(entityType string, id uint, version int) (*models.CatalogVersion, error)
+}
+
+type catalogVersionService struct {
+	versions repository.CatalogVersionRepository
+	category CategoryServices
+	plan     ExercisePlanServices
+	mealPlan MealPlanService
+	log      *slog.Logger
+}
+
+func NewCatalogVersionService(
+	versions repository.CatalogVersionRepository,
+	log *slog.Logger,
+	category CategoryServices,
+	plan ExercisePlanServices,
+	mealPlan MealPlanService,
+) CatalogVersionService {
+	return &catalogVersionService{
+		versions: versions,
+		category: category,
+		plan:     plan,
+		mealPlan: mealPlan,
+		log:      log,
+	}
+}
+
+func (s *catalogVersionService) Publish(entityType string, id uint) (*models.CatalogVersion, error) {
+	if err := s.validatePublishable(entityType, id); err != nil {
+		s.log.Warn("catalog entity is not publishable", "entity_type", entityType, "id", id, "err", err)
+		return nil, err
+	}
+
+	version, err := s.versions.Publish(entityType, id)
+	if err != nil {
+		s.log.Error("error Publish in catalog_version_service.go", "err", err)
+		return nil, err
+	}
+
+	return version, nil
+}
+
+func (s *catalogVersionService) Unpublish(entityType string, id uint) error {
+	status, err := s.currentStatus(entityType, id)
+	if err != nil {
+		return err
+	}
+
+	if status != models.StatusPublished {
+		return fmt.Errorf("%s %d is not published", entityType, id)
+	}
+
+	if err := s.versions.SetStatus(entityType, id, models.StatusDraft); err != nil {
+		s.log.Error("error Unpublish in catalog_version_service.go", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *catalogVersionService) Archive(entityType string, id uint) error {
+	if err := s.versions.SetStatus(entityType, id, models.StatusArchived); err != nil {
+		s.log.Error("error Archive in catalog_version_service.go", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *catalogVersionService) ListVersions(entityType string, id uint) ([]models.CatalogVersion, error) {
+	if _, err := s.currentStatus(entityType, id); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.versions.ListVersions(entityType, id)
+	if err != nil {
+		s.log.Error("error ListVersions in catalog_version_service.go", "err", err)
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func (s *catalogVersionService) GetVersion(entityType string, id uint, version int) (*models.CatalogVersion, error) {
+	if version < 1 {
+		return nil, errors.New("version must be greater than zero")
+	}
+
+	result, err := s.versions.GetVersion(entityType, id, version)
+	if err != nil {
+		s.log.Error("error GetVersion in catalog_version_service.go", "err", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *catalogVersionService) currentStatus(entityType string, id uint) (string, error) {
+	switch entityType {
+	case models.CatalogCategory:
+		category, err := s.category.GetCategoryByID(id)
+		if err != nil {
+			return "", err
+		}
+		return category.Status, nil
+	case models.CatalogExercisePlan:
+		plan, err := s.plan.GetPlanByID(id)
+		if err != nil {
+			return "", err
+		}
+		return plan.Status, nil
+	case models.CatalogMealPlan:
+		mealPlan, err := s.mealPlan.GetMealPlanByID(id)
+		if err != nil {
+			return "", err
+		}
+		return mealPlan.Status, nil
+	}
+
+	return "", fmt.Errorf("unknown catalog entity type %q", entityType)
+}
+
+func (s *catalogVersionService) validatePublishable(entityType string, id uint) error {
+	switch entityType {
+	case models.CatalogCategory:
+		category, err := s.category.GetCategoryByID(id)
+		if err != nil {
+			return err
+		}
+		if category.Name == "" || category.Price <= 0 {
+			return errors.New("category needs a name and a price before publishing")
+		}
+		if len(category.ExercisePlans) == 0 && len(category.MealPlans) == 0 {
+			return errors.New("category needs at least one exercise or meal plan before publishing")
+		}
+	case models.CatalogExercisePlan:
+		plan, err := s.plan.GetPlanByID(id)
+		if err != nil {
+			return err
+		}
+		if len(plan.Exercises) == 0 {
+			return errors.New("exercise plan needs at least one exercise before publishing")
+		}
+	case models.CatalogMealPlan:
+		mealPlan, err := s.mealPlan.GetMealPlanByID(id)
+		if err != nil {
+			return err
+		}
+		if len(mealPlan.Meals) == 0 {
+			return errors.New("meal plan needs at least one meal before publishing")
+		}
+	default:
+		return fmt.Errorf("unknown catalog entity type %q", entityType)
+	}
+
+	return nil
+}
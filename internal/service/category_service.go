@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"healthy_body/internal/models"
 	"healthy_body/internal/repository"
 	"log/slog"
@@ -9,11 +10,12 @@ import (
 
 type CategoryServices interface {
 	CreateCategory(req models.CreateCategoryRequest) (*models.Categories, error)
-	GetCategoryList()([]models.Categories,error)
+	GetCategoryList(status string, ownerID *uint, sort string)([]models.Categories,error)
 	GetCategoryByID(id uint) (*models.Categories,error)
 	GetWithPlans(id uint) (*models.Categories, error)
 	UpdateCategory(id uint, req models.UpdateCategoryRequest) (*models.Categories, error)
 	DeleteCategory(id uint) error
+	CloneCategory(id uint) (*models.Categories, error)
 }
 
 type categoryServices struct {
@@ -42,10 +44,17 @@ func (c *categoryServices) CreateCategory(req models.CreateCategoryRequest) (*mo
 		return  nil , errors.New("empty description by your category")
 	}
 
+	if err := validateGoals(req.Goals); err != nil {
+		return nil, err
+	}
+
 	 category := &models.Categories{
 		Name: req.Name,
 		Description: req.Description,
 		Price: req.Price,
+		Status: models.StatusDraft,
+		OwnerID: req.OwnerID,
+		Goals: req.Goals,
 	 }
 
 	  if err:= c.category.Create(category); err != nil {
@@ -67,13 +76,17 @@ func (c *categoryServices) GetWithPlans(id uint) (*models.Categories, error) {
 }
 
 
-func (c *categoryServices) GetCategoryList()([]models.Categories,error){
-	list , err := c.category.List()
+func (c *categoryServices) GetCategoryList(status string, ownerID *uint, sort string)([]models.Categories,error){
+	list , err := c.category.List(status, ownerID, sort)
 	if err != nil {
 		c.log.Error("error GetList in category_service.go")
 		return nil, err
 	}
 
+	if err := c.attachRatings(list); err != nil {
+		return nil, err
+	}
+
 
 	return  list , nil
 }
@@ -85,6 +98,12 @@ func (c *categoryServices) GetCategoryByID(id uint) (*models.Categories,error) {
 		return  nil ,err
 	}
 
+	ratings, err := c.category.Ratings([]uint{category.ID})
+	if err != nil {
+		return nil, err
+	}
+	category.Rating = ratings[category.ID]
+
 	return  category, nil
 }
 
@@ -95,6 +114,14 @@ func (c *categoryServices) UpdateCategory(id uint, req models.UpdateCategoryRequ
 		return &models.Categories{} , err
 	}
 
+	if category.Status == models.StatusPublished {
+		return nil, ErrPublished
+	}
+
+	if err := validateGoals(req.Goals); err != nil {
+		return nil, err
+	}
+
 	c.Up(category, req)
 
 	if err := c.category.Update(category); err != nil {
@@ -107,6 +134,16 @@ func (c *categoryServices) UpdateCategory(id uint, req models.UpdateCategoryRequ
 
 
 func (c *categoryServices) DeleteCategory(id uint) error{
+	category, err := c.category.GetByID(id)
+	if err != nil {
+		c.log.Error("error DeleteCategory in category_service.go")
+		return err
+	}
+
+	if category.Status == models.StatusPublished {
+		return ErrPublished
+	}
+
 	if err:= c.category.Delete(id); err != nil {
 		c.log.Error("error UpdateCategory in category_service.go")
 		return err
@@ -115,6 +152,16 @@ func (c *categoryServices) DeleteCategory(id uint) error{
 	return  nil
 }
 
+func (c *categoryServices) CloneCategory(id uint) (*models.Categories, error) {
+	clone, err := c.category.Clone(id)
+	if err != nil {
+		c.log.Error("error CloneCategory in category_service.go")
+		return nil, err
+	}
+
+	return clone, nil
+}
+
 func(c *categoryServices) Up(cat *models.Categories, req models.UpdateCategoryRequest){
 	if req.Name != nil {
 		cat.Name = *req.Name
@@ -125,4 +172,39 @@ func(c *categoryServices) Up(cat *models.Categories, req models.UpdateCategoryRe
 	if req.Price != nil {
 		cat.Price = *req.Price
 	}
+	if req.OwnerID != nil {
+		cat.OwnerID = req.OwnerID
+	}
+	if req.Goals != nil {
+		cat.Goals = req.Goals
+	}
+}
+
+func validateGoals(goals []string) error {
+	for _, goal := range goals {
+		if !models.IsValidGoal(goal) {
+			return fmt.Errorf("unknown goal %q", goal)
+		}
+	}
+
+	return nil
+}
+
+func (c *categoryServices) attachRatings(list []models.Categories) error {
+	ids := make([]uint, 0, len(list))
+	for _, category := range list {
+		ids = append(ids, category.ID)
+	}
+
+	ratings, err := c.category.Ratings(ids)
+	if err != nil {
+		c.log.Error("error Ratings in category_service.go", "err", err)
+		return err
+	}
+
+	for i := range list {
+		list[i].Rating = ratings[list[i].ID]
+	}
+
+	return nil
 }
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"healthy_body/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// accrueCoachEarnings books the coach's share of every attributed item of an
+// order that has just been created.
+func accrueCoachEarnings(tx *gorm.DB, order *models.Order) error {
+	shares := map[uint]int{}
+	for _, item := range order.Items {
+		if item.CoachID == nil || item.FinalPrice <= 0 {
+			continue
+		}
+
+		share, ok := shares[*item.CoachID]
+		if !ok {
+			var err error
+			if share, err = revenueShare(tx, *item.CoachID); err != nil {
+				return err
+			}
+			shares[*item.CoachID] = share
+		}
+
+		if err := tx.Create(&models.CoachEarning{
+			CoachID:     *item.CoachID,
+			OrderID:     order.ID,
+			OrderItemID: item.ID,
+			Kind:        models.EarningAccrual,
+			Name:        item.Name,
+			Currency:    order.Currency,
+			Gross:       item.FinalPrice,
+			Share:       share,
+			Amount:      coachAmount(item.FinalPrice, share),
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reverseCoachEarnings books negative lines against the accruals of the order
+// items selected by itemIDs. Together they take back no more than refunded,
+// and no line takes back more of an accrual than earlier reversals left of it.
+func reverseCoachEarnings(tx *gorm.DB, itemIDs *gorm.DB, refunded int64) error {
+	var accruals []models.CoachEarning
+	if err := tx.Where("order_item_id IN (?) AND kind = ?", itemIDs, models.EarningAccrual).
+		Order("id").Find(&accruals).Error; err != nil {
+		return err
+	}
+
+	for _, accrual := range accruals {
+		if refunded <= 0 {
+			break
+		}
+
+		var reversed int64
+		if err := tx.Model(&models.CoachEarning{}).
+			Where("order_item_id = ? AND kind = ?", accrual.OrderItemID, models.EarningReversal).
+			Select("COALESCE(-SUM(gross), 0)").Scan(&reversed).Error; err != nil {
+			return err
+		}
+
+		gross := min(accrual.Gross-reversed, refunded)
+		if gross <= 0 {
+			continue
+		}
+		refunded -= gross
+
+		if err := tx.Create(&models.CoachEarning{
+			CoachID:     accrual.CoachID,
+			OrderID:     accrual.OrderID,
+			OrderItemID: accrual.OrderItemID,
+			Kind:        models.EarningReversal,
+			Name:        accrual.Name,
+			Currency:    accrual.Currency,
+			Gross:       -gross,
+			Share:       accrual.Share,
+			Amount:      -coachAmount(gross, accrual.Share),
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func revenueShare(tx *gorm.DB, coachID uint) (int, error) {
+	var shares []*int
+	if err := tx.Model(&models.CoachProfile{}).Where("user_id = ?", coachID).
+		Limit(1).Pluck("revenue_share", &shares).Error; err != nil {
+		return 0, err
+	}
+	if len(shares) == 0 || shares[0] == nil {
+		return models.DefaultRevenueShare, nil
+	}
+
+	return *shares[0], nil
+}
+
+// coachAmount is the coach's part of gross, rounded down to a minor unit.
+func coachAmount(gross int64, share int) int64 {
+	return gross * int64(share) / 100
+}
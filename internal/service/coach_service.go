@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCoachNotFound = errors.New("coach not found")
+	ErrNotOwner      = errors.New("only the owner coach or an admin can change this item")
+	ErrAdminOnly     = errors.New("only an admin can do this")
+	ErrInvalidPeriod = errors.New("period must be YYYY-MM")
+	ErrInvalidRange  = errors.New("from must be before to")
+	ErrInvalidShare  = errors.New("revenue share must be between 0 and 100")
+	ErrPeriodNotOver = errors.New("the period is not over yet")
+)
+
+type CoachService interface {
+	List() ([]models.CoachPage, error)
+	GetPage(userID uint) (*models.CoachPage, error)
+	UpdateProfile(userID uint, req models.UpdateCoachProfileRequest) (*models.CoachPage, error)
+	UploadPhoto(ctx context.Context, userID uint, body io.Reader) (*models.CoachPage, error)
+	Revenue(coachID uint, from, to time.Time) ([]models.CoachRevenue, error)
+
+	SetRevenueShare(coachID uint, share int) error
+	Earnings(coachID uint, from, to time.Time) ([]models.CoachEarning, error)
+	ExportEarnings(w io.Writer, coachID uint, from, to time.Time) error
+	Statement(coachID uint, period string) (*models.PayoutStatement, error)
+	ClosePeriod(coachID uint, period string) (*models.PayoutStatement, error)
+	MarkPaid(coachID, payoutID uint) (*models.CoachPayout, error)
+
+	CanEdit(actorID uint, targetType string, targetID uint) error
+	Author(actorID uint) (*uint, bool, error)
+}
+
+type coachService struct {
+	repo        repository.CoachRepository
+	users       repository.UserRepository
+	categories  repository.CategoryRepo
+	attachments AttachmentService
+	log         *slog.Logger
+}
+
+func NewCoachService(repo repository.CoachRepository, users repository.UserRepository, categories repository.CategoryRepo, attachments AttachmentService, log *slog.Logger) CoachService {
+	return &coachService{
+		repo:        repo,
+		users:       users,
+		categories:  categories,
+		attachments: attachments,
+		log:         log,
+	}
+}
+
+func (s *coachService) List() ([]models.CoachPage, error) {
+	profiles, err := s.repo.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]models.CoachPage, 0, len(profiles))
+	for i := range profiles {
+		pages = append(pages, coachPage(&profiles[i]))
+	}
+
+	return pages, nil
+}
+
+// GetPage is the public coach page: the profile, the published categories
+// the coach owns and a rating over all their reviews.
+func (s *coachService) GetPage(userID uint) (*models.CoachPage, error) {
+	profile, err := s.coachProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.repo.OwnedCategories(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(categories))
+	for _, category := range categories {
+		ids = append(ids, category.ID)
+	}
+	ratings, err := s.categories.Ratings(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	page := coachPage(profile)
+	for i := range categories {
+		rating := ratings[categories[i].ID]
+		categories[i].Rating = rating
+
+		page.Rating.Count += rating.Count
+		page.Rating.Sum += rating.Sum
+		page.Rating.Stars1 += rating.Stars1
+		page.Rating.Stars2 += rating.Stars2
+		page.Rating.Stars3 += rating.Stars3
+		page.Rating.Stars4 += rating.Stars4
+		page.Rating.Stars5 += rating.Stars5
+	}
+	if page.Rating.Count > 0 {
+		page.Rating.Average = float64(page.Rating.Sum) / float64(page.Rating.Count)
+	}
+	page.Rating.FillHistogram()
+	page.Categories = categories
+
+	return &page, nil
+}
+
+func (s *coachService) UpdateProfile(userID uint, req models.UpdateCoachProfileRequest) (*models.CoachPage, error) {
+	profile, err := s.coachProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Bio != nil {
+		profile.Bio = strings.TrimSpace(*req.Bio)
+	}
+	if req.Specialties != nil {
+		specialties := make([]string, 0, len(req.Specialties))
+		for _, specialty := range req.Specialties {
+			if specialty = strings.TrimSpace(specialty); specialty != "" {
+				specialties = append(specialties, specialty)
+			}
+		}
+		profile.Specialties = specialties
+	}
+
+	if err := s.repo.SaveProfile(profile); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("coach profile updated", "user_id", userID)
+	page := coachPage(profile)
+	return &page, nil
+}
+
+func (s *coachService) UploadPhoto(ctx context.Context, userID uint, body io.Reader) (*models.CoachPage, error) {
+	profile, err := s.coachProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	photo, err := s.attachments.UploadUserPhoto(ctx, userID, body)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.PhotoID = &photo.ID
+	if err := s.repo.SaveProfile(profile); err != nil {
+		return nil, err
+	}
+
+	page := coachPage(profile)
+	page.Photo = photo
+	return &page, nil
+}
+
+func (s *coachService) Revenue(coachID uint, from, to time.Time) ([]models.CoachRevenue, error) {
+	if _, err := s.coachProfile(coachID); err != nil {
+		return nil, err
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidRange
+	}
+
+	revenue, err := s.repo.Revenue(coachID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for i := range revenue {
+		revenue[i].Amount = models.Money{Amount: revenue[i].Total, Currency: revenue[i].Currency}.String()
+	}
+
+	return revenue, nil
+}
+
+func (s *coachService) SetRevenueShare(coachID uint, share int) error {
+	if _, err := s.coachProfile(coachID); err != nil {
+		return err
+	}
+	if share < 0 || share > 100 {
+		return ErrInvalidShare
+	}
+
+	if err := s.repo.SetRevenueShare(coachID, share); err != nil {
+		return err
+	}
+
+	s.log.Info("coach revenue share set", "coach_id", coachID, "share", share)
+	return nil
+}
+
+func (s *coachService) Earnings(coachID uint, from, to time.Time) ([]models.CoachEarning, error) {
+	if _, err := s.coachProfile(coachID); err != nil {
+		return nil, err
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidRange
+	}
+
+	return s.repo.Earnings(coachID, from, to)
+}
+
+// ExportEarnings writes the coach ledger for the period as CSV, amounts in
+// major units.
+func (s *coachService) ExportEarnings(w io.Writer, coachID uint, from, to time.Time) error {
+	earnings, err := s.Earnings(coachID, from, to)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"date", "order_id", "order_item_id", "item", "kind", "currency", "gross", "share", "amount", "payout_id",
+	}); err != nil {
+		return err
+	}
+
+	for _, earning := range earnings {
+		payoutID := ""
+		if earning.PayoutID != nil {
+			payoutID = strconv.FormatUint(uint64(*earning.PayoutID), 10)
+		}
+
+		if err := writer.Write([]string{
+			earning.CreatedAt.UTC().Format(time.DateOnly),
+			strconv.FormatUint(uint64(earning.OrderID), 10),
+			strconv.FormatUint(uint64(earning.OrderItemID), 10),
+			earning.Name,
+			earning.Kind,
+			earning.Currency,
+			majorAmount(earning.Gross, earning.Currency),
+			strconv.Itoa(earning.Share),
+			majorAmount(earning.Amount, earning.Currency),
+			payoutID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Statement is the payout statement for a month: the stored payouts once the
+// month is closed, otherwise a preview of what closing it would pay.
+func (s *coachService) Statement(coachID uint, period string) (*models.PayoutStatement, error) {
+	profile, err := s.coachProfile(coachID)
+	if err != nil {
+		return nil, err
+	}
+	_, end, err := payoutPeriod(period)
+	if err != nil {
+		return nil, err
+	}
+
+	payouts, entries, err := s.repo.Statement(coachID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &models.PayoutStatement{
+		CoachID:      coachID,
+		Period:       period,
+		Closed:       len(payouts) > 0,
+		RevenueShare: profileShare(profile),
+		Payouts:      payouts,
+		Entries:      entries,
+	}
+	if !statement.Closed {
+		if statement.Entries, err = s.repo.OpenEarnings(coachID, end); err != nil {
+			return nil, err
+		}
+		statement.Payouts = models.NewPayouts(coachID, period, statement.Entries)
+	}
+	if statement.Entries == nil {
+		statement.Entries = []models.CoachEarning{}
+	}
+
+	return statement, nil
+}
+
+// ClosePeriod books the payouts of a finished month.
+func (s *coachService) ClosePeriod(coachID uint, period string) (*models.PayoutStatement, error) {
+	if _, err := s.coachProfile(coachID); err != nil {
+		return nil, err
+	}
+	_, end, err := payoutPeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	if end.After(time.Now()) {
+		return nil, ErrPeriodNotOver
+	}
+
+	payouts, err := s.repo.ClosePeriod(coachID, period, end)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("coach payout period closed", "coach_id", coachID, "period", period, "payouts", len(payouts))
+	return s.Statement(coachID, period)
+}
+
+func (s *coachService) MarkPaid(coachID, payoutID uint) (*models.CoachPayout, error) {
+	payout, err := s.repo.MarkPaid(coachID, payoutID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("coach payout paid", "coach_id", coachID, "payout_id", payoutID,
+		"amount", models.Money{Amount: payout.Amount, Currency: payout.Currency}.String())
+	return payout, nil
+}
+
+// CanEdit lets admins change any catalog item and coaches only the items they
+// own.
+func (s *coachService) CanEdit(actorID uint, targetType string, targetID uint) error {
+	actor, err := s.users.GetUserByID(actorID)
+	if err != nil {
+		return ErrNotOwner
+	}
+	if actor.Role == models.RoleAdmin {
+		return nil
+	}
+
+	ownerID, err := s.repo.Owner(targetType, targetID)
+	if err != nil {
+		return err
+	}
+	if actor.Role != models.RoleCoach || ownerID != actor.ID {
+		s.log.Warn("catalog change refused",
+			"actor_id", actorID,
+			"type", targetType,
+			"id", targetID,
+			"owner_id", ownerID)
+		return ErrNotOwner
+	}
+
+	return nil
+}
+
+// Author checks that the actor may create catalog items. New items belong to
+// the coach who creates them; admins may assign any owner.
+func (s *coachService) Author(actorID uint) (*uint, bool, error) {
+	actor, err := s.users.GetUserByID(actorID)
+	if err != nil {
+		return nil, false, ErrNotOwner
+	}
+
+	switch actor.Role {
+	case models.RoleAdmin:
+		return nil, true, nil
+	case models.RoleCoach:
+		return &actor.ID, false, nil
+	}
+
+	return nil, false, ErrNotOwner
+}
+
+func (s *coachService) coachProfile(userID uint) (*models.CoachProfile, error) {
+	user, err := s.users.GetUserByID(userID)
+	if err != nil || user.Role != models.RoleCoach {
+		return nil, ErrCoachNotFound
+	}
+
+	profile, err := s.repo.GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+	profile.User = user
+
+	return profile, nil
+}
+
+func profileShare(profile *models.CoachProfile) int {
+	if profile.RevenueShare == nil {
+		return models.DefaultRevenueShare
+	}
+
+	return *profile.RevenueShare
+}
+
+// payoutPeriod parses a YYYY-MM month into its UTC bounds.
+func payoutPeriod(period string) (time.Time, time.Time, error) {
+	from, err := time.Parse("2006-01", period)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidPeriod
+	}
+
+	return from, from.AddDate(0, 1, 0), nil
+}
+
+// majorAmount formats minor units as a plain decimal, without the currency.
+func majorAmount(amount int64, currency string) string {
+	return strings.TrimSuffix(models.Money{Amount: amount, Currency: currency}.String(), " "+currency)
+}
+
+func coachPage(profile *models.CoachProfile) models.CoachPage {
+	page := models.CoachPage{
+		ID:          profile.UserID,
+		Bio:         profile.Bio,
+		Specialties: profile.Specialties,
+	}
+	if profile.User != nil {
+		page.Name = profile.User.Name
+	}
+	if profile.Photo != nil {
+		photo := attachmentView(profile.Photo)
+		page.Photo = &photo
+	}
+	if page.Specialties == nil {
+		page.Specialties = []string{}
+	}
+	page.Rating.FillHistogram()
+
+	return page
+}
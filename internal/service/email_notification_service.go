@@ -1,14 +1,14 @@
 package service
 
 import (
-	"fmt"
 	"healthy_body/internal/models"
+	"healthy_body/internal/templates"
 	"log/slog"
 
 	gomail "gopkg.in/gomail.v2"
 )
 
-type EmailNotificationService struct {
+type EmailChannel struct {
 	fromEmail string
 	fromPass  string
 	smtpHost  string
@@ -16,13 +16,13 @@ type EmailNotificationService struct {
 	logger    *slog.Logger
 }
 
-func NewEmailNotificationService(
+func NewEmailChannel(
 	fromEmail, fromPass, smtpHost string,
 	smtpPort int,
 	logger *slog.Logger,
-) *EmailNotificationService {
+) *EmailChannel {
 
-	return &EmailNotificationService{
+	return &EmailChannel{
 		fromEmail: fromEmail,
 		fromPass:  fromPass,
 		smtpHost:  smtpHost,
@@ -31,24 +31,25 @@ func NewEmailNotificationService(
 	}
 }
 
-func (s *EmailNotificationService) SendPaymentSuccess(user *models.User, category *models.Categories) error {
+func (s *EmailChannel) Name() string {
+	return models.ChannelEmail
+}
+
+func (s *EmailChannel) Send(recipient NotificationRecipient, message *templates.Message) error {
 
-	if user.Email == "" {
-		s.logger.Warn("у пользователя нет email", "user_id", user.ID)
+	if recipient.Email == "" {
+		s.logger.Warn("у пользователя нет email", "user_id", recipient.UserID)
 		return nil
 	}
 
 	msg := gomail.NewMessage()
 	msg.SetHeader("From", s.fromEmail)
-	msg.SetHeader("To", user.Email)
-	msg.SetHeader("Subject", "Оплата прошла успешно")
-	msg.SetBody("text/plain",
-		fmt.Sprintf(
-			"Привет, %s!\n\nВы успешно оплатили категорию: %s.\nСпасибо, что пользуетесь нашим сервисом!",
-			user.Name,
-			category.Name,
-		),
-	)
+	msg.SetHeader("To", recipient.Email)
+	msg.SetHeader("Subject", message.Subject)
+	msg.SetBody("text/plain", message.Text)
+	if message.HTML != "" {
+		msg.AddAlternative("text/html", message.HTML)
+	}
 
 	dialer := gomail.NewDialer(s.smtpHost, s.smtpPort, s.fromEmail, s.fromPass)
 
@@ -60,8 +61,8 @@ func (s *EmailNotificationService) SendPaymentSuccess(user *models.User, categor
 	}
 
 	s.logger.Info("email уведомление отправлено",
-		"to", user.Email,
-		"category", category.Name,
+		"to", recipient.Email,
+		"event_type", recipient.EventType,
 	)
 
 	return nil
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"fmt"
+	"healthy_body/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	unknownLabel = "unknown"
+
+	pushPullImbalanceRatio = 1.5
+	maxWeeklySetsPerMuscle = 25
+)
+
+var pushMuscleGroups = map[string]bool{
+	"chest":     true,
+	"shoulders": true,
+	"triceps":   true,
+	"грудь":     true,
+	"плечи":     true,
+	"трицепс":   true,
+}
+
+var pullMuscleGroups = map[string]bool{
+	"back":   true,
+	"lats":   true,
+	"biceps": true,
+	"спина":  true,
+	"бицепс": true,
+}
+
+var weekDays = map[string]string{
+	"monday":      "monday",
+	"mon":         "monday",
+	"понедельник": "monday",
+	"tuesday":     "tuesday",
+	"tue":         "tuesday",
+	"вторник":     "tuesday",
+	"wednesday":   "wednesday",
+	"wed":         "wednesday",
+	"среда":       "wednesday",
+	"thursday":    "thursday",
+	"thu":         "thursday",
+	"четверг":     "thursday",
+	"friday":      "friday",
+	"fri":         "friday",
+	"пятница":     "friday",
+	"saturday":    "saturday",
+	"sat":         "saturday",
+	"суббота":     "saturday",
+	"sunday":      "sunday",
+	"sun":         "sunday",
+	"воскресенье": "sunday",
+}
+
+func (e *exercisePlanServices) GetPlanAnalytics(id uint) (*models.ExercisePlanAnalytics, error) {
+	plan, err := e.exerciseRepo.GetByIDExercisePlan(id)
+	if err != nil {
+		e.log.Error("error GetPlanAnalytics function in exercise_analytics.go", "plan_id", id)
+		return nil, err
+	}
+
+	return analyzeExercisePlan(plan), nil
+}
+
+func analyzeExercisePlan(plan *models.ExercisePlan) *models.ExercisePlanAnalytics {
+	result := &models.ExercisePlanAnalytics{
+		PlanID:             plan.ID,
+		DurationWeeks:      plan.DurationWeeks,
+		WeeklySetsByMuscle: make(map[string]int),
+		ExercisesByDay:     make(map[string]int),
+		Warnings:           []string{},
+	}
+
+	unknownDays := 0
+	unparsedDurations := 0
+
+	for _, item := range plan.Exercises {
+		muscle := normalizeMuscleGroup(item.MuscleGroup)
+		result.WeeklySetsByMuscle[muscle] += item.Sets
+
+		switch {
+		case pushMuscleGroups[muscle]:
+			result.PushSets += item.Sets
+		case pullMuscleGroups[muscle]:
+			result.PullSets += item.Sets
+		}
+
+		minutes, ok := parseDurationMinutes(item.DurationMinutes)
+		if !ok {
+			unparsedDurations++
+		}
+		result.WeeklyMinutes += minutes
+
+		day, ok := normalizeWeekDay(item.DayOfWeek)
+		if !ok {
+			unknownDays++
+		}
+		result.ExercisesByDay[day]++
+	}
+
+	for day := range result.ExercisesByDay {
+		if _, ok := weekDays[day]; ok {
+			result.TrainingDaysPerWeek++
+		}
+	}
+	result.RestDaysPerWeek = 7 - result.TrainingDaysPerWeek
+	result.TotalMinutes = result.WeeklyMinutes * plan.DurationWeeks
+
+	result.Warnings = planWarnings(result, len(plan.Exercises), unknownDays, unparsedDurations)
+
+	return result
+}
+
+func planWarnings(a *models.ExercisePlanAnalytics, items, unknownDays, unparsedDurations int) []string {
+	warnings := []string{}
+
+	if items == 0 {
+		return append(warnings, "plan has no exercises")
+	}
+
+	if a.TrainingDaysPerWeek >= 7 {
+		warnings = append(warnings, "no rest day: every day of the week has training")
+	}
+
+	if a.PushSets > 0 || a.PullSets > 0 {
+		push, pull := float64(a.PushSets), float64(a.PullSets)
+		if pull == 0 || push/pull > pushPullImbalanceRatio {
+			warnings = append(warnings, fmt.Sprintf("push/pull imbalance: %d push sets vs %d pull sets", a.PushSets, a.PullSets))
+		} else if push == 0 || pull/push > pushPullImbalanceRatio {
+			warnings = append(warnings, fmt.Sprintf("push/pull imbalance: %d pull sets vs %d push sets", a.PullSets, a.PushSets))
+		}
+	}
+
+	muscles := make([]string, 0, len(a.WeeklySetsByMuscle))
+	for muscle := range a.WeeklySetsByMuscle {
+		muscles = append(muscles, muscle)
+	}
+	sort.Strings(muscles)
+
+	for _, muscle := range muscles {
+		if sets := a.WeeklySetsByMuscle[muscle]; muscle != unknownLabel && sets > maxWeeklySetsPerMuscle {
+			warnings = append(warnings, fmt.Sprintf("high volume: %d weekly sets for %s", sets, muscle))
+		}
+	}
+
+	if sets := a.WeeklySetsByMuscle[unknownLabel]; sets > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d weekly sets have no muscle group", sets))
+	}
+
+	if unknownDays > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d exercises have an unrecognized day of week", unknownDays))
+	}
+
+	if unparsedDurations > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d exercises have an unreadable duration", unparsedDurations))
+	}
+
+	return warnings
+}
+
+func normalizeMuscleGroup(muscle string) string {
+	muscle = strings.ToLower(strings.TrimSpace(muscle))
+	if muscle == "" {
+		return unknownLabel
+	}
+
+	return muscle
+}
+
+func normalizeWeekDay(day string) (string, bool) {
+	day = strings.ToLower(strings.TrimSpace(day))
+	if canonical, ok := weekDays[day]; ok {
+		return canonical, true
+	}
+
+	if day == "" {
+		return unknownLabel, false
+	}
+
+	return day, false
+}
+
+func parseDurationMinutes(value string) (int, bool) {
+	digits := strings.TrimSpace(value)
+	end := strings.IndexFunc(digits, func(r rune) bool { return !unicode.IsDigit(r) })
+	if end >= 0 {
+		digits = digits[:end]
+	}
+
+	minutes, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
+	}
+
+	return minutes, true
+}
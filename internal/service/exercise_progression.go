@@ -0,0 +1,223 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"math"
+)
+
+const (
+	maxLoadIncreasePercent = 50
+	loadRoundingKg         = 0.5
+)
+
+func (e *exercisePlanServices) CreateProgressionRule(planID uint, req models.CreateProgressionRuleRequest) (*models.ProgressionRule, error) {
+	plan, err := e.exerciseRepo.GetByIDExercisePlan(planID)
+	if err != nil {
+		e.log.Error("error CreateProgressionRule function in exercise_progression.go")
+		return nil, err
+	}
+
+	if plan.Status == models.StatusPublished {
+		return nil, ErrPublished
+	}
+
+	rule := &models.ProgressionRule{
+		Type:               req.Type,
+		StartWeek:          req.StartWeek,
+		EndWeek:            req.EndWeek,
+		EveryWeeks:         req.EveryWeeks,
+		Value:              req.Value,
+		ExercisePlanItemID: req.ExercisePlanItemID,
+		ExercisePlanID:     plan.ID,
+	}
+
+	if err := validateProgressionRule(plan, rule); err != nil {
+		e.log.Error("error validate progression rule in exercise_progression.go", "err", err)
+		return nil, err
+	}
+
+	if err := e.exerciseRepo.CreateProgressionRule(rule); err != nil {
+		e.log.Error("error CreateProgressionRule function in exercise_progression.go")
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (e *exercisePlanServices) DeleteProgressionRule(planID, ruleID uint) error {
+	rule, err := e.exerciseRepo.GetProgressionRule(ruleID)
+	if err != nil {
+		e.log.Error("error DeleteProgressionRule function in exercise_progression.go")
+		return err
+	}
+
+	if rule.ExercisePlanID != planID {
+		return errors.New("progression rule does not belong to this plan")
+	}
+
+	if err := e.editablePlan(planID); err != nil {
+		return err
+	}
+
+	if err := e.exerciseRepo.DeleteProgressionRule(ruleID); err != nil {
+		e.log.Error("error DeleteProgressionRule function in exercise_progression.go")
+		return err
+	}
+
+	return nil
+}
+
+func (e *exercisePlanServices) GetProgression(planID uint) ([]models.WeekPrescription, error) {
+	plan, err := e.exerciseRepo.GetByIDExercisePlan(planID)
+	if err != nil {
+		e.log.Error("error GetProgression function in exercise_progression.go")
+		return nil, err
+	}
+
+	return expandProgression(plan), nil
+}
+
+func (e *exercisePlanServices) validateRulesFit(rules []models.ProgressionRule, durationWeeks int) error {
+	for _, rule := range rules {
+		if rule.StartWeek > durationWeeks || rule.EndWeek > durationWeeks {
+			return fmt.Errorf("progression rule %d does not fit into %d weeks", rule.ID, durationWeeks)
+		}
+	}
+
+	return nil
+}
+
+func validateProgressionRule(plan *models.ExercisePlan, rule *models.ProgressionRule) error {
+	switch rule.Type {
+	case models.ProgressionLinearReps, models.ProgressionPercentLoad, models.ProgressionDeload:
+	default:
+		return fmt.Errorf("unknown progression type %q", rule.Type)
+	}
+
+	if rule.StartWeek < 1 || rule.StartWeek > plan.DurationWeeks {
+		return fmt.Errorf("start week must be between 1 and %d", plan.DurationWeeks)
+	}
+
+	if rule.EndWeek != 0 && (rule.EndWeek < rule.StartWeek || rule.EndWeek > plan.DurationWeeks) {
+		return fmt.Errorf("end week must be between start week and %d", plan.DurationWeeks)
+	}
+
+	if rule.EveryWeeks < 0 {
+		return errors.New("every weeks can not be negative")
+	}
+
+	if rule.Value <= 0 {
+		return errors.New("progression value must be greater than zero")
+	}
+
+	switch rule.Type {
+	case models.ProgressionLinearReps:
+		if rule.Value != math.Trunc(rule.Value) {
+			return errors.New("reps increase must be a whole number")
+		}
+	case models.ProgressionPercentLoad:
+		if rule.Value > maxLoadIncreasePercent {
+			return fmt.Errorf("load increase can not exceed %d percent per step", maxLoadIncreasePercent)
+		}
+	case models.ProgressionDeload:
+		if rule.Value >= 100 {
+			return errors.New("deload reduction must be less than 100 percent")
+		}
+	}
+
+	if rule.ExercisePlanItemID != nil {
+		found := false
+		for _, item := range plan.Exercises {
+			if item.ID == *rule.ExercisePlanItemID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New("exercise plan item does not belong to this plan")
+		}
+	}
+
+	return nil
+}
+
+func expandProgression(plan *models.ExercisePlan) []models.WeekPrescription {
+	weeks := make([]models.WeekPrescription, 0, plan.DurationWeeks)
+
+	for week := 1; week <= plan.DurationWeeks; week++ {
+		prescription := models.WeekPrescription{
+			Week:  week,
+			Items: make([]models.ItemPrescription, 0, len(plan.Exercises)),
+		}
+
+		for _, item := range plan.Exercises {
+			current := models.ItemPrescription{
+				ExercisePlanItemID: item.ID,
+				Name:               item.Name,
+				DayOfWeek:          item.DayOfWeek,
+				Sets:               item.Sets,
+				Reps:               item.Reps,
+				WeightKg:           item.WeightKg,
+			}
+
+			deload := 0.0
+			for _, rule := range plan.ProgressionRules {
+				if rule.ExercisePlanItemID != nil && *rule.ExercisePlanItemID != item.ID {
+					continue
+				}
+
+				switch rule.Type {
+				case models.ProgressionLinearReps:
+					current.Reps += int(rule.Value) * progressionSteps(rule, week)
+				case models.ProgressionPercentLoad:
+					current.WeightKg *= math.Pow(1+rule.Value/100, float64(progressionSteps(rule, week)))
+				case models.ProgressionDeload:
+					if isDeloadWeek(rule, week) && rule.Value > deload {
+						deload = rule.Value
+					}
+				}
+			}
+
+			if deload > 0 {
+				prescription.Deload = true
+				current.Sets = max(1, int(math.Round(float64(current.Sets)*(1-deload/100))))
+				current.WeightKg *= 1 - deload/100
+			}
+			current.WeightKg = math.Round(current.WeightKg/loadRoundingKg) * loadRoundingKg
+
+			prescription.Items = append(prescription.Items, current)
+		}
+
+		weeks = append(weeks, prescription)
+	}
+
+	return weeks
+}
+
+func progressionSteps(rule models.ProgressionRule, week int) int {
+	if week < rule.StartWeek {
+		return 0
+	}
+
+	if rule.EndWeek != 0 && week > rule.EndWeek {
+		week = rule.EndWeek
+	}
+
+	every := max(rule.EveryWeeks, 1)
+
+	return (week-rule.StartWeek)/every + 1
+}
+
+func isDeloadWeek(rule models.ProgressionRule, week int) bool {
+	if week < rule.StartWeek || (rule.EndWeek != 0 && week > rule.EndWeek) {
+		return false
+	}
+
+	if rule.EveryWeeks == 0 {
+		return rule.EndWeek != 0 || week == rule.StartWeek
+	}
+
+	return (week-rule.StartWeek)%rule.EveryWeeks == 0
+}
@@ -14,12 +14,19 @@ type ExercisePlanServices interface {
 	GetListPlans() ([]models.ExercisePlan, error)
 	UpdatePlan(id uint, req models.UpdateExercesicePlanRequest) (*models.ExercisePlan, error)
 	DeletePlan(id uint) error
+	ClonePlan(id uint, req models.ClonePlanRequest) (*models.ExercisePlan, error)
 
 	CreatePlanItem(req models.CreateExercisePlanItemRequest) (*models.ExercisePlanItem, error)
 	GetAllPlanItem() ([]models.ExercisePlanItem, error)
 	GetByIDPlanItem(id uint) (*models.ExercisePlanItem, error)
 	UpdatePlanItem(id uint, req models.UpdateExercisePlanItemRequest) (*models.ExercisePlanItem, error)
 	DeletePlanItem(id uint) error
+
+	GetPlanAnalytics(id uint) (*models.ExercisePlanAnalytics, error)
+
+	CreateProgressionRule(planID uint, req models.CreateProgressionRuleRequest) (*models.ProgressionRule, error)
+	DeleteProgressionRule(planID, ruleID uint) error
+	GetProgression(planID uint) ([]models.WeekPrescription, error)
 }
 
 type exercisePlanServices struct {
@@ -52,6 +59,8 @@ func (e *exercisePlanServices) CreatePlan(req models.CreateExercesicePlanRequest
 		Description: req.Description,
 		DurationWeeks: req.DurationWeeks,
 		CategoriesID:    req.CategoryID,
+		Status:        models.StatusDraft,
+		OwnerID:       req.OwnerID,
 	}
 
 	if err := e.exerciseRepo.CreateExercisePlan(exercise); err != nil {
@@ -99,7 +108,15 @@ func (e *exercisePlanServices) UpdatePlan(id uint, req models.UpdateExercesicePl
 		return nil, err
 	}
 
+	if plan.Status == models.StatusPublished {
+		return nil, ErrPublished
+	}
+
 	if req.DurationWeeks != nil {
+		if err := e.validateRulesFit(plan.ProgressionRules, *req.DurationWeeks); err != nil {
+			e.log.Error("error UpdatePlan function in exercise_service.go")
+			return nil, err
+		}
 		plan.DurationWeeks = *req.DurationWeeks
 	}
 
@@ -120,6 +137,10 @@ func (e *exercisePlanServices) UpdatePlan(id uint, req models.UpdateExercesicePl
 }
 
 func (e *exercisePlanServices) DeletePlan(id uint) error {
+	if err := e.editablePlan(id); err != nil {
+		return err
+	}
+
 	if err := e.exerciseRepo.DeleteExercisePlan(id); err != nil {
 		e.log.Error("error DeletePlan function in exercise_service.go")
 		return err
@@ -128,6 +149,22 @@ func (e *exercisePlanServices) DeletePlan(id uint) error {
 	return nil
 }
 
+func (e *exercisePlanServices) ClonePlan(id uint, req models.ClonePlanRequest) (*models.ExercisePlan, error) {
+	if req.CategoriesID != nil {
+		if _, err := e.category.GetCategoryByID(*req.CategoriesID); err != nil {
+			e.log.Error("error GetCategoryByID function in exercise_service.go")
+			return nil, err
+		}
+	}
+
+	plan, err := e.exerciseRepo.CloneExercisePlan(id, req.CategoriesID)
+	if err != nil {
+		e.log.Error("error ClonePlan function in exercise_service.go")
+		return nil, err
+	}
+
+	return plan, nil
+}
 
 func (e *exercisePlanServices) CreatePlanItem(req models.CreateExercisePlanItemRequest) (*models.ExercisePlanItem, error) {
 	if err := e.validate(req); err != nil {
@@ -135,7 +172,7 @@ func (e *exercisePlanServices) CreatePlanItem(req models.CreateExercisePlanItemR
 		return nil, err
 	}
 
-	if _, err := e.exerciseRepo.GetByIDExercisePlanForNotPreload(req.ExercisePlanID); err != nil {
+	if err := e.editablePlan(req.ExercisePlanID); err != nil {
 		e.log.Error("error CreatePlanItem function in exercise_service.go")
 		return nil, err
 	}
@@ -147,6 +184,8 @@ func (e *exercisePlanServices) CreatePlanItem(req models.CreateExercisePlanItemR
 		EquipmentNeeded: req.EquipmentNeeded,
 		DurationMinutes: req.DurationMinutes,
 		DayOfWeek:       req.DayOfWeek,
+		MuscleGroup:     req.MuscleGroup,
+		WeightKg:        req.WeightKg,
 		ExercisePlanID:  req.ExercisePlanID,
 	}
 
@@ -185,6 +224,10 @@ func (e *exercisePlanServices) UpdatePlanItem(id uint, req models.UpdateExercise
 		return nil, err
 	}
 
+	if err := e.editablePlan(item.ExercisePlanID); err != nil {
+		return nil, err
+	}
+
 	e.up(item, req)
 
 	if err := e.exerciseRepo.UpdateExercisePlanItem(item); err != nil {
@@ -196,6 +239,16 @@ func (e *exercisePlanServices) UpdatePlanItem(id uint, req models.UpdateExercise
 }
 
 func (e *exercisePlanServices) DeletePlanItem(id uint) error {
+	item, err := e.exerciseRepo.GetByIDExercisePlanItem(id)
+	if err != nil {
+		e.log.Error("error DeletePlanItem function in exercise_service.go")
+		return err
+	}
+
+	if err := e.editablePlan(item.ExercisePlanID); err != nil {
+		return err
+	}
+
 	if err := e.exerciseRepo.DeleteExercisePlanItem(id); err != nil {
 		e.log.Error("error DeletePlanItem function in exercise_service.go")
 		return err
@@ -204,6 +257,21 @@ func (e *exercisePlanServices) DeletePlanItem(id uint) error {
 	return nil
 }
 
+// editablePlan guards everything hanging off a plan: items and progression
+// rules of a published plan are as read-only as the plan itself.
+func (e *exercisePlanServices) editablePlan(id uint) error {
+	plan, err := e.exerciseRepo.GetByIDExercisePlanForNotPreload(id)
+	if err != nil {
+		return err
+	}
+
+	if plan.Status == models.StatusPublished {
+		return ErrPublished
+	}
+
+	return nil
+}
+
 func (r *exercisePlanServices) validate(req models.CreateExercisePlanItemRequest) error {
 	if req.Name == "" {
 		return errors.New("name plan item is null")
@@ -228,6 +296,10 @@ func (r *exercisePlanServices) validate(req models.CreateExercisePlanItemRequest
 		return errors.New("equipmentNeeded plan item is null")
 	}
 
+	if req.WeightKg < 0 {
+		return errors.New("weightKg plan item is negative")
+	}
+
 	return nil
 }
 
@@ -256,4 +328,12 @@ func (r *exercisePlanServices) up(item *models.ExercisePlanItem, req models.Upda
 		item.DayOfWeek = *req.DayOfWeek
 	}
 
+	if req.MuscleGroup != nil {
+		item.MuscleGroup = *req.MuscleGroup
+	}
+
+	if req.WeightKg != nil {
+		item.WeightKg = *req.WeightKg
+	}
+
 }
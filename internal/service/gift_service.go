@@ -0,0 +1,433 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const giftLifetime = 30 * 24 * time.Hour
+
+type GiftService interface {
+	Send(senderID uint, req models.CreateGiftRequest) (*models.Gift, error)
+	Accept(giftID, userID uint) (*models.Gift, error)
+	Redeem(code string, userID uint) (*models.Gift, error)
+	Decline(giftID, userID uint) (*models.Gift, error)
+	ExpirePending() (int, error)
+	RunExpiry(ctx context.Context, interval time.Duration)
+	History(userID uint) (*models.GiftHistory, error)
+}
+
+type giftService struct {
+	repo  repository.GiftRepository
+	users repository.UserRepository
+	db    *gorm.DB
+	log   *slog.Logger
+}
+
+func NewGiftService(repo repository.GiftRepository, users repository.UserRepository, db *gorm.DB, log *slog.Logger) GiftService {
+	return &giftService{
+		repo:  repo,
+		users: users,
+		db:    db,
+		log:   log,
+	}
+}
+
+func (s *giftService) Send(senderID uint, req models.CreateGiftRequest) (*models.Gift, error) {
+	req.RecipientEmail = strings.TrimSpace(req.RecipientEmail)
+	if req.RecipientID == nil && req.RecipientEmail == "" {
+		return nil, errors.New("укажите получателя подарка: recipient_id или recipient_email")
+	}
+
+	if req.RecipientID != nil && *req.RecipientID == senderID {
+		return nil, errors.New("нельзя подарить категорию самому себе")
+	}
+
+	code, err := newGiftCode()
+	if err != nil {
+		return nil, err
+	}
+
+	var gift *models.Gift
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var sender models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&sender, senderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("пользователь не найден")
+			}
+			return fmt.Errorf("ошибка при поиске пользователя %w", err)
+		}
+
+		var recipient *models.User
+		if req.RecipientID != nil {
+			var user models.User
+			if err := tx.First(&user, *req.RecipientID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("получатель не найден")
+				}
+				return fmt.Errorf("ошибка при поиске получателя %w", err)
+			}
+			recipient = &user
+		} else {
+			var user models.User
+			err := tx.Where("email = ?", req.RecipientEmail).First(&user).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("ошибка при поиске получателя %w", err)
+			}
+			if err == nil {
+				recipient = &user
+			}
+		}
+
+		if recipient != nil && recipient.ID == sender.ID {
+			return fmt.Errorf("нельзя подарить категорию самому себе")
+		}
+
+		var category models.Categories
+		if err := tx.First(&category, req.CategoriesID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("категория не найдена")
+			}
+			return fmt.Errorf("ошибка при поиске категории %w", err)
+		}
+
+		if category.Status != models.StatusPublished {
+			return fmt.Errorf("категория недоступна для покупки")
+		}
+
+		currency := models.NormalizeCurrency(sender.Currency)
+		price, err := priceFor(tx, models.TargetCategory, category.ID, category.Price, currency)
+		if err != nil {
+			return err
+		}
+
+		if err := debitWallet(tx, &sender, price, currency); err != nil {
+			s.log.Warn("Не удалось списать средства", "user_id", sender.ID, "error", err.Error())
+			return err
+		}
+
+		gift = &models.Gift{
+			Code:            code,
+			SenderID:        sender.ID,
+			RecipientEmail:  req.RecipientEmail,
+			CategoriesID:    category.ID,
+			CategoryVersion: category.Version,
+			Currency:        currency,
+			Amount:          price,
+			Message:         req.Message,
+			Status:          models.GiftPending,
+			ExpiresAt:       time.Now().Add(giftLifetime),
+		}
+		event := models.NotificationEvent{
+			EventType: models.EventGiftReceived,
+			Email:     req.RecipientEmail,
+			Data: map[string]any{
+				"sender_name":   sender.Name,
+				"category_id":   category.ID,
+				"category_name": category.Name,
+				"message":       req.Message,
+				"code":          code,
+				"expires_at":    gift.ExpiresAt.Format("02.01.2006"),
+			},
+		}
+		if recipient != nil {
+			gift.RecipientID = &recipient.ID
+			event.UserID = recipient.ID
+			if gift.RecipientEmail == "" {
+				gift.RecipientEmail = recipient.Email
+			}
+		}
+
+		if err := tx.Create(gift).Error; err != nil {
+			return fmt.Errorf("ошибка при создании подарка %w", err)
+		}
+
+		if err := createOrder(tx, &models.Order{
+			UserID:   sender.ID,
+			Currency: currency,
+			Items: []models.OrderItem{{
+				TargetType: models.TargetGift,
+				TargetID:   category.ID,
+				Name:       category.Name,
+				ListPrice:  price,
+				FinalPrice: price,
+				GiftID:     &gift.ID,
+			}},
+		}); err != nil {
+			return fmt.Errorf("ошибка при создании заказа %w", err)
+		}
+
+		return enqueueNotification(tx, event)
+	})
+	if err != nil {
+		s.log.Error("Ошибка при отправке подарка", "sender_id", senderID, "error", err.Error())
+		return nil, err
+	}
+
+	s.log.Info("Подарок отправлен", "gift_id", gift.ID, "sender_id", senderID)
+	return gift, nil
+}
+
+func (s *giftService) Accept(giftID, userID uint) (*models.Gift, error) {
+	return s.accept(func(tx *gorm.DB, gift *models.Gift) error {
+		return tx.First(gift, giftID).Error
+	}, userID, false)
+}
+
+func (s *giftService) Redeem(code string, userID uint) (*models.Gift, error) {
+	return s.accept(func(tx *gorm.DB, gift *models.Gift) error {
+		return tx.Where("code = ?", strings.ToUpper(strings.TrimSpace(code))).First(gift).Error
+	}, userID, true)
+}
+
+func (s *giftService) accept(find func(tx *gorm.DB, gift *models.Gift) error, userID uint, byCode bool) (*models.Gift, error) {
+	var gift models.Gift
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := find(tx.Clauses(clause.Locking{Strength: "UPDATE"}), &gift); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("подарок не найден")
+			}
+			return err
+		}
+
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("пользователь не найден")
+			}
+			return fmt.Errorf("ошибка при поиске пользователя %w", err)
+		}
+
+		if err := checkGiftRecipient(&gift, &user, byCode); err != nil {
+			return err
+		}
+
+		userPlan := &models.UserPlan{
+			UserID:          user.ID,
+			CategoriesID:    gift.CategoriesID,
+			CategoryVersion: gift.CategoryVersion,
+			PricePaid:       gift.Amount,
+		}
+		if err := tx.Create(userPlan).Error; err != nil {
+			return fmt.Errorf("ошибка при записи покупки пользователя %w", err)
+		}
+
+		if err := tx.Model(&user).UpdateColumn("categories_id", gift.CategoriesID).Error; err != nil {
+			return fmt.Errorf("ошибка при сохранении пользователя %w", err)
+		}
+
+		now := time.Now()
+		gift.Status = models.GiftAccepted
+		gift.RecipientID = &user.ID
+		gift.RespondedAt = &now
+		if err := tx.Model(&gift).Updates(map[string]any{
+			"status":       gift.Status,
+			"recipient_id": user.ID,
+			"responded_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return enqueueNotification(tx, models.NotificationEvent{
+			EventType: models.EventGiftAccepted,
+			UserID:    gift.SenderID,
+			Data: map[string]any{
+				"recipient_name": user.Name,
+				"gift_id":        gift.ID,
+			},
+		})
+	})
+	if err != nil {
+		s.log.Error("Ошибка при принятии подарка", "user_id", userID, "error", err.Error())
+		return nil, err
+	}
+
+	s.log.Info("Подарок принят", "gift_id", gift.ID, "user_id", userID)
+	return &gift, nil
+}
+
+func (s *giftService) Decline(giftID, userID uint) (*models.Gift, error) {
+	var gift models.Gift
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&gift, giftID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("подарок не найден")
+			}
+			return err
+		}
+
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("пользователь не найден")
+			}
+			return fmt.Errorf("ошибка при поиске пользователя %w", err)
+		}
+
+		if err := checkGiftRecipient(&gift, &user, false); err != nil {
+			return err
+		}
+
+		return refundGift(tx, &gift, models.GiftDeclined, models.EventGiftDeclined)
+	})
+	if err != nil {
+		s.log.Error("Ошибка при отклонении подарка", "user_id", userID, "error", err.Error())
+		return nil, err
+	}
+
+	s.log.Info("Подарок отклонен", "gift_id", gift.ID, "user_id", userID)
+	return &gift, nil
+}
+
+func (s *giftService) ExpirePending() (int, error) {
+	count := 0
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var gifts []models.Gift
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expires_at <= ?", models.GiftPending, time.Now()).
+			Find(&gifts).Error; err != nil {
+			return err
+		}
+
+		for i := range gifts {
+			if err := refundGift(tx, &gifts[i], models.GiftExpired, models.EventGiftExpired); err != nil {
+				return err
+			}
+			count++
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.log.Error("error ExpirePending in gift_service.go", "err", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (s *giftService) RunExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if count, err := s.ExpirePending(); err == nil && count > 0 {
+			s.log.Info("Просроченные подарки возвращены отправителям", "count", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *giftService) History(userID uint) (*models.GiftHistory, error) {
+	user, err := s.users.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sent, err := s.repo.ListSent(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	received, err := s.repo.ListReceived(user.ID, user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GiftHistory{Sent: sent, Received: received}, nil
+}
+
+func checkGiftRecipient(gift *models.Gift, user *models.User, byCode bool) error {
+	if gift.Status != models.GiftPending {
+		return fmt.Errorf("подарок уже обработан")
+	}
+
+	if time.Now().After(gift.ExpiresAt) {
+		return fmt.Errorf("срок действия подарка истек")
+	}
+
+	if gift.SenderID == user.ID {
+		return fmt.Errorf("нельзя принять собственный подарок")
+	}
+
+	if gift.RecipientID != nil {
+		if *gift.RecipientID != user.ID {
+			return fmt.Errorf("подарок предназначен другому пользователю")
+		}
+		return nil
+	}
+
+	if byCode || strings.EqualFold(gift.RecipientEmail, user.Email) {
+		return nil
+	}
+
+	return fmt.Errorf("подарок предназначен другому пользователю")
+}
+
+func refundGift(tx *gorm.DB, gift *models.Gift, status, eventType string) error {
+	if gift.Status != models.GiftPending {
+		return fmt.Errorf("подарок уже обработан")
+	}
+
+	if err := creditWallet(tx, gift.SenderID, gift.Amount, gift.Currency); err != nil {
+		return fmt.Errorf("ошибка при возврате средств %w", err)
+	}
+
+	now := time.Now()
+	gift.Status = status
+	gift.RespondedAt = &now
+	if err := tx.Model(gift).Updates(map[string]any{
+		"status":       status,
+		"responded_at": now,
+	}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.Order{}).
+		Where("id IN (?)", tx.Model(&models.OrderItem{}).Select("order_id").Where("gift_id = ?", gift.ID)).
+		UpdateColumn("refunded_at", now).Error; err != nil {
+		return err
+	}
+
+	giftItems := tx.Model(&models.OrderItem{}).Select("id").Where("gift_id = ?", gift.ID)
+	if err := reverseCoachEarnings(tx, giftItems, gift.Amount); err != nil {
+		return err
+	}
+
+	return enqueueNotification(tx, models.NotificationEvent{
+		EventType: eventType,
+		UserID:    gift.SenderID,
+		Data: map[string]any{
+			"gift_id": gift.ID,
+			"amount":  models.Money{Amount: gift.Amount, Currency: gift.Currency}.String(),
+		},
+	})
+}
+
+func newGiftCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf), nil
+}
@@ -17,19 +17,35 @@ type MealPlanItemsService interface {
 
 type mealPlanItemsService struct {
 	mealPlanItems repository.MealPlanItemRepository
+	mealPlans     repository.MealPlanRepository
 	logger        *slog.Logger
 }
 
 func NewMealPlanItemsService(
 	mealPlanItems repository.MealPlanItemRepository,
+	mealPlans repository.MealPlanRepository,
 	logger *slog.Logger,
 ) MealPlanItemsService {
 	return &mealPlanItemsService{
 		mealPlanItems: mealPlanItems,
+		mealPlans:     mealPlans,
 		logger:        logger,
 	}
 }
 
+// editablePlan keeps the items of a published meal plan as read-only as the
+// plan itself.
+func (s *mealPlanItemsService) editablePlan(id uint) error {
+	mealPlan, err := s.mealPlans.GetMealPlanByID(id)
+	if err != nil {
+		return err
+	}
+	if mealPlan.Status == models.StatusPublished {
+		return ErrPublished
+	}
+	return nil
+}
+
 func (s *mealPlanItemsService) CreateMealPlanItem(req models.CreateMealPlanItemRequest) (*models.MealPlanItem, error) {
 	if req.MealPlanId == 0 {
 		s.logger.Warn("attempt to create item with empty meal plan id")
@@ -39,6 +55,9 @@ func (s *mealPlanItemsService) CreateMealPlanItem(req models.CreateMealPlanItemR
 		s.logger.Warn("attempt to create item with empty name")
 		return nil, errors.New("name is required")
 	}
+	if err := s.editablePlan(req.MealPlanId); err != nil {
+		return nil, err
+	}
 
 	item := &models.MealPlanItem{
 		Name:        req.Name,
@@ -78,6 +97,14 @@ func (s *mealPlanItemsService) UpdateMealPlanItem(id uint, req *models.UpdateMea
 		s.logger.Error("service: meal plan item not found")
 		return nil, err
 	}
+	if err := s.editablePlan(mealPlanItems.MealPlanId); err != nil {
+		return nil, err
+	}
+	if req.MealPlanId != nil && *req.MealPlanId != mealPlanItems.MealPlanId {
+		if err := s.editablePlan(*req.MealPlanId); err != nil {
+			return nil, err
+		}
+	}
 
 	if req.Name != nil {
 		mealPlanItems.Name = *req.Name
@@ -123,7 +150,15 @@ func (s *mealPlanItemsService) DeleteMealPlanItem(id uint) error {
 		s.logger.Warn("attempt to delete meal plan with id = 0")
 		return errors.New("invalid id")
 	}
-	err := s.mealPlanItems.Delete(id)
+	mealPlanItem, err := s.mealPlanItems.GetMealPlanItemByID(id)
+	if err != nil {
+		s.logger.Error("failed to get meal plan item", "id", id, "error", err)
+		return err
+	}
+	if err := s.editablePlan(mealPlanItem.MealPlanId); err != nil {
+		return err
+	}
+	err = s.mealPlanItems.Delete(id)
 	if err != nil {
 		s.logger.Error("failed to delete meal plan", "id", id)
 		return err
@@ -13,6 +13,7 @@ type MealPlanService interface {
 	UpdateMealPlan(id uint, req *models.UpdateMealPlanRequest) (*models.MealPlan, error)
 	GetMealPlanByID(id uint) (*models.MealPlan, error)
 	DeleteMealPlan(id uint) error
+	CloneMealPlan(id uint, req models.ClonePlanRequest) (*models.MealPlan, error)
 }
 
 type mealPlanService struct {
@@ -53,6 +54,8 @@ func (s *mealPlanService) CreateMealPlan(req models.CreateMealPlanRequest) (*mod
 		Description: req.Description,
 		CategoriesID:  req.CategoriesID,
 		TotalDays:   req.TotalDays,
+		Status:      models.StatusDraft,
+		OwnerID:     req.OwnerID,
 	}
 
 	if err := s.mealPlans.Create(&mealPlan); err != nil {
@@ -84,6 +87,10 @@ func (s *mealPlanService) UpdateMealPlan(id uint, req *models.UpdateMealPlanRequ
 		return nil, err
 	}
 
+	if mealPlan.Status == models.StatusPublished {
+		return nil, ErrPublished
+	}
+
 	if req.Name != nil {
 		mealPlan.Name = *req.Name
 	}
@@ -111,7 +118,15 @@ func (s *mealPlanService) DeleteMealPlan(id uint) error {
 		s.logger.Warn("attempt to delete meal plan with id = 0")
 		return errors.New("invalid id")
 	}
-	err := s.mealPlans.Delete(id)
+	mealPlan, err := s.mealPlans.GetMealPlanByID(id)
+	if err != nil {
+		s.logger.Error("service: meal plan not found")
+		return err
+	}
+	if mealPlan.Status == models.StatusPublished {
+		return ErrPublished
+	}
+	err = s.mealPlans.Delete(id)
 	if err != nil {
 		s.logger.Error("failed to delete meal plan", "id", id)
 		return err
@@ -132,3 +147,26 @@ func (s *mealPlanService) GetMealPlanByID(id uint) (*models.MealPlan, error) {
 	}
 	return mealPlan, nil
 }
+
+func (s *mealPlanService) CloneMealPlan(id uint, req models.ClonePlanRequest) (*models.MealPlan, error) {
+	if id == 0 {
+		s.logger.Warn("attempt to clone meal plan with id = 0")
+		return nil, errors.New("invalid id")
+	}
+
+	if req.CategoriesID != nil {
+		if _, err := s.category.GetCategoryByID(*req.CategoriesID); err != nil {
+			s.logger.Error("failed to find target category", "id", *req.CategoriesID)
+			return nil, err
+		}
+	}
+
+	mealPlan, err := s.mealPlans.Clone(id, req.CategoriesID)
+	if err != nil {
+		s.logger.Error("failed to clone meal plan", "id", id, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("meal plan cloned successfully", "id", id, "clone_id", mealPlan.ID)
+	return mealPlan, nil
+}
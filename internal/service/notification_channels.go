@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"healthy_body/internal/templates"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var ErrWebhookTarget = errors.New("webhook target must be an absolute https url of a public host")
+
+type NotificationRecipient struct {
+	UserID    uint
+	Name      string
+	Email     string
+	Target    string
+	EventType string
+}
+
+type NotificationChannel interface {
+	Name() string
+	Send(recipient NotificationRecipient, message *templates.Message) error
+}
+
+type WebhookChannel struct {
+	client *http.Client
+	logger *slog.Logger
+}
+
+// NewWebhookChannel copies the client and swaps its transport for one that
+// refuses to dial internal addresses, so a target that passed validation
+// cannot later resolve to one.
+func NewWebhookChannel(client *http.Client, logger *slog.Logger) *WebhookChannel {
+	guarded := *client
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: refuseInternalAddress}
+	guarded.Transport = &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	guarded.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many webhook redirects")
+		}
+		return validateWebhookTarget(req.URL.String())
+	}
+
+	return &WebhookChannel{
+		client: &guarded,
+		logger: logger,
+	}
+}
+
+// validateWebhookTarget accepts only absolute https urls whose host is not a
+// literal internal address; hostnames are checked again when dialing.
+func validateWebhookTarget(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return ErrWebhookTarget
+	}
+	if u.Hostname() == "localhost" {
+		return ErrWebhookTarget
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && internalIP(ip) {
+		return ErrWebhookTarget
+	}
+	return nil
+}
+
+func refuseInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || internalIP(ip) {
+		return fmt.Errorf("webhook address %s is not public", host)
+	}
+	return nil
+}
+
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func internalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+func (c *WebhookChannel) Name() string {
+	return models.ChannelWebhook
+}
+
+func (c *WebhookChannel) Send(recipient NotificationRecipient, message *templates.Message) error {
+	if recipient.Target == "" {
+		return nil
+	}
+	if err := validateWebhookTarget(recipient.Target); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"event_type": recipient.EventType,
+		"user_id":    recipient.UserID,
+		"subject":    message.Subject,
+		"text":       message.Text,
+		"html":       message.HTML,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Post(recipient.Target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		c.logger.Error("failed to call notification webhook", "user_id", recipient.UserID, "err", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+type InAppChannel struct {
+	repo   repository.NotificationRepository
+	logger *slog.Logger
+}
+
+func NewInAppChannel(repo repository.NotificationRepository, logger *slog.Logger) *InAppChannel {
+	return &InAppChannel{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (c *InAppChannel) Name() string {
+	return models.ChannelInApp
+}
+
+func (c *InAppChannel) Send(recipient NotificationRecipient, message *templates.Message) error {
+	if recipient.UserID == 0 {
+		return nil
+	}
+
+	return c.repo.CreateInbox(&models.InboxNotification{
+		UserID:    recipient.UserID,
+		EventType: recipient.EventType,
+		Subject:   message.Subject,
+		Body:      message.Text,
+	})
+}
+
+type LogChannel struct {
+	path   string
+	mu     sync.Mutex
+	logger *slog.Logger
+}
+
+func NewLogChannel(path string, logger *slog.Logger) *LogChannel {
+	return &LogChannel{
+		path:   path,
+		logger: logger,
+	}
+}
+
+func (c *LogChannel) Name() string {
+	return models.ChannelLog
+}
+
+func (c *LogChannel) Send(recipient NotificationRecipient, message *templates.Message) error {
+	c.logger.Info("notification",
+		"event_type", recipient.EventType,
+		"user_id", recipient.UserID,
+		"to", recipient.Email,
+		"subject", message.Subject)
+
+	if c.path == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(map[string]any{
+		"time":       time.Now().UTC(),
+		"event_type": recipient.EventType,
+		"user_id":    recipient.UserID,
+		"to":         recipient.Email,
+		"subject":    message.Subject,
+		"text":       message.Text,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	file, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"log/slog"
+	"time"
+)
+
+const (
+	outboxBatchSize   = 20
+	outboxMaxAttempts = 8
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = time.Hour
+	outboxLease       = 5 * time.Minute
+)
+
+type NotificationOutboxService interface {
+	Enqueue(event models.NotificationEvent) error
+	Run(ctx context.Context, interval time.Duration)
+	Dispatch() (int, error)
+	List(status string) ([]models.NotificationOutbox, error)
+	Retry(id uint) (*models.NotificationOutbox, error)
+}
+
+type notificationOutboxService struct {
+	repo     repository.NotificationOutboxRepository
+	notifier NotificationService
+	log      *slog.Logger
+}
+
+func NewNotificationOutboxService(repo repository.NotificationOutboxRepository, notifier NotificationService, log *slog.Logger) NotificationOutboxService {
+	return &notificationOutboxService{
+		repo:     repo,
+		notifier: notifier,
+		log:      log,
+	}
+}
+
+func (s *notificationOutboxService) Enqueue(event models.NotificationEvent) error {
+	return s.repo.Enqueue(event)
+}
+
+func (s *notificationOutboxService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Dispatch(); err != nil {
+			s.log.Error("error Run in notification_outbox_service.go", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *notificationOutboxService) Dispatch() (int, error) {
+	messages, err := s.repo.ClaimDue(outboxBatchSize, outboxLease)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, message := range messages {
+		delivered, err := s.deliver(&message)
+		if err != nil {
+			attempts := message.Attempts + 1
+			dead := attempts >= outboxMaxAttempts
+			next := time.Now().Add(outboxBackoff(attempts))
+
+			s.log.Warn("notification delivery failed",
+				"id", message.ID,
+				"event_type", message.EventType,
+				"attempts", attempts,
+				"dead", dead,
+				"err", err)
+
+			if err := s.repo.MarkFailed(message.ID, attempts, next, err.Error(), delivered, dead); err != nil {
+				return sent, err
+			}
+			continue
+		}
+
+		if err := s.repo.MarkSent(message.ID); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	if len(messages) > 0 {
+		s.log.Info("outbox dispatched", "claimed", len(messages), "sent", sent)
+	}
+
+	return sent, nil
+}
+
+func (s *notificationOutboxService) List(status string) ([]models.NotificationOutbox, error) {
+	switch status {
+	case "", models.OutboxPending, models.OutboxSent, models.OutboxDead:
+	default:
+		return nil, fmt.Errorf("unknown notification status %q", status)
+	}
+
+	return s.repo.List(status)
+}
+
+func (s *notificationOutboxService) Retry(id uint) (*models.NotificationOutbox, error) {
+	message, err := s.repo.Retry(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("notification scheduled for retry", "id", id)
+	return message, nil
+}
+
+// deliver retries only the channels that have not received the message yet.
+func (s *notificationOutboxService) deliver(message *models.NotificationOutbox) ([]string, error) {
+	var event models.NotificationEvent
+	if err := json.Unmarshal(message.Payload, &event); err != nil {
+		return message.Delivered, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	return s.notifier.Notify(event, message.Delivered)
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+
+	return backoff
+}
@@ -1,9 +1,204 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"healthy_body/internal/templates"
+	"log/slog"
+	"maps"
+	"slices"
 )
 
 type NotificationService interface {
-	SendPaymentSuccess(user *models.User, category *models.Categories) error
+	Notify(event models.NotificationEvent, delivered []string) ([]string, error)
+	GetPreferences(userID uint) ([]models.NotificationPreference, error)
+	UpdatePreference(userID uint, req models.UpdateNotificationPreferenceRequest) (*models.NotificationPreference, error)
+	ListInbox(userID uint, page, pageSize int) (*models.InboxPage, error)
+	MarkRead(userID, id uint) error
+	MarkAllRead(userID uint) (int64, error)
+}
+
+type notificationService struct {
+	repo     repository.NotificationRepository
+	users    repository.UserRepository
+	channels []NotificationChannel
+	log      *slog.Logger
+}
+
+func NewNotificationService(repo repository.NotificationRepository, users repository.UserRepository, log *slog.Logger, channels ...NotificationChannel) NotificationService {
+	return &notificationService{
+		repo:     repo,
+		users:    users,
+		channels: channels,
+		log:      log,
+	}
+}
+
+// Notify sends the event over every enabled channel not in delivered and
+// returns delivered with the channels that succeeded this time.
+func (s *notificationService) Notify(event models.NotificationEvent, delivered []string) ([]string, error) {
+	recipient := NotificationRecipient{
+		UserID:    event.UserID,
+		Email:     event.Email,
+		EventType: event.EventType,
+	}
+	language := event.Language
+	prefs := make(map[string]models.NotificationPreference)
+
+	if event.UserID != 0 {
+		user, err := s.users.GetUserByID(event.UserID)
+		if err != nil {
+			return delivered, err
+		}
+
+		recipient.Name = user.Name
+		if recipient.Email == "" {
+			recipient.Email = user.Email
+		}
+		if language == "" {
+			language = user.Language
+		}
+
+		list, err := s.repo.ListPreferences(user.ID)
+		if err != nil {
+			return delivered, err
+		}
+		for _, pref := range list {
+			prefs[pref.Channel] = pref
+		}
+	}
+
+	data := map[string]any{"user_name": recipient.Name}
+	maps.Copy(data, event.Data)
+
+	message, err := templates.Render(event.EventType, language, data)
+	if err != nil {
+		s.log.Error("error Notify in notification_service.go", "event_type", event.EventType, "err", err)
+		return delivered, err
+	}
+
+	var errs []error
+	for _, channel := range s.channels {
+		if slices.Contains(delivered, channel.Name()) {
+			continue
+		}
+
+		pref, ok := prefs[channel.Name()]
+		enabled := channel.Name() != models.ChannelWebhook
+		if ok {
+			enabled = pref.Enabled
+		}
+		if !enabled {
+			continue
+		}
+
+		target := recipient
+		target.Target = pref.Target
+
+		if err := channel.Send(target, message); err != nil {
+			s.log.Warn("notification channel failed", "channel", channel.Name(), "event_type", event.EventType, "err", err)
+			errs = append(errs, fmt.Errorf("%s: %w", channel.Name(), err))
+			continue
+		}
+		delivered = append(delivered, channel.Name())
+	}
+
+	return delivered, errors.Join(errs...)
+}
+
+func (s *notificationService) GetPreferences(userID uint) ([]models.NotificationPreference, error) {
+	if _, err := s.users.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListPreferences(userID)
+}
+
+func (s *notificationService) UpdatePreference(userID uint, req models.UpdateNotificationPreferenceRequest) (*models.NotificationPreference, error) {
+	switch req.Channel {
+	case models.ChannelEmail, models.ChannelWebhook, models.ChannelInApp:
+	default:
+		return nil, fmt.Errorf("unknown notification channel %q", req.Channel)
+	}
+
+	if _, err := s.users.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	pref := models.NotificationPreference{UserID: userID, Channel: req.Channel, Enabled: true}
+
+	list, err := s.repo.ListPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range list {
+		if existing.Channel == req.Channel {
+			pref = existing
+		}
+	}
+
+	if req.Enabled != nil {
+		pref.Enabled = *req.Enabled
+	}
+	if req.Target != nil {
+		pref.Target = *req.Target
+	}
+
+	if pref.Channel == models.ChannelWebhook && pref.Enabled && pref.Target == "" {
+		return nil, errors.New("webhook channel requires a target url")
+	}
+	if pref.Channel == models.ChannelWebhook && pref.Target != "" {
+		if err := validateWebhookTarget(pref.Target); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.SavePreference(&pref); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("notification preference updated", "user_id", userID, "channel", pref.Channel, "enabled", pref.Enabled)
+	return &pref, nil
+}
+
+func (s *notificationService) ListInbox(userID uint, page, pageSize int) (*models.InboxPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	if _, err := s.users.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	items, total, unread, err := s.repo.ListInbox(userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.InboxPage{
+		Items:    items,
+		Total:    total,
+		Unread:   unread,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+func (s *notificationService) MarkRead(userID, id uint) error {
+	return s.repo.MarkRead(userID, id)
+}
+
+func (s *notificationService) MarkAllRead(userID uint) (int64, error) {
+	count, err := s.repo.MarkAllRead(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.log.Info("notifications marked as read", "user_id", userID, "count", count)
+	return count, nil
 }
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"healthy_body/internal/templates"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderService interface {
+	List(userID uint) ([]models.Order, error)
+	Receipt(userID, orderID uint) (string, error)
+}
+
+type orderService struct {
+	repo  repository.OrderRepository
+	users repository.UserRepository
+	log   *slog.Logger
+}
+
+func NewOrderService(repo repository.OrderRepository, users repository.UserRepository, log *slog.Logger) OrderService {
+	return &orderService{
+		repo:  repo,
+		users: users,
+		log:   log,
+	}
+}
+
+func (s *orderService) List(userID uint) ([]models.Order, error) {
+	if _, err := s.users.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListByUser(userID)
+}
+
+func (s *orderService) Receipt(userID, orderID uint) (string, error) {
+	user, err := s.users.GetUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	order, err := s.repo.GetByID(userID, orderID)
+	if err != nil {
+		return "", err
+	}
+
+	money := func(amount int64) string {
+		return models.Money{Amount: amount, Currency: order.Currency}.String()
+	}
+
+	items := make([]map[string]any, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, map[string]any{
+			"Name":       item.Name,
+			"ListPrice":  money(item.ListPrice),
+			"Discount":   money(item.Discount),
+			"FinalPrice": money(item.FinalPrice),
+		})
+	}
+
+	html, err := templates.RenderHTML("order_receipt", user.Language, map[string]any{
+		"user_name":      user.Name,
+		"receipt_number": order.ReceiptNumber,
+		"date":           order.CreatedAt.Format("02.01.2006 15:04"),
+		"items":          items,
+		"list_total":     money(order.ListTotal),
+		"discount":       money(order.Discount),
+		"total":          money(order.Total),
+		"payment_source": order.PaymentSource,
+	})
+	if err != nil {
+		s.log.Error("error Receipt in order_service.go", "order_id", orderID, "err", err)
+		return "", err
+	}
+
+	return html, nil
+}
+
+// createOrder numbers receipts from a locked per-year counter.
+func createOrder(tx *gorm.DB, order *models.Order) error {
+	year := time.Now().Year()
+
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.ReceiptCounter{Year: year}).Error; err != nil {
+		return err
+	}
+
+	var counter models.ReceiptCounter
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&counter, "year = ?", year).Error; err != nil {
+		return err
+	}
+
+	counter.Value++
+	if err := tx.Model(&models.ReceiptCounter{}).Where("year = ?", year).UpdateColumn("value", counter.Value).Error; err != nil {
+		return err
+	}
+
+	order.ReceiptNumber = fmt.Sprintf("HB-%d-%06d", year, counter.Value)
+	if order.PaymentSource == "" {
+		order.PaymentSource = models.PaymentSourceBalance
+	}
+
+	order.ListTotal, order.Discount, order.Total = 0, 0, 0
+	for i := range order.Items {
+		item := &order.Items[i]
+		order.ListTotal += item.ListPrice
+		order.Discount += item.Discount
+		order.Total += item.FinalPrice
+
+		if err := attributeCoach(tx, item); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Create(order).Error; err != nil {
+		return err
+	}
+
+	return accrueCoachEarnings(tx, order)
+}
+
+// attributeCoach credits the item to the coach who owns the purchased
+// category; subscriptions and gifts count for the owner of their category.
+func attributeCoach(tx *gorm.DB, item *models.OrderItem) error {
+	if item.CoachID != nil {
+		return nil
+	}
+
+	query := tx.Table("categories")
+	switch item.TargetType {
+	case models.TargetCategory, models.TargetGift:
+		query = query.Where("categories.id = ?", item.TargetID)
+	case models.TargetSubscription:
+		query = query.Joins("JOIN subscriptions ON subscriptions.categories_id = categories.id").
+			Where("subscriptions.id = ?", item.TargetID)
+	default:
+		return nil
+	}
+
+	var owners []*uint
+	if err := query.Limit(1).Pluck("categories.owner_id", &owners).Error; err != nil {
+		return err
+	}
+	if len(owners) > 0 {
+		item.CoachID = owners[0]
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+const exifOrientationTag = 0x0112
+
+// photoOrientation reads the EXIF Orientation of a JPEG (APP1 segment) or a
+// PNG (eXIf chunk). Photos without one are upright, which is orientation 1.
+func photoOrientation(data []byte, contentType string) int {
+	var tiff []byte
+	switch contentType {
+	case "image/jpeg":
+		tiff = jpegExif(data)
+	case "image/png":
+		tiff = pngExif(data)
+	}
+
+	orientation := tiffOrientation(tiff)
+	if orientation < 1 || orientation > 8 {
+		return 1
+	}
+
+	return orientation
+}
+
+func jpegExif(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return nil
+		}
+		marker := data[i+1]
+		if marker == 0xD8 || marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			i += 2
+			continue
+		}
+		// the image data starts here, metadata only comes before it
+		if marker == 0xDA || marker == 0xD9 {
+			return nil
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return nil
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:]
+		}
+		i += 2 + length
+	}
+
+	return nil
+}
+
+func pngExif(data []byte) []byte {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil
+	}
+
+	for i := len(signature); i+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		chunk := string(data[i+4 : i+8])
+		if length < 0 || i+12+length > len(data) {
+			return nil
+		}
+		switch chunk {
+		case "eXIf":
+			return data[i+8 : i+8+length]
+		case "IDAT", "IEND":
+			return nil
+		}
+		i += 12 + length
+	}
+
+	return nil
+}
+
+// tiffOrientation looks the Orientation tag up in the first IFD of an EXIF
+// TIFF structure; 0 means it is not there.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+
+	return 0
+}
+
+// orient turns img upright for the given EXIF orientation. The re-encoded photo
+// carries no EXIF, so a photo taken with a turned camera would otherwise stay
+// sideways.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA64(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // flip horizontally
+				sx, sy = w-1-x, y
+			case 3: // turn 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // flip vertically
+				sx, sy = x, h-1-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // turn 90° clockwise
+				sx, sy = y, h-1-x
+			case 7: // transverse
+				sx, sy = w-1-y, h-1-x
+			case 8: // turn 90° counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, img.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+
+	return dst
+}
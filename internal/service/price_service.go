@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type PriceService interface {
+	GetPrices(targetType string, targetID uint) ([]models.Money, error)
+	SetPrices(targetType string, targetID uint, req models.SetPricesRequest) ([]models.Money, error)
+}
+
+type priceService struct {
+	repo repository.PriceRepository
+	db   *gorm.DB
+	log  *slog.Logger
+}
+
+func NewPriceService(repo repository.PriceRepository, db *gorm.DB, log *slog.Logger) PriceService {
+	return &priceService{
+		repo: repo,
+		db:   db,
+		log:  log,
+	}
+}
+
+// GetPrices lists the item's price in every currency it is sold in. The RUB
+// price always comes first and is taken from the item itself.
+func (s *priceService) GetPrices(targetType string, targetID uint) ([]models.Money, error) {
+	basePrice, err := s.basePrice(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.repo.List(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := []models.Money{{Amount: basePrice, Currency: models.CurrencyRUB}}
+	for _, price := range list {
+		prices = append(prices, models.Money{Amount: price.Amount, Currency: price.Currency})
+	}
+
+	return prices, nil
+}
+
+func (s *priceService) SetPrices(targetType string, targetID uint, req models.SetPricesRequest) ([]models.Money, error) {
+	if _, err := s.basePrice(targetType, targetID); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(req.Prices))
+	prices := make([]models.CatalogPrice, 0, len(req.Prices))
+	for _, price := range req.Prices {
+		currency := models.NormalizeCurrency(price.Currency)
+		if currency == models.CurrencyRUB {
+			return nil, errors.New("RUB price is set on the item itself")
+		}
+		if !models.IsSupportedCurrency(currency) {
+			return nil, fmt.Errorf("unsupported currency %q", price.Currency)
+		}
+		if seen[currency] {
+			return nil, fmt.Errorf("duplicate currency %s", currency)
+		}
+		if price.Amount < 1 {
+			return nil, fmt.Errorf("price in %s must be greater than zero", currency)
+		}
+		seen[currency] = true
+
+		prices = append(prices, models.CatalogPrice{
+			TargetType: targetType,
+			TargetID:   targetID,
+			Currency:   currency,
+			Amount:     price.Amount,
+		})
+	}
+
+	if err := s.repo.Replace(targetType, targetID, prices); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("prices updated", "target_type", targetType, "target_id", targetID, "count", len(prices))
+	return s.GetPrices(targetType, targetID)
+}
+
+func (s *priceService) basePrice(targetType string, targetID uint) (int64, error) {
+	switch targetType {
+	case models.TargetCategory:
+		var category models.Categories
+		if err := s.db.First(&category, targetID).Error; err != nil {
+			return 0, err
+		}
+		return category.Price, nil
+	case models.TargetSubscription:
+		var sub models.Subscription
+		if err := s.db.First(&sub, targetID).Error; err != nil {
+			return 0, err
+		}
+		return sub.Price, nil
+	default:
+		return 0, fmt.Errorf("unsupported target type %q", targetType)
+	}
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromoService interface {
+	Create(req models.CreatePromoCodeRequest) (*models.PromoCode, error)
+	List() ([]models.PromoCode, error)
+	Delete(id uint) error
+	Quote(userID uint, targetType string, targetID uint, code, currency string) (*models.PriceQuote, error)
+}
+
+type promoService struct {
+	repo repository.PromoCodeRepository
+	db   *gorm.DB
+	log  *slog.Logger
+}
+
+func NewPromoService(repo repository.PromoCodeRepository, db *gorm.DB, log *slog.Logger) PromoService {
+	return &promoService{
+		repo: repo,
+		db:   db,
+		log:  log,
+	}
+}
+
+func (s *promoService) Create(req models.CreatePromoCodeRequest) (*models.PromoCode, error) {
+	code := normalizePromoCode(req.Code)
+	if code == "" {
+		return nil, errors.New("code is required")
+	}
+
+	currency := ""
+	switch req.DiscountType {
+	case models.DiscountPercent:
+		if req.Value < 1 || req.Value > 100 {
+			return nil, errors.New("percent discount must be between 1 and 100")
+		}
+	case models.DiscountFixed:
+		if req.Value < 1 {
+			return nil, errors.New("fixed discount must be greater than zero")
+		}
+		currency = models.NormalizeCurrency(req.Currency)
+		if currency == "" {
+			currency = models.CurrencyRUB
+		}
+		if !models.IsSupportedCurrency(currency) {
+			return nil, fmt.Errorf("unsupported currency %q", req.Currency)
+		}
+	default:
+		return nil, fmt.Errorf("unknown discount type %q", req.DiscountType)
+	}
+
+	if req.MaxUses < 0 || req.MaxUsesPerUser < 0 {
+		return nil, errors.New("usage limits must not be negative")
+	}
+
+	if req.ValidFrom != nil && req.ValidUntil != nil && !req.ValidUntil.After(*req.ValidFrom) {
+		return nil, errors.New("valid_until must be after valid_from")
+	}
+
+	promo := &models.PromoCode{
+		Code:           code,
+		DiscountType:   req.DiscountType,
+		Value:          req.Value,
+		Currency:       currency,
+		ValidFrom:      req.ValidFrom,
+		ValidUntil:     req.ValidUntil,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+	}
+	for _, id := range req.Categories {
+		promo.Restrictions = append(promo.Restrictions, models.PromoRestriction{TargetType: models.TargetCategory, TargetID: id})
+	}
+	for _, id := range req.Subscriptions {
+		promo.Restrictions = append(promo.Restrictions, models.PromoRestriction{TargetType: models.TargetSubscription, TargetID: id})
+	}
+
+	if err := s.repo.Create(promo); err != nil {
+		s.log.Error("error Create in promo_service.go", "err", err)
+		return nil, err
+	}
+
+	return promo, nil
+}
+
+func (s *promoService) List() ([]models.PromoCode, error) {
+	return s.repo.List()
+}
+
+func (s *promoService) Delete(id uint) error {
+	return s.repo.Delete(id)
+}
+
+func (s *promoService) Quote(userID uint, targetType string, targetID uint, code, currency string) (*models.PriceQuote, error) {
+	currency = models.NormalizeCurrency(currency)
+	if userID != 0 {
+		var user models.User
+		if err := s.db.First(&user, userID).Error; err != nil {
+			return nil, err
+		}
+
+		var err error
+		if currency, err = purchaseCurrency(&user, currency); err != nil {
+			return nil, err
+		}
+	}
+	if currency == "" {
+		currency = models.CurrencyRUB
+	}
+
+	var basePrice int64
+	switch targetType {
+	case models.TargetCategory:
+		var category models.Categories
+		if err := s.db.First(&category, targetID).Error; err != nil {
+			return nil, err
+		}
+		basePrice = category.Price
+	case models.TargetSubscription:
+		var sub models.Subscription
+		if err := s.db.First(&sub, targetID).Error; err != nil {
+			return nil, err
+		}
+		basePrice = sub.Price
+	default:
+		return nil, fmt.Errorf("unknown target type %q", targetType)
+	}
+
+	price, err := priceFor(s.db, targetType, targetID, basePrice, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &models.PriceQuote{
+		TargetType: targetType,
+		TargetID:   targetID,
+		Currency:   currency,
+		ListPrice:  price,
+		FinalPrice: price,
+	}
+
+	if code == "" {
+		return quote, nil
+	}
+
+	promo, discount, err := applyPromo(s.db, code, userID, targetType, targetID, price, currency, false)
+	if err != nil {
+		return nil, err
+	}
+
+	quote.PromoCode = promo.Code
+	quote.Discount = discount
+	quote.FinalPrice = price - discount
+	return quote, nil
+}
+
+// applyPromo returns the discount of code; lock holds the row for the usage limit.
+func applyPromo(tx *gorm.DB, code string, userID uint, targetType string, targetID uint, price int64, currency string, lock bool) (*models.PromoCode, int64, error) {
+	promo, err := loadPromo(tx, code, userID, lock)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !promoApplies(promo, targetType, targetID) {
+		return nil, 0, fmt.Errorf("промокод не действует для этой покупки")
+	}
+
+	if err := checkPromoCurrency(promo, currency); err != nil {
+		return nil, 0, err
+	}
+
+	return promo, promoDiscount(promo, price, currency), nil
+}
+
+func loadPromo(tx *gorm.DB, code string, userID uint, lock bool) (*models.PromoCode, error) {
+	query := tx.Preload("Restrictions")
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	var promo models.PromoCode
+	if err := query.Where("code = ?", normalizePromoCode(code)).First(&promo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("промокод не найден")
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if promo.ValidFrom != nil && now.Before(*promo.ValidFrom) {
+		return nil, fmt.Errorf("промокод еще не действует")
+	}
+	if promo.ValidUntil != nil && now.After(*promo.ValidUntil) {
+		return nil, fmt.Errorf("срок действия промокода истек")
+	}
+
+	if promo.MaxUses > 0 && promo.UsedCount >= promo.MaxUses {
+		return nil, fmt.Errorf("промокод больше недоступен")
+	}
+
+	if promo.MaxUsesPerUser > 0 {
+		var used int64
+		if err := tx.Model(&models.PromoRedemption{}).
+			Where("promo_code_id = ? AND user_id = ?", promo.ID, userID).
+			Count(&used).Error; err != nil {
+			return nil, err
+		}
+		if int(used) >= promo.MaxUsesPerUser {
+			return nil, fmt.Errorf("вы уже использовали этот промокод")
+		}
+	}
+
+	return &promo, nil
+}
+
+func promoApplies(promo *models.PromoCode, targetType string, targetID uint) bool {
+	if len(promo.Restrictions) == 0 {
+		return true
+	}
+
+	for _, restriction := range promo.Restrictions {
+		if restriction.TargetType == targetType && restriction.TargetID == targetID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkPromoCurrency(promo *models.PromoCode, currency string) error {
+	if promo.DiscountType == models.DiscountFixed && promo.Currency != currency {
+		return fmt.Errorf("промокод действует только при оплате в %s", promo.Currency)
+	}
+
+	return nil
+}
+
+// promoDiscount returns the discount in minor units. Percent discounts are
+// rounded down to whole units of the currency.
+func promoDiscount(promo *models.PromoCode, price int64, currency string) int64 {
+	discount := promo.Value
+	if promo.DiscountType == models.DiscountPercent {
+		factor := models.MinorPerMajor(currency)
+		discount = price * promo.Value / 100 / factor * factor
+	}
+
+	return min(discount, price)
+}
+
+func redeemPromo(tx *gorm.DB, promo *models.PromoCode, userID uint, targetType string, targetID uint, discount int64, currency string) error {
+	if err := tx.Model(promo).UpdateColumn("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&models.PromoRedemption{
+		PromoCodeID: promo.ID,
+		UserID:      userID,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		Currency:    currency,
+		Discount:    discount,
+	}).Error
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"log/slog"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultRecommendations = 10
+	MaxRecommendations     = 50
+
+	popularityWindow = 30 * 24 * time.Hour
+)
+
+var ErrUserNotFound = errors.New("пользователь не найден")
+
+type RecommendationService interface {
+	Recommend(userID uint, limit int) (*models.Recommendations, error)
+}
+
+type recommendationService struct {
+	repo       repository.RecommendationRepository
+	users      repository.UserRepository
+	categories repository.CategoryRepo
+	log        *slog.Logger
+}
+
+func NewRecommendationService(repo repository.RecommendationRepository, users repository.UserRepository, categories repository.CategoryRepo, log *slog.Logger) RecommendationService {
+	return &recommendationService{
+		repo:       repo,
+		users:      users,
+		categories: categories,
+		log:        log,
+	}
+}
+
+// recommendationFacts is what the rules know about the user and the catalog.
+type recommendationFacts struct {
+	language   string
+	goal       string
+	bmi        float64
+	bmiBand    string
+	purchased  []models.Categories
+	ratings    map[uint]*models.CategoryRating
+	popularity map[uint]int64
+}
+
+// recommendationRule scores one category and explains the score; zero points
+// means the rule does not apply.
+type recommendationRule func(facts *recommendationFacts, category *models.Categories) (int, string)
+
+// recommendationRules run in this order, which is also the order of the
+// reasons in the response.
+var recommendationRules = []recommendationRule{
+	goalRule,
+	bmiRule,
+	similarPurchaseRule,
+	sameCoachRule,
+	ratingRule,
+	popularityRule,
+}
+
+// Recommend scores the published categories the user has not bought yet and
+// returns the best ones with the reasons behind each score. The engine is a
+// fixed set of rules, so the same data always gives the same answer.
+func (s *recommendationService) Recommend(userID uint, limit int) (*models.Recommendations, error) {
+	if limit <= 0 {
+		limit = DefaultRecommendations
+	}
+	limit = min(limit, MaxRecommendations)
+
+	user, err := s.users.GetUserByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.repo.Candidates()
+	if err != nil {
+		return nil, err
+	}
+	purchased, err := s.repo.Purchased(userID)
+	if err != nil {
+		return nil, err
+	}
+	popularity, err := s.repo.Popularity(time.Now().Add(-popularityWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(candidates))
+	for _, category := range candidates {
+		ids = append(ids, category.ID)
+	}
+	ratings, err := s.categories.Ratings(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	facts := &recommendationFacts{
+		language:   user.Language,
+		goal:       user.Goal,
+		purchased:  purchased,
+		ratings:    ratings,
+		popularity: popularity,
+	}
+	result := &models.Recommendations{UserID: user.ID, Goal: user.Goal, Items: []models.Recommendation{}}
+	if user.HeightCm > 0 && user.WeightKg > 0 {
+		facts.bmi = models.BMI(user.WeightKg, user.HeightCm)
+		facts.bmiBand = models.BMIBand(facts.bmi)
+		result.BMI = &facts.bmi
+		result.BMIBand = facts.bmiBand
+	}
+
+	owned := make(map[uint]bool, len(purchased))
+	for _, category := range purchased {
+		owned[category.ID] = true
+	}
+
+	for i := range candidates {
+		category := &candidates[i]
+		if owned[category.ID] {
+			continue
+		}
+
+		item := models.Recommendation{Reasons: []string{}}
+		for _, rule := range recommendationRules {
+			points, reason := rule(facts, category)
+			if points > 0 {
+				item.Score += points
+				item.Reasons = append(item.Reasons, reason)
+			}
+		}
+		if item.Score == 0 {
+			continue
+		}
+
+		category.Rating = ratings[category.ID]
+		item.Category = *category
+		result.Items = append(result.Items, item)
+	}
+
+	sort.SliceStable(result.Items, func(i, j int) bool {
+		return result.Items[i].Score > result.Items[j].Score
+	})
+	if len(result.Items) > limit {
+		result.Items = result.Items[:limit]
+	}
+
+	s.log.Info("recommendations built", "user_id", userID, "candidates", len(candidates), "items", len(result.Items))
+	return result, nil
+}
+
+func goalRule(facts *recommendationFacts, category *models.Categories) (int, string) {
+	if facts.goal == "" || !slices.Contains(category.Goals, facts.goal) {
+		return 0, ""
+	}
+
+	return 40, facts.text("goal", facts.goalName(facts.goal))
+}
+
+// bmiGoals are the goals that suit each BMI band.
+var bmiGoals = map[string][]string{
+	models.BMIUnderweight: {models.GoalGainMuscle, models.GoalMaintain},
+	models.BMINormal:      {models.GoalMaintain, models.GoalGainMuscle, models.GoalEndurance},
+	models.BMIOverweight:  {models.GoalLoseWeight, models.GoalEndurance},
+	models.BMIObese:       {models.GoalLoseWeight},
+}
+
+func bmiRule(facts *recommendationFacts, category *models.Categories) (int, string) {
+	if facts.bmiBand == "" {
+		return 0, ""
+	}
+
+	for _, goal := range bmiGoals[facts.bmiBand] {
+		if slices.Contains(category.Goals, goal) {
+			return 25, facts.text("bmi", facts.bmi, facts.bandName(facts.bmiBand))
+		}
+	}
+
+	return 0, ""
+}
+
+func similarPurchaseRule(facts *recommendationFacts, category *models.Categories) (int, string) {
+	for _, bought := range facts.purchased {
+		for _, goal := range bought.Goals {
+			if slices.Contains(category.Goals, goal) {
+				return 15, facts.text("similar", bought.Name)
+			}
+		}
+	}
+
+	return 0, ""
+}
+
+func sameCoachRule(facts *recommendationFacts, category *models.Categories) (int, string) {
+	if category.OwnerID == nil {
+		return 0, ""
+	}
+
+	for _, bought := range facts.purchased {
+		if bought.OwnerID != nil && *bought.OwnerID == *category.OwnerID {
+			return 10, facts.text("coach", bought.Name)
+		}
+	}
+
+	return 0, ""
+}
+
+// ratingRule rewards well rated categories once they have a few reviews:
+// 10 points for an average of 4 up to 20 for a perfect 5.
+func ratingRule(facts *recommendationFacts, category *models.Categories) (int, string) {
+	rating := facts.ratings[category.ID]
+	if rating == nil || rating.Count < 3 || rating.Average < 4 {
+		return 0, ""
+	}
+
+	return int(math.Round((rating.Average - 3) * 10)), facts.text("rating", rating.Average, rating.Count)
+}
+
+func popularityRule(facts *recommendationFacts, category *models.Categories) (int, string) {
+	purchases := facts.popularity[category.ID]
+	if purchases == 0 {
+		return 0, ""
+	}
+
+	return int(min(purchases, 15)), facts.text("popular", purchases)
+}
+
+var recommendationTexts = map[string]map[string]string{
+	models.LanguageRU: {
+		"goal":    "Подходит под вашу цель: %s",
+		"bmi":     "Рекомендуется при вашем ИМТ %.1f (%s)",
+		"similar": "Похожа на программу «%s», которую вы уже купили",
+		"coach":   "От тренера программы «%s»",
+		"rating":  "Средняя оценка %.1f по %d отзывам",
+		"popular": "Куплена %d раз за последние 30 дней",
+	},
+	models.LanguageEN: {
+		"goal":    "Fits your goal: %s",
+		"bmi":     "Suits your BMI of %.1f (%s)",
+		"similar": "Similar to %q, which you already bought",
+		"coach":   "By the coach of %q",
+		"rating":  "Rated %.1f on average in %d reviews",
+		"popular": "Bought %d times in the last 30 days",
+	},
+}
+
+var goalNames = map[string]map[string]string{
+	models.LanguageRU: {
+		models.GoalLoseWeight: "снижение веса",
+		models.GoalGainMuscle: "набор мышечной массы",
+		models.GoalMaintain:   "поддержание формы",
+		models.GoalEndurance:  "выносливость",
+	},
+	models.LanguageEN: {
+		models.GoalLoseWeight: "weight loss",
+		models.GoalGainMuscle: "muscle gain",
+		models.GoalMaintain:   "staying in shape",
+		models.GoalEndurance:  "endurance",
+	},
+}
+
+var bmiBandNames = map[string]map[string]string{
+	models.LanguageRU: {
+		models.BMIUnderweight: "недостаточный вес",
+		models.BMINormal:      "нормальный вес",
+		models.BMIOverweight:  "избыточный вес",
+		models.BMIObese:       "ожирение",
+	},
+	models.LanguageEN: {
+		models.BMIUnderweight: "underweight",
+		models.BMINormal:      "normal weight",
+		models.BMIOverweight:  "overweight",
+		models.BMIObese:       "obesity",
+	},
+}
+
+func (f *recommendationFacts) lang() string {
+	if _, ok := recommendationTexts[f.language]; ok {
+		return f.language
+	}
+
+	return models.LanguageRU
+}
+
+func (f *recommendationFacts) text(key string, args ...any) string {
+	return fmt.Sprintf(recommendationTexts[f.lang()][key], args...)
+}
+
+func (f *recommendationFacts) goalName(goal string) string {
+	return goalNames[f.lang()][goal]
+}
+
+func (f *recommendationFacts) bandName(band string) string {
+	return bmiBandNames[f.lang()][band]
+}
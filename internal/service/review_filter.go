@@ -0,0 +1,83 @@
+package service
+
+import (
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"regexp"
+	"strings"
+)
+
+// ReviewFilter inspects review content before it goes live. Each returned flag
+// sends the review to the moderation queue instead of publishing it.
+type ReviewFilter interface {
+	Check(review *models.Reviews) ([]string, error)
+}
+
+type wordListFilter struct {
+	words []string
+}
+
+// NewWordListFilter flags reviews that contain any of words, case-insensitively.
+func NewWordListFilter(words []string) ReviewFilter {
+	normalized := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			normalized = append(normalized, word)
+		}
+	}
+
+	return &wordListFilter{words: normalized}
+}
+
+func (f *wordListFilter) Check(review *models.Reviews) ([]string, error) {
+	content := strings.ToLower(review.Content)
+	for _, word := range f.words {
+		if strings.Contains(content, word) {
+			return []string{"stop_word"}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.|\b[a-z0-9-]+\.(ru|com|net|org|io|me|info|biz|xyz)\b|t\.me/|@[a-z0-9_]{5,})`)
+
+type linkFilter struct{}
+
+// NewLinkFilter flags reviews with URLs, bare domains or messenger handles.
+func NewLinkFilter() ReviewFilter {
+	return &linkFilter{}
+}
+
+func (f *linkFilter) Check(review *models.Reviews) ([]string, error) {
+	if linkPattern.MatchString(review.Content) {
+		return []string{"link"}, nil
+	}
+
+	return nil, nil
+}
+
+type duplicateFilter struct {
+	repo repository.ReviewsRepository
+}
+
+// NewDuplicateFilter flags content already posted in another review.
+func NewDuplicateFilter(repo repository.ReviewsRepository) ReviewFilter {
+	return &duplicateFilter{repo: repo}
+}
+
+func (f *duplicateFilter) Check(review *models.Reviews) ([]string, error) {
+	if strings.TrimSpace(review.Content) == "" {
+		return nil, nil
+	}
+
+	duplicate, err := f.repo.HasSameContent(review.Content, review.ID)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate {
+		return []string{"duplicate"}, nil
+	}
+
+	return nil, nil
+}
@@ -1,28 +1,57 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"healthy_body/internal/models"
 	"healthy_body/internal/repository"
 	"log/slog"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReviewNotPurchased = errors.New("оставить отзыв можно только на купленную категорию, категорию по действующей подписке или их планы")
+	ErrReviewExists       = errors.New("вы уже оставили отзыв на этот объект")
+	ErrReviewNotFound     = errors.New("отзыв не найден")
+	ErrReplyForbidden     = errors.New("отвечать на отзывы может только владелец категории или администратор")
+	ErrOwnReview          = errors.New("нельзя оценивать свой отзыв или жаловаться на него")
+	ErrReviewReported     = errors.New("вы уже пожаловались на этот отзыв")
 )
 
+// reviewReportThreshold is the number of open reports that sends a published
+// review back to moderation.
+const reviewReportThreshold = 3
+
 type ReviewsService interface {
 	CreateReview(req models.CreateReviewRequest, userID uint) (uint, error)
 	GetReview(id uint) (*models.GetReview, error)
 	GetReviewsByUser(userID uint) ([]models.GetReview, error)
-	GetReviewsByCategory(categoryID uint) ([]models.GetReview, error)
+	GetReviewsByCategory(categoryID uint, sort string) ([]models.GetReview, error)
+	GetReviewsByTarget(targetType string, targetID uint, sort string) ([]models.GetReview, error)
+	GetRating(targetType string, targetID uint) (*models.RatingStats, error)
 	UpdateReview(id uint, req models.UpdateReviewRequest, userID uint) error
 	DeleteReview(id uint, userID uint) error
+	RebuildRatings() (int64, error)
+	ListModeration(status string) ([]models.GetReview, error)
+	Approve(id uint) (*models.GetReview, error)
+	Reject(id uint, reason string) (*models.GetReview, error)
+	ReplyToReview(reviewID uint, req models.CreateReplyRequest) (*models.GetReviewReply, error)
+	DeleteReply(reviewID, replyID, userID uint) error
+	VoteReview(reviewID uint, req models.VoteReviewRequest) (*models.GetReview, error)
+	ReportReview(reviewID uint, req models.ReportReviewRequest) error
 }
 
 type reviewsService struct {
-	repo repository.ReviewsRepository
-	log  *slog.Logger
+	repo    repository.ReviewsRepository
+	outbox  NotificationOutboxService
+	filters []ReviewFilter
+	log     *slog.Logger
 }
 
-func NewReviewsService(repo repository.ReviewsRepository, log *slog.Logger) ReviewsService {
-	return &reviewsService{repo: repo, log: log}
+func NewReviewsService(repo repository.ReviewsRepository, log *slog.Logger, outbox NotificationOutboxService, filters ...ReviewFilter) ReviewsService {
+	return &reviewsService{repo: repo, log: log, outbox: outbox, filters: filters}
 }
 
 func (s *reviewsService) CreateReview(req models.CreateReviewRequest, userID uint) (uint, error) {
@@ -33,32 +62,105 @@ func (s *reviewsService) CreateReview(req models.CreateReviewRequest, userID uin
 		return 0, fmt.Errorf("такого пользователя не существует")
 	}
 
-	if req.CategoriesID == 0 {
-		s.log.Warn("Такой категории нету",
-			"category_id", req.CategoriesID)
-		return 0, fmt.Errorf("такой категории не существует")
+	if req.TargetType == "" {
+		req.TargetType = models.ReviewTargetCategory
+	}
+	if req.TargetType == models.ReviewTargetCategory && req.TargetID == 0 {
+		req.TargetID = req.CategoriesID
+	}
+	if !models.IsReviewTarget(req.TargetType) {
+		s.log.Warn("Неизвестный тип объекта отзыва",
+			"target_type", req.TargetType)
+		return 0, fmt.Errorf("отзыв можно оставить на category, exercise_plan или meal_plan")
+	}
+
+	if req.TargetID == 0 {
+		s.log.Warn("Объект отзыва не указан",
+			"target_type", req.TargetType)
+		return 0, fmt.Errorf("такого объекта отзыва не существует")
 
 	}
 
-	if req.Rating < 0 || req.Rating > 5 {
+	categoryID, err := s.repo.TargetCategoryID(req.TargetType, req.TargetID)
+	if err != nil {
+		return 0, fmt.Errorf("такого объекта отзыва не существует: %w", err)
+	}
+	if categoryID == 0 {
+		s.log.Warn("План вне категорий",
+			"target_type", req.TargetType,
+			"target_id", req.TargetID)
+		return 0, ErrReviewNotPurchased
+	}
+	req.CategoriesID = categoryID
+
+	if req.Rating < 1 || req.Rating > 5 {
 		s.log.Warn("Оценка должна быть выбрана от 1 до 5",
 			"ваша оценка", req.Rating)
 		return 0, fmt.Errorf("оценка должна быть выбрана от 1 до 5")
 	}
 
+	purchased, err := s.repo.HasPurchase(userID, req.CategoriesID)
+	if err != nil {
+		return 0, err
+	}
+	if !purchased {
+		s.log.Warn("Отзыв без покупки",
+			"user_id", userID,
+			"category_id", req.CategoriesID)
+		return 0, ErrReviewNotPurchased
+	}
+
+	exists, err := s.repo.ExistsForUser(userID, req.TargetType, req.TargetID)
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		s.log.Warn("Повторный отзыв",
+			"user_id", userID,
+			"target_type", req.TargetType,
+			"target_id", req.TargetID)
+		return 0, ErrReviewExists
+	}
+
 	newReview := models.Reviews{
-		UserID:     req.UserID,
+		UserID:     userID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
 		CategoriesID: req.CategoriesID,
 		Rating:     req.Rating,
 		Content:    req.Content,
+		VerifiedPurchase: true,
+	}
+
+	if err := s.moderate(&newReview, models.ReviewApproved); err != nil {
+		return 0, err
 	}
 
 	if err := s.repo.CreateReviews(&newReview); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return 0, ErrReviewExists
+		}
 		s.log.Error("Ошибка при создании отзыва",
 			"error", err.Error())
 		return 0, fmt.Errorf("ошибка при создании отзыва")
 	}
 
+	if err := s.outbox.Enqueue(models.NotificationEvent{
+		EventType: models.EventReviewCreated,
+		UserID:    newReview.UserID,
+		Data: map[string]any{
+			"review_id":   newReview.ID,
+			"category_id": newReview.CategoriesID,
+			"target_type": newReview.TargetType,
+			"target_id":   newReview.TargetID,
+			"rating":      newReview.Rating,
+			"pending":     newReview.Status == models.ReviewPending,
+		},
+	}); err != nil {
+		s.log.Error("Ошибка при постановке уведомления в очередь",
+			"error", err.Error())
+	}
+
 	return newReview.ID, nil
 
 }
@@ -77,13 +179,32 @@ func (s *reviewsService) GetReview(id uint) (*models.GetReview, error) {
 		return nil, fmt.Errorf("ошибка при выводе отзыва: %w", err)
 	}
 
+	if req.Status != models.ReviewApproved {
+		s.log.Warn("Отзыв не опубликован",
+			"id", id,
+			"status", req.Status)
+		return nil, ErrReviewNotFound
+	}
+
+	replies, err := s.repo.ListReplies(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	getReview := &models.GetReview{
 		ID:         req.ID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
 		CategoriesID: req.CategoriesID,
 		UserID:     req.UserID,
 		Rating:     req.Rating,
 		Content:    req.Content,
+		VerifiedPurchase: req.VerifiedPurchase,
 		Date:       req.CreatedAt.Format("2006-01-02 15:04:05"),
+		Helpful:    req.HelpfulCount,
+		NotHelpful: req.NotHelpfulCount,
+		Photos:     attachmentViews(req.Attachments),
+		Replies:    replyTree(replies, nil),
 	}
 
 	s.log.Info("Отзыв получен")
@@ -109,11 +230,17 @@ func (s *reviewsService) GetReviewsByUser(userID uint) ([]models.GetReview, erro
 	for _, review := range reviews {
 		getReview := models.GetReview{
 			ID:         review.ID,
+			TargetType: review.TargetType,
+			TargetID:   review.TargetID,
 			CategoriesID: review.CategoriesID,
 			UserID:     review.UserID,
 			Rating:     review.Rating,
 			Content:    review.Content,
+			VerifiedPurchase: review.VerifiedPurchase,
 			Date:       review.CreatedAt.Format("2006-01-02 15:04:05"),
+			Helpful:    review.HelpfulCount,
+			NotHelpful: review.NotHelpfulCount,
+			Photos:     attachmentViews(review.Attachments),
 		}
 		result = append(result, getReview)
 	}
@@ -124,39 +251,55 @@ func (s *reviewsService) GetReviewsByUser(userID uint) ([]models.GetReview, erro
 	return result, nil
 }
 
-func (s *reviewsService) GetReviewsByCategory(categoryID uint) ([]models.GetReview, error) {
-	if categoryID == 0 {
-		s.log.Warn("ID категории не указан")
-		return nil, fmt.Errorf("ID категории не указан")
+func (s *reviewsService) GetReviewsByCategory(categoryID uint, sort string) ([]models.GetReview, error) {
+	return s.GetReviewsByTarget(models.ReviewTargetCategory, categoryID, sort)
+}
+
+func (s *reviewsService) GetReviewsByTarget(targetType string, targetID uint, sort string) ([]models.GetReview, error) {
+	if targetID == 0 {
+		s.log.Warn("ID объекта отзыва не указан", "target_type", targetType)
+		return nil, fmt.Errorf("ID объекта отзыва не указан")
 	}
 
-	reviews, err := s.repo.GetByCategoryID(categoryID)
+	reviews, err := s.repo.GetByTarget(targetType, targetID, sort)
 	if err != nil {
-		s.log.Error("Ошибка при получении отзывов по категории",
-			"category_id", categoryID,
+		s.log.Error("Ошибка при получении отзывов",
+			"target_type", targetType,
+			"target_id", targetID,
 			"error", err.Error())
-		return nil, fmt.Errorf("ошибка при получении отзывов по категории: %w", err)
+		return nil, fmt.Errorf("ошибка при получении отзывов: %w", err)
 	}
 
 	var result []models.GetReview
 	for _, review := range reviews {
 		getReview := models.GetReview{
 			ID:         review.ID,
+			TargetType: review.TargetType,
+			TargetID:   review.TargetID,
 			CategoriesID: review.CategoriesID,
 			UserID:     review.UserID,
 			Rating:     review.Rating,
 			Content:    review.Content,
+			VerifiedPurchase: review.VerifiedPurchase,
 			Date:       review.CreatedAt.Format("2006-01-02 15:04:05"),
+			Helpful:    review.HelpfulCount,
+			NotHelpful: review.NotHelpfulCount,
+			Photos:     attachmentViews(review.Attachments),
 		}
 		result = append(result, getReview)
 	}
 
-	s.log.Info("Отзывы по категории получены",
-		"category_id", categoryID,
+	s.log.Info("Отзывы получены",
+		"target_type", targetType,
+		"target_id", targetID,
 		"count", len(result))
 	return result, nil
 }
 
+func (s *reviewsService) GetRating(targetType string, targetID uint) (*models.RatingStats, error) {
+	return s.repo.Rating(targetType, targetID)
+}
+
 func (s *reviewsService) UpdateReview(id uint, req models.UpdateReviewRequest, userID uint) error {
 	if id == 0 {
 		s.log.Warn("ID отзыва не указан")
@@ -196,6 +339,15 @@ func (s *reviewsService) UpdateReview(id uint, req models.UpdateReviewRequest, u
 		review.Content = *req.Content
 	}
 
+	// once a moderator or a filter has been involved, edits go back to the queue
+	keep := models.ReviewApproved
+	if review.Status != models.ReviewApproved {
+		keep = models.ReviewPending
+	}
+	if err := s.moderate(review, keep); err != nil {
+		return err
+	}
+
 	if err := s.repo.UpdateReviews(review); err != nil {
 		s.log.Error("Ошибка при обновлении отзыва",
 			"id", id,
@@ -245,3 +397,341 @@ func (s *reviewsService) DeleteReview(id uint, userID uint) error {
 		"id", id)
 	return nil
 }
+
+func (s *reviewsService) RebuildRatings() (int64, error) {
+	return s.repo.RebuildRatings()
+}
+
+// moderate runs the content filters. A flagged review waits in the moderation
+// queue, a clean one gets the clean status passed in.
+func (s *reviewsService) moderate(review *models.Reviews, clean string) error {
+	var flags []string
+	for _, filter := range s.filters {
+		found, err := filter.Check(review)
+		if err != nil {
+			s.log.Error("Ошибка при проверке отзыва",
+				"error", err.Error())
+			return fmt.Errorf("ошибка при проверке отзыва: %w", err)
+		}
+		flags = append(flags, found...)
+	}
+
+	review.FilterFlags = strings.Join(flags, ",")
+	review.Status = clean
+	if len(flags) > 0 {
+		review.Status = models.ReviewPending
+		s.log.Info("Отзыв отправлен на модерацию",
+			"user_id", review.UserID,
+			"flags", review.FilterFlags)
+	}
+
+	return nil
+}
+
+func (s *reviewsService) ListModeration(status string) ([]models.GetReview, error) {
+	if status == "" {
+		status = models.ReviewPending
+	}
+	if status != models.ReviewPending && status != models.ReviewApproved && status != models.ReviewRejected {
+		return nil, fmt.Errorf("unknown status %q", status)
+	}
+
+	reviews, err := s.repo.ListByStatus(status)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.GetReview, 0, len(reviews))
+	for i := range reviews {
+		result = append(result, moderationView(&reviews[i]))
+	}
+
+	return result, nil
+}
+
+func (s *reviewsService) Approve(id uint) (*models.GetReview, error) {
+	return s.setModeration(id, models.ReviewApproved, "")
+}
+
+func (s *reviewsService) Reject(id uint, reason string) (*models.GetReview, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("reason is required")
+	}
+
+	return s.setModeration(id, models.ReviewRejected, reason)
+}
+
+func (s *reviewsService) setModeration(id uint, status, reason string) (*models.GetReview, error) {
+	review, err := s.repo.SetModeration(id, status, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("review moderated", "id", id, "status", status)
+	view := moderationView(review)
+	return &view, nil
+}
+
+func moderationView(review *models.Reviews) models.GetReview {
+	view := models.GetReview{
+		ID:               review.ID,
+		TargetType:       review.TargetType,
+		TargetID:         review.TargetID,
+		CategoriesID:     review.CategoriesID,
+		UserID:           review.UserID,
+		Rating:           review.Rating,
+		Content:          review.Content,
+		VerifiedPurchase: review.VerifiedPurchase,
+		Date:             review.CreatedAt.Format("2006-01-02 15:04:05"),
+		Helpful:          review.HelpfulCount,
+		NotHelpful:       review.NotHelpfulCount,
+		Photos:           attachmentViews(review.Attachments),
+		Status:           review.Status,
+		ModerationReason: review.ModerationReason,
+		Reports:          review.ReportCount,
+	}
+	if review.FilterFlags != "" {
+		view.Flags = strings.Split(review.FilterFlags, ",")
+	}
+
+	return view
+}
+
+func (s *reviewsService) ReplyToReview(reviewID uint, req models.CreateReplyRequest) (*models.GetReviewReply, error) {
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		s.log.Warn("Пустой ответ на отзыв", "review_id", reviewID)
+		return nil, fmt.Errorf("текст ответа не может быть пустым")
+	}
+
+	review, err := s.publishedReview(reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.canReply(review, req.UserID); err != nil {
+		return nil, err
+	}
+
+	if req.ParentID != nil {
+		parent, err := s.repo.GetReply(*req.ParentID)
+		if err != nil || parent.ReviewID != review.ID {
+			s.log.Warn("Ответ на чужую ветку",
+				"review_id", reviewID,
+				"parent_id", *req.ParentID)
+			return nil, fmt.Errorf("ответ %d не относится к этому отзыву", *req.ParentID)
+		}
+	}
+
+	reply := models.ReviewReply{
+		ReviewID: review.ID,
+		ParentID: req.ParentID,
+		UserID:   req.UserID,
+		Content:  content,
+	}
+	if err := s.repo.CreateReply(&reply); err != nil {
+		return nil, fmt.Errorf("ошибка при создании ответа на отзыв: %w", err)
+	}
+
+	if review.UserID != req.UserID {
+		if err := s.outbox.Enqueue(models.NotificationEvent{
+			EventType: models.EventReviewReply,
+			UserID:    review.UserID,
+			Data: map[string]any{
+				"review_id":   review.ID,
+				"category_id": review.CategoriesID,
+				"reply_id":    reply.ID,
+				"content":     reply.Content,
+			},
+		}); err != nil {
+			s.log.Error("Ошибка при постановке уведомления в очередь",
+				"error", err.Error())
+		}
+	}
+
+	view := replyView(&reply)
+	return &view, nil
+}
+
+func (s *reviewsService) DeleteReply(reviewID, replyID, userID uint) error {
+	reply, err := s.repo.GetReply(replyID)
+	if err != nil || reply.ReviewID != reviewID {
+		s.log.Warn("Ответ на отзыв не найден",
+			"review_id", reviewID,
+			"reply_id", replyID)
+		return fmt.Errorf("ответ на отзыв не найден")
+	}
+
+	if reply.UserID != userID {
+		role, err := s.repo.UserRole(userID)
+		if err != nil {
+			return err
+		}
+		if role != models.RoleAdmin {
+			s.log.Warn("Попытка удаления чужого ответа",
+				"user_id", userID,
+				"reply_id", replyID)
+			return ErrReplyForbidden
+		}
+	}
+
+	return s.repo.DeleteReply(replyID)
+}
+
+func (s *reviewsService) VoteReview(reviewID uint, req models.VoteReviewRequest) (*models.GetReview, error) {
+	if req.UserID == 0 {
+		s.log.Warn("ID пользователя не указан")
+		return nil, fmt.Errorf("ID пользователя не указан")
+	}
+
+	review, err := s.publishedReview(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID == req.UserID {
+		return nil, ErrOwnReview
+	}
+
+	review, err = s.repo.Vote(reviewID, req.UserID, req.Helpful)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при голосовании за отзыв: %w", err)
+	}
+
+	s.log.Info("Голос за отзыв учтен",
+		"review_id", reviewID,
+		"user_id", req.UserID)
+	return &models.GetReview{
+		ID:               review.ID,
+		TargetType:       review.TargetType,
+		TargetID:         review.TargetID,
+		CategoriesID:     review.CategoriesID,
+		UserID:           review.UserID,
+		Rating:           review.Rating,
+		Content:          review.Content,
+		VerifiedPurchase: review.VerifiedPurchase,
+		Date:             review.CreatedAt.Format("2006-01-02 15:04:05"),
+		Helpful:          review.HelpfulCount,
+		NotHelpful:       review.NotHelpfulCount,
+	}, nil
+}
+
+func (s *reviewsService) ReportReview(reviewID uint, req models.ReportReviewRequest) error {
+	if req.UserID == 0 {
+		s.log.Warn("ID пользователя не указан")
+		return fmt.Errorf("ID пользователя не указан")
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		s.log.Warn("Жалоба без причины", "review_id", reviewID)
+		return fmt.Errorf("укажите причину жалобы")
+	}
+
+	review, err := s.publishedReview(reviewID)
+	if err != nil {
+		return err
+	}
+	if review.UserID == req.UserID {
+		return ErrOwnReview
+	}
+
+	reported, err := s.repo.HasReported(reviewID, req.UserID)
+	if err != nil {
+		return err
+	}
+	if reported {
+		return ErrReviewReported
+	}
+
+	review, err = s.repo.Report(&models.ReviewReport{
+		ReviewID: reviewID,
+		UserID:   req.UserID,
+		Reason:   reason,
+	}, reviewReportThreshold)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении жалобы: %w", err)
+	}
+
+	if review.Status == models.ReviewPending {
+		s.log.Info("Отзыв отправлен на модерацию по жалобам",
+			"review_id", reviewID,
+			"reports", review.ReportCount)
+	}
+
+	return nil
+}
+
+func (s *reviewsService) publishedReview(id uint) (*models.Reviews, error) {
+	review, err := s.repo.GetReviewsByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	if review.Status != models.ReviewApproved {
+		return nil, ErrReviewNotFound
+	}
+
+	return review, nil
+}
+
+// canReply allows admins and the owner of the reviewed category to answer.
+func (s *reviewsService) canReply(review *models.Reviews, userID uint) error {
+	if userID == 0 {
+		s.log.Warn("ID пользователя не указан")
+		return fmt.Errorf("ID пользователя не указан")
+	}
+
+	role, err := s.repo.UserRole(userID)
+	if err != nil {
+		return err
+	}
+	if role == models.RoleAdmin {
+		return nil
+	}
+
+	ownerID, err := s.repo.CategoryOwnerID(review.CategoriesID)
+	if err != nil {
+		return err
+	}
+	if ownerID == 0 || ownerID != userID {
+		s.log.Warn("Ответ на отзыв без прав",
+			"user_id", userID,
+			"review_id", review.ID)
+		return ErrReplyForbidden
+	}
+
+	return nil
+}
+
+func replyView(reply *models.ReviewReply) models.GetReviewReply {
+	return models.GetReviewReply{
+		ID:       reply.ID,
+		ParentID: reply.ParentID,
+		UserID:   reply.UserID,
+		Content:  reply.Content,
+		Date:     reply.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// replyTree nests replies under their parents, keeping creation order.
+func replyTree(replies []models.ReviewReply, parentID *uint) []models.GetReviewReply {
+	var thread []models.GetReviewReply
+	for i := range replies {
+		reply := &replies[i]
+		if (parentID == nil) != (reply.ParentID == nil) {
+			continue
+		}
+		if parentID != nil && *reply.ParentID != *parentID {
+			continue
+		}
+
+		view := replyView(reply)
+		view.Replies = replyTree(replies, &reply.ID)
+		thread = append(thread, view)
+	}
+
+	return thread
+}
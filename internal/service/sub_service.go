@@ -1,10 +1,12 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"healthy_body/internal/models"
 	"healthy_body/internal/repository"
 	"log/slog"
+	"time"
 )
 
 type SubscriptionService interface {
@@ -13,6 +15,7 @@ type SubscriptionService interface {
 	GetListSub() ([]models.Subscription, error)
 	UpdateSub(id uint, req models.UpdateSubscriptionRequest) (*models.Subscription, error)
 	Delete(id uint) error
+	RunExpiryReminders(ctx context.Context, interval, within time.Duration)
 }
 
 type subscriptionService struct {
@@ -46,6 +49,7 @@ func (s *subscriptionService) CreateSub(req *models.CreateSubscriptionRequest) (
 		Price:         req.Price,
 		CategoriesID: req.CategoriesID,
 		DurationDays: req.DurationDays,
+		TrialDays:    req.TrialDays,
 	}
 
 	if err := s.subRepo.Create(sub); err != nil {
@@ -83,6 +87,10 @@ func (s *subscriptionService) UpdateSub(id uint, req models.UpdateSubscriptionRe
 		return nil, err
 	}
 
+	if req.TrialDays != nil && *req.TrialDays < 0 {
+		return nil, errors.New("negative trial days in sub struct")
+	}
+
 	s.upSub(sub, req)
 	if err := s.subRepo.Update(sub); err != nil {
 		s.log.Error("error update function in sub_service.go")
@@ -115,6 +123,9 @@ func (s *subscriptionService) valiD(req *models.CreateSubscriptionRequest) error
 	if req.DurationDays == 0 {
 		return errors.New("empty duration days in sub struct")
 	}
+	if req.TrialDays < 0 {
+		return errors.New("negative trial days in sub struct")
+	}
 
 	return nil
 }
@@ -135,4 +146,28 @@ func (s *subscriptionService) upSub(sub *models.Subscription, req models.UpdateS
 	if req.DurationDays != nil {
 		sub.DurationDays = *req.DurationDays
 	}
+
+	if req.TrialDays != nil {
+		sub.TrialDays = *req.TrialDays
+	}
+}
+
+func (s *subscriptionService) RunExpiryReminders(ctx context.Context, interval, within time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := s.subRepo.EnqueueExpiryReminders(within)
+		if err != nil {
+			s.log.Error("error RunExpiryReminders in sub_service.go", "err", err)
+		} else if count > 0 {
+			s.log.Info("subscription expiry reminders queued", "count", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
@@ -1,14 +1,15 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"healthy_body/internal/models"
 	"healthy_body/internal/repository"
 	"log/slog"
-	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserService interface {
@@ -19,10 +20,11 @@ type UserService interface {
 	GetUserCategory(userID uint) (*models.User, error)
 	GetUserSub(userID uint) (*models.User, error)
 	UpdateUser(id uint, req models.UpdateUserRequest) (*models.User, error)
+	SetRole(id uint, role string) (*models.User, error)
 	Delete(id uint) error
 
-	Payment(userID uint, categoryID uint) error
-	SubPayment(userID, subID uint) error
+	Payment(userID uint, categoryID uint, promoCode, currency string) error
+	SubPayment(userID, subID uint, promoCode, currency string) error
 	PaymentToAnother(userID uint, categoryID uint, secondUserID uint) error
 }
 
@@ -32,17 +34,19 @@ type userService struct {
 	db           *gorm.DB
 	sub          SubscriptionService
 	categoryRepo repository.CategoryRepo
-	notifier     NotificationService
+	versions     repository.CatalogVersionRepository
+	gifts        GiftService
 }
 
-func NewUserService(userRepo repository.UserRepository, log *slog.Logger, db *gorm.DB, sub SubscriptionService, categoryRepo repository.CategoryRepo, notifier NotificationService) UserService {
+func NewUserService(userRepo repository.UserRepository, log *slog.Logger, db *gorm.DB, sub SubscriptionService, categoryRepo repository.CategoryRepo, versions repository.CatalogVersionRepository, gifts GiftService) UserService {
 	return &userService{
 		userRepo:     userRepo,
 		log:          log,
 		db:           db,
 		sub:          sub,
 		categoryRepo: categoryRepo,
-		notifier:     notifier,
+		versions:     versions,
+		gifts:        gifts,
 	}
 }
 
@@ -56,10 +60,43 @@ func (s *userService) CreateUser(req models.CreateUserRequest) (*models.User, er
 
 	}
 
+	if req.Language == "" {
+		req.Language = models.LanguageRU
+	}
+	if !isSupportedLanguage(req.Language) {
+		s.log.Warn("неподдерживаемый язык", "язык", req.Language)
+		return nil, fmt.Errorf("язык должен быть ru или en")
+	}
+
+	req.Currency = models.NormalizeCurrency(req.Currency)
+	if req.Currency == "" {
+		req.Currency = models.CurrencyRUB
+	}
+	if !models.IsSupportedCurrency(req.Currency) {
+		s.log.Warn("неподдерживаемая валюта", "валюта", req.Currency)
+		return nil, fmt.Errorf("неподдерживаемая валюта %s", req.Currency)
+	}
+
+	if req.Goal != "" && !models.IsValidGoal(req.Goal) {
+		s.log.Warn("неизвестная цель", "цель", req.Goal)
+		return nil, errUnknownGoal
+	}
+
+	if err := validateBodyMetrics(&req.HeightCm, &req.WeightKg); err != nil {
+		s.log.Warn("некорректные рост или вес", "рост", req.HeightCm, "вес", req.WeightKg)
+		return nil, err
+	}
+
 	newUser := &models.User{
 		Name:       req.Name,
 		Balance:    0,
 		Email:      req.Email,
+		Language:   req.Language,
+		Currency:   req.Currency,
+		Role:       models.RoleUser,
+		HeightCm:   req.HeightCm,
+		WeightKg:   req.WeightKg,
+		Goal:       req.Goal,
 		CategoriesID: 2,
 	}
 
@@ -130,6 +167,18 @@ func (s *userService) GetUserPlan(userID uint) (*models.Categories, error) {
 		s.log.Error("invalid user id")
 		return nil, err
 	}
+
+	var userPlan models.UserPlan
+	err = s.db.Where("user_id = ? AND categories_id = ? AND category_version > 0", user.ID, user.CategoriesID).
+		Order("id DESC").First(&userPlan).Error
+	if err == nil {
+		return s.purchasedCategory(userPlan)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.log.Error("invalid user plan", "error", err.Error())
+		return nil, err
+	}
+
 	category, err := s.categoryRepo.GetWithPlans(user.CategoriesID)
 	if err != nil {
 		s.log.Error("invalid user category id")
@@ -139,6 +188,26 @@ func (s *userService) GetUserPlan(userID uint) (*models.Categories, error) {
 	return category, nil
 }
 
+func (s *userService) purchasedCategory(userPlan models.UserPlan) (*models.Categories, error) {
+	version, err := s.versions.GetVersion(models.CatalogCategory, userPlan.CategoriesID, userPlan.CategoryVersion)
+	if err != nil {
+		s.log.Error("Ошибка при получении купленной версии категории",
+			"category_id", userPlan.CategoriesID,
+			"version", userPlan.CategoryVersion,
+			"error", err.Error())
+		return nil, err
+	}
+
+	var category models.Categories
+	if err := json.Unmarshal(version.Snapshot, &category); err != nil {
+		s.log.Error("Ошибка при чтении купленной версии категории",
+			"error", err.Error())
+		return nil, err
+	}
+
+	return &category, nil
+}
+
 func (s *userService) GetUserCategory(userID uint) (*models.User, error) {
 	user, err := s.userRepo.GeUserCategory(userID)
 	if err != nil {
@@ -161,7 +230,8 @@ func (s *userService) GetUserSub(userID uint) (*models.User, error) {
 
 func (s *userService) UpdateUser(id uint, req models.UpdateUserRequest) (*models.User, error) {
 
-	if req.Name == nil && req.Balance == nil {
+	if req.Name == nil && req.Balance == nil && req.Email == nil && req.Language == nil && req.Currency == nil &&
+		req.HeightCm == nil && req.WeightKg == nil && req.Goal == nil {
 		s.log.Warn("Нет полей для обновления", "id", id)
 		return nil, fmt.Errorf("не указаны поля для обновления")
 	}
@@ -195,6 +265,37 @@ func (s *userService) UpdateUser(id uint, req models.UpdateUserRequest) (*models
 		}
 	}
 
+	if req.Language != nil && !isSupportedLanguage(*req.Language) {
+		s.log.Warn("Неподдерживаемый язык",
+			"id", id,
+			"language", *req.Language)
+		return nil, fmt.Errorf("язык должен быть ru или en")
+	}
+
+	if req.Currency != nil {
+		currency := models.NormalizeCurrency(*req.Currency)
+		if !models.IsSupportedCurrency(currency) {
+			s.log.Warn("Неподдерживаемая валюта",
+				"id", id,
+				"currency", *req.Currency)
+			return nil, fmt.Errorf("неподдерживаемая валюта %s", *req.Currency)
+		}
+		req.Currency = &currency
+	}
+
+	if req.Goal != nil && *req.Goal != "" && !models.IsValidGoal(*req.Goal) {
+		s.log.Warn("Неизвестная цель",
+			"id", id,
+			"goal", *req.Goal)
+		return nil, errUnknownGoal
+	}
+
+	if err := validateBodyMetrics(req.HeightCm, req.WeightKg); err != nil {
+		s.log.Warn("Некорректные рост или вес",
+			"id", id)
+		return nil, err
+	}
+
 	user, err := s.GetUserByID(id)
 
 	if err != nil {
@@ -212,6 +313,21 @@ func (s *userService) UpdateUser(id uint, req models.UpdateUserRequest) (*models
 	if req.Email != nil {
 		user.Email = *req.Email
 	}
+	if req.Language != nil {
+		user.Language = *req.Language
+	}
+	if req.Currency != nil {
+		user.Currency = *req.Currency
+	}
+	if req.HeightCm != nil {
+		user.HeightCm = *req.HeightCm
+	}
+	if req.WeightKg != nil {
+		user.WeightKg = *req.WeightKg
+	}
+	if req.Goal != nil {
+		user.Goal = *req.Goal
+	}
 
 	if err := s.userRepo.Update(user); err != nil {
 		s.log.Error("Ошибка при обновлении пользователя",
@@ -228,6 +344,34 @@ func (s *userService) UpdateUser(id uint, req models.UpdateUserRequest) (*models
 	return user, nil
 }
 
+// SetRole changes the role of a user. Callers check that an admin asked for it.
+func (s *userService) SetRole(id uint, role string) (*models.User, error) {
+	if !models.IsValidRole(role) {
+		s.log.Warn("Неизвестная роль",
+			"id", id,
+			"role", role)
+		return nil, fmt.Errorf("роль должна быть user, coach или admin")
+	}
+
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при поиске пользователя %w", err)
+	}
+
+	user.Role = role
+	if err := s.userRepo.Update(user); err != nil {
+		s.log.Error("Ошибка при смене роли",
+			"id", id,
+			"error", err.Error())
+		return nil, fmt.Errorf("ошибка при смене роли %w", err)
+	}
+
+	s.log.Info("Роль пользователя изменена",
+		"id", id,
+		"role", role)
+	return user, nil
+}
+
 func (s *userService) Delete(id uint) error {
 
 	if err := s.userRepo.Delete(id); err != nil {
@@ -241,11 +385,19 @@ func (s *userService) Delete(id uint) error {
 }
 
 func (s *userService) PaymentToAnother(userID uint, categoryID uint, secondUserID uint) error {
+	_, err := s.gifts.Send(userID, models.CreateGiftRequest{
+		CategoriesID: categoryID,
+		RecipientID:  &secondUserID,
+	})
+	return err
+}
+
+func (s *userService) Payment(userID uint, categoryID uint, promoCode, currency string) error {
 	err := s.db.Transaction(func(tx *gorm.DB) error {
+
 		var user models.User
-		var userSec models.User
 
-		if err := tx.First(&user, userID).Error; err != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
 			s.log.Error("Ошибка при поиске пользователя",
 				"error", err.Error())
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -254,15 +406,6 @@ func (s *userService) PaymentToAnother(userID uint, categoryID uint, secondUserI
 			return fmt.Errorf("ошибка при поиске пользователя %w", err)
 		}
 
-		if err := tx.First(&userSec, secondUserID).Error; err != nil {
-			s.log.Error("Ошибка при поиске второго пользователя",
-				"error", err.Error())
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return fmt.Errorf("второй пользователь не найден")
-			}
-			return fmt.Errorf("ошибка при поиске второго пользователя %w", err)
-		}
-
 		var category models.Categories
 
 		if err := tx.First(&category, categoryID).Error; err != nil {
@@ -274,83 +417,60 @@ func (s *userService) PaymentToAnother(userID uint, categoryID uint, secondUserI
 			return fmt.Errorf("ошибка при поиске категории %w", err)
 		}
 
-		if user.Balance < category.Price {
-			s.log.Warn("Недостаточно средств на счету")
-			return fmt.Errorf("недостаточно средств на счету")
-		}
-
-		user.Balance -= category.Price
-		userSec.CategoriesID = categoryID
-
-		userPlan := &models.UserPlan{
-			UserID:     secondUserID,
-			CategoriesID: categoryID,
-		}
-
-		if err := tx.Create(&userPlan).Error; err != nil {
-			s.log.Error("Ошибка при записи покупки пользователя",
-				"error", err.Error())
-			return fmt.Errorf("ошибка при записи покупки пользователя %w", err)
-		}
-
-		if err := tx.Save(&user).Error; err != nil {
-			s.log.Error("Ошибка при сохранении пользователя",
-				"error", err.Error())
-			return fmt.Errorf("ошибка при сохранении пользователя %w", err)
+		if category.Status != models.StatusPublished {
+			s.log.Warn("Категория не опубликована",
+				"category_id", category.ID,
+				"status", category.Status)
+			return fmt.Errorf("категория недоступна для покупки")
 		}
-		if err := tx.Save(&userSec).Error; err != nil {
-			s.log.Error("Ошибка при сохранении пользователя",
-				"error", err.Error())
-			return fmt.Errorf("ошибка при сохранении пользователя %w", err)
-		}
-
-		s.log.Info("Оплата прошла успешно")
 
-		if err := s.notifier.SendPaymentSuccess(&user, &category); err != nil {
-			s.log.Error("не удалось отправить уведомление", "err", err)
+		currency, err := purchaseCurrency(&user, currency)
+		if err != nil {
+			s.log.Warn("Валюта оплаты не совпадает", "error", err.Error())
+			return err
 		}
 
-		return nil
-	})
-	return err
-}
-
-func (s *userService) Payment(userID uint, categoryID uint) error {
-	err := s.db.Transaction(func(tx *gorm.DB) error {
-
-		var user models.User
-
-		if err := tx.First(&user, userID).Error; err != nil {
-			s.log.Error("Ошибка при поиске пользователя",
-				"error", err.Error())
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return fmt.Errorf("пользователь не найден")
-			}
-			return fmt.Errorf("ошибка при поиске пользователя %w", err)
+		listPrice, err := priceFor(tx, models.TargetCategory, category.ID, category.Price, currency)
+		if err != nil {
+			s.log.Warn("Цена не найдена", "error", err.Error())
+			return err
 		}
 
-		var category models.Categories
-
-		if err := tx.First(&category, categoryID).Error; err != nil {
-			s.log.Error("Ошибка при поиске категории",
-				"error", err.Error())
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return fmt.Errorf("категория не найдена")
+		price := listPrice
+		var discount int64
+		var promo *models.PromoCode
+		if promoCode != "" {
+			promo, discount, err = applyPromo(tx, promoCode, user.ID, models.TargetCategory, category.ID, price, currency, true)
+			if err != nil {
+				s.log.Warn("Промокод не применен", "code", promoCode, "error", err.Error())
+				return err
 			}
-			return fmt.Errorf("ошибка при поиске категории %w", err)
+			price -= discount
 		}
 
-		if user.Balance < category.Price {
-			s.log.Warn("Недостаточно средств на счету")
-			return fmt.Errorf("недостаточно средств на счету")
+		if err := debitWallet(tx, &user, price, currency); err != nil {
+			s.log.Warn("Не удалось списать средства", "error", err.Error())
+			return err
 		}
 
-		user.Balance -= category.Price
 		user.CategoriesID = categoryID
 
 		userPlan := &models.UserPlan{
 			UserID:     user.ID,
 			CategoriesID: user.CategoriesID,
+			CategoryVersion: category.Version,
+			Currency:   currency,
+			PricePaid:  price,
+			Discount:   discount,
+		}
+
+		if promo != nil {
+			userPlan.PromoCodeID = &promo.ID
+			if err := redeemPromo(tx, promo, user.ID, models.TargetCategory, category.ID, discount, currency); err != nil {
+				s.log.Error("Ошибка при применении промокода",
+					"error", err.Error())
+				return fmt.Errorf("ошибка при применении промокода %w", err)
+			}
 		}
 
 		if err := tx.Create(&userPlan).Error; err != nil {
@@ -359,27 +479,56 @@ func (s *userService) Payment(userID uint, categoryID uint) error {
 			return fmt.Errorf("ошибка при записи покупки пользователя %w", err)
 		}
 
-		if err := tx.Save(&user).Error; err != nil {
+		if err := tx.Model(&user).UpdateColumn("categories_id", user.CategoriesID).Error; err != nil {
 			s.log.Error("Ошибка при сохранении пользователя",
 				"error", err.Error())
 			return fmt.Errorf("ошибка при сохранении пользователя %w", err)
 		}
 
-		s.log.Info("Оплата прошла успешно")
-    
-		if err := s.notifier.SendPaymentSuccess(&user, &category); err != nil {
-			s.log.Error("не удалось отправить уведомление", "err", err)
+		if err := createOrder(tx, &models.Order{
+			UserID:      user.ID,
+			PromoCodeID: userPlan.PromoCodeID,
+			Currency:    currency,
+			Items: []models.OrderItem{{
+				TargetType: models.TargetCategory,
+				TargetID:   category.ID,
+				Name:       category.Name,
+				ListPrice:  listPrice,
+				Discount:   discount,
+				FinalPrice: price,
+				UserPlanID: &userPlan.ID,
+			}},
+		}); err != nil {
+			s.log.Error("Ошибка при создании заказа",
+				"error", err.Error())
+			return fmt.Errorf("ошибка при создании заказа %w", err)
 		}
+
+		if err := enqueueNotification(tx, models.NotificationEvent{
+			EventType: models.EventPaymentSuccess,
+			UserID:    user.ID,
+			Data: map[string]any{
+				"category_id":   category.ID,
+				"category_name": category.Name,
+			},
+		}); err != nil {
+			s.log.Error("Ошибка при постановке уведомления в очередь",
+				"error", err.Error())
+			return fmt.Errorf("ошибка при постановке уведомления в очередь %w", err)
+		}
+
+		s.log.Info("Оплата прошла успешно")
+
 		return nil
 	})
 	return err
 }
 
-func (s *userService) SubPayment(userID, subID uint) error {
+func (s *userService) SubPayment(userID, subID uint, promoCode, currency string) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
 
 		var user models.User
-		if err := tx.First(&user, userID).Error; err != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return fmt.Errorf("пользователь не найден")
 			}
@@ -391,28 +540,130 @@ func (s *userService) SubPayment(userID, subID uint) error {
 			return fmt.Errorf("subscription not found: %w", err)
 		}
 
-		if user.Balance < sub.Price {
-			return fmt.Errorf("недостаточно средств")
+		currency, err := purchaseCurrency(&user, currency)
+		if err != nil {
+			return err
 		}
 
-		user.Balance -= sub.Price
+		listPrice, err := priceFor(tx, models.TargetSubscription, sub.ID, sub.Price, currency)
+		if err != nil {
+			return err
+		}
 
-		if err := tx.Save(&user).Error; err != nil {
-			return fmt.Errorf("cannot update user balance: %w", err)
+		trial, err := trialAvailable(tx, userID, sub)
+		if err != nil {
+			return fmt.Errorf("cannot check trial: %w", err)
+		}
+		if trial {
+			if promoCode != "" {
+				return fmt.Errorf("промокод нельзя применить к пробному периоду")
+			}
+
+			userSub := &models.UserSubscription{UserID: userID, Currency: currency, Trial: true}
+			if err := activateSubscription(tx, userSub, sub); err != nil {
+				return fmt.Errorf("cannot start trial: %w", err)
+			}
+
+			return enqueueNotification(tx, models.NotificationEvent{
+				EventType: models.EventSubscriptionActivated,
+				UserID:    userID,
+				Data: map[string]any{
+					"subscription_name": sub.Name,
+					"end_date":          userSub.EndDate.Format("02.01.2006"),
+					"trial":             true,
+				},
+			})
+		}
+
+		price := listPrice
+		var discount int64
+		var promo *models.PromoCode
+		if promoCode != "" {
+			promo, discount, err = applyPromo(tx, promoCode, userID, models.TargetSubscription, sub.ID, price, currency, true)
+			if err != nil {
+				return err
+			}
+			price -= discount
+		}
+
+		if err := debitWallet(tx, &user, price, currency); err != nil {
+			return err
 		}
 
 		userSub := &models.UserSubscription{
-			UserID:         userID,
-			SubscriptionID: subID,
-			StartDate:      time.Now(),
-			EndDate:        time.Now().Add(time.Hour * 24 * time.Duration(sub.DurationDays)),
-			IsActive:       true,
+			UserID:    userID,
+			Currency:  currency,
+			PricePaid: price,
+			Discount:  discount,
 		}
 
-		if err := tx.Create(&userSub).Error; err != nil {
+		if promo != nil {
+			userSub.PromoCodeID = &promo.ID
+			if err := redeemPromo(tx, promo, userID, models.TargetSubscription, sub.ID, discount, currency); err != nil {
+				return fmt.Errorf("cannot redeem promo code: %w", err)
+			}
+		}
+
+		if err := activateSubscription(tx, userSub, sub); err != nil {
 			return fmt.Errorf("cannot create user subscription: %w", err)
 		}
 
+		if err := createOrder(tx, &models.Order{
+			UserID:      userID,
+			PromoCodeID: userSub.PromoCodeID,
+			Currency:    currency,
+			Items: []models.OrderItem{{
+				TargetType:         models.TargetSubscription,
+				TargetID:           sub.ID,
+				Name:               sub.Name,
+				ListPrice:          listPrice,
+				Discount:           discount,
+				FinalPrice:         price,
+				UserSubscriptionID: &userSub.ID,
+			}},
+		}); err != nil {
+			return fmt.Errorf("cannot create order: %w", err)
+		}
+
+		if err := enqueueNotification(tx, models.NotificationEvent{
+			EventType: models.EventSubscriptionActivated,
+			UserID:    userID,
+			Data: map[string]any{
+				"subscription_name": sub.Name,
+				"end_date":          userSub.EndDate.Format("02.01.2006"),
+			},
+		}); err != nil {
+			return fmt.Errorf("cannot enqueue notification: %w", err)
+		}
+
 		return nil
 	})
 }
+
+func enqueueNotification(tx *gorm.DB, event models.NotificationEvent) error {
+	message, err := models.NewOutboxMessage(event)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(message).Error
+}
+
+func isSupportedLanguage(language string) bool {
+	return language == models.LanguageRU || language == models.LanguageEN
+}
+
+var errUnknownGoal = fmt.Errorf("цель должна быть lose_weight, gain_muscle, maintain или endurance")
+
+// validateBodyMetrics checks height and weight when they are given; zero
+// clears the value.
+func validateBodyMetrics(heightCm, weightKg *float64) error {
+	if heightCm != nil && *heightCm != 0 && (*heightCm < 50 || *heightCm > 272) {
+		return fmt.Errorf("рост должен быть от 50 до 272 см")
+	}
+	if weightKg != nil && *weightKg != 0 && (*weightKg < 20 || *weightKg > 500) {
+		return fmt.Errorf("вес должен быть от 20 до 500 кг")
+	}
+
+	return nil
+}
@@ -0,0 +1,564 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSubscriptionExists = errors.New("у пользователя уже есть действующая подписка на эту категорию")
+	ErrNoAccess           = errors.New("нет доступа: купите категорию или оформите подписку на нее")
+	ErrNotOnSale          = errors.New("тариф недоступен: категория не опубликована")
+)
+
+type UserSubscriptionService interface {
+	List(userID uint) ([]models.UserSubscription, error)
+	Entitlements(userID uint) ([]models.Entitlement, error)
+	HasAccess(userID, categoryID uint) (bool, error)
+	CanView(userID uint, targetType string, targetID uint) error
+	Change(userID, userSubID uint, req models.ChangeSubscriptionRequest) (*models.SubscriptionChange, error)
+	Pause(userID, userSubID uint) (*models.UserSubscription, error)
+	Resume(userID, userSubID uint) (*models.UserSubscription, error)
+	Cancel(userID, userSubID uint) (*models.UserSubscription, error)
+	Reactivate(userID, userSubID uint) (*models.UserSubscription, error)
+	RenewDue() (int, error)
+	RunRenewals(ctx context.Context, interval time.Duration)
+}
+
+type userSubscriptionService struct {
+	repo  repository.UserSubscriptionRepository
+	users repository.UserRepository
+	db    *gorm.DB
+	log   *slog.Logger
+}
+
+func NewUserSubscriptionService(repo repository.UserSubscriptionRepository, users repository.UserRepository, db *gorm.DB, log *slog.Logger) UserSubscriptionService {
+	return &userSubscriptionService{
+		repo:  repo,
+		users: users,
+		db:    db,
+		log:   log,
+	}
+}
+
+func (s *userSubscriptionService) List(userID uint) ([]models.UserSubscription, error) {
+	if _, err := s.users.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListByUser(userID)
+}
+
+func (s *userSubscriptionService) Entitlements(userID uint) ([]models.Entitlement, error) {
+	if _, err := s.users.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Entitlements(userID)
+}
+
+func (s *userSubscriptionService) HasAccess(userID, categoryID uint) (bool, error) {
+	entitlements, err := s.Entitlements(userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, entitlement := range entitlements {
+		if entitlement.CategoriesID == categoryID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// CanView lets userID open the content of a catalog item when the user is
+// entitled to the category the item is sold in.
+func (s *userSubscriptionService) CanView(userID uint, targetType string, targetID uint) error {
+	categoryID, err := s.repo.CatalogCategory(targetType, targetID)
+	if err != nil {
+		return err
+	}
+	if categoryID == 0 {
+		return ErrNoAccess
+	}
+
+	access, err := s.HasAccess(userID, categoryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNoAccess
+	}
+	if err != nil {
+		return err
+	}
+	if !access {
+		return ErrNoAccess
+	}
+
+	return nil
+}
+
+// Change moves a running subscription to another plan until the same EndDate.
+// The unused share of the current period is credited against the price of the
+// new plan for the remaining time; the difference is charged or refunded.
+func (s *userSubscriptionService) Change(userID, userSubID uint, req models.ChangeSubscriptionRequest) (*models.SubscriptionChange, error) {
+	result := &models.SubscriptionChange{}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("пользователь не найден")
+			}
+			return err
+		}
+
+		current, err := lockUserSubscription(tx, userID, userSubID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if current.Status != models.SubStatusActive || !current.EndDate.After(now) {
+			return fmt.Errorf("сменить тариф можно только у действующей подписки")
+		}
+		if current.Trial {
+			return fmt.Errorf("во время пробного периода сменить тариф нельзя")
+		}
+		if current.SubscriptionID == req.SubscriptionID {
+			return fmt.Errorf("подписка уже оформлена на этот тариф")
+		}
+
+		var plan models.Subscription
+		if err := tx.First(&plan, req.SubscriptionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("тариф не найден")
+			}
+			return err
+		}
+		if plan.DurationDays < 1 {
+			return fmt.Errorf("у тарифа не задан срок действия")
+		}
+		if plan.CategoriesID != current.CategoriesID {
+			return fmt.Errorf("сменить тариф можно только в пределах той же категории")
+		}
+		if err := ensureOnSale(tx, &plan); err != nil {
+			return err
+		}
+
+		currency := current.Currency
+		if _, err := purchaseCurrency(&user, currency); err != nil {
+			return err
+		}
+
+		price, err := priceFor(tx, models.TargetSubscription, plan.ID, plan.Price, currency)
+		if err != nil {
+			return err
+		}
+
+		remaining := current.EndDate.Sub(now)
+		credit := prorate(current.PricePaid, remaining, current.EndDate.Sub(current.StartDate))
+		cost := prorate(price, remaining, time.Duration(plan.DurationDays)*24*time.Hour)
+
+		result.Currency = currency
+		result.Credit = credit
+		result.Cost = cost
+		if cost > credit {
+			result.Charged = cost - credit
+			if err := debitWallet(tx, &user, result.Charged, currency); err != nil {
+				return err
+			}
+		} else if credit > cost {
+			result.Refunded = credit - cost
+			if err := creditWallet(tx, userID, result.Refunded, currency); err != nil {
+				return err
+			}
+
+			// every renewal adds an order item, only the current period's is refunded
+			paid := tx.Model(&models.OrderItem{}).Select("id").
+				Where("user_subscription_id = ?", current.ID).Order("id DESC").Limit(1)
+			if err := reverseCoachEarnings(tx, paid, result.Refunded); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(current).Updates(map[string]any{
+			"status":    models.SubStatusChanged,
+			"is_active": false,
+			"end_date":  now,
+		}).Error; err != nil {
+			return err
+		}
+
+		next := models.UserSubscription{
+			UserID:            userID,
+			SubscriptionID:    plan.ID,
+			CategoriesID:      plan.CategoriesID,
+			StartDate:         now,
+			EndDate:           current.EndDate,
+			IsActive:          true,
+			Status:            models.SubStatusActive,
+			CancelAtPeriodEnd: current.CancelAtPeriodEnd,
+			Currency:          currency,
+			PricePaid:         cost,
+		}
+		if err := tx.Create(&next).Error; err != nil {
+			return err
+		}
+
+		if result.Charged > 0 {
+			if err := createOrder(tx, &models.Order{
+				UserID:   userID,
+				Currency: currency,
+				Items: []models.OrderItem{{
+					TargetType:         models.TargetSubscription,
+					TargetID:           plan.ID,
+					Name:               plan.Name,
+					ListPrice:          cost,
+					Discount:           cost - result.Charged,
+					FinalPrice:         result.Charged,
+					UserSubscriptionID: &next.ID,
+				}},
+			}); err != nil {
+				return fmt.Errorf("ошибка при создании заказа %w", err)
+			}
+		}
+
+		result.Previous = *current
+		result.Subscription = next
+		return nil
+	})
+	if err != nil {
+		s.log.Error("Ошибка при смене тарифа", "user_id", userID, "user_subscription_id", userSubID, "error", err.Error())
+		return nil, err
+	}
+
+	s.log.Info("Тариф подписки изменен", "user_id", userID, "subscription_id", req.SubscriptionID,
+		"charged", result.Charged, "refunded", result.Refunded)
+	return result, nil
+}
+
+func (s *userSubscriptionService) Pause(userID, userSubID uint) (*models.UserSubscription, error) {
+	return s.update(userID, userSubID, func(tx *gorm.DB, userSub *models.UserSubscription) error {
+		now := time.Now()
+		if userSub.Status != models.SubStatusActive || !userSub.EndDate.After(now) {
+			return fmt.Errorf("приостановить можно только действующую подписку")
+		}
+		if userSub.Trial {
+			return fmt.Errorf("пробный период нельзя приостановить")
+		}
+
+		userSub.Status = models.SubStatusPaused
+		userSub.IsActive = false
+		userSub.PausedAt = &now
+		return tx.Model(userSub).Updates(map[string]any{
+			"status":    userSub.Status,
+			"is_active": false,
+			"paused_at": now,
+		}).Error
+	})
+}
+
+// Resume moves EndDate forward by the time the subscription spent paused.
+func (s *userSubscriptionService) Resume(userID, userSubID uint) (*models.UserSubscription, error) {
+	return s.update(userID, userSubID, func(tx *gorm.DB, userSub *models.UserSubscription) error {
+		if userSub.Status != models.SubStatusPaused || userSub.PausedAt == nil {
+			return fmt.Errorf("подписка не приостановлена")
+		}
+
+		userSub.EndDate = userSub.EndDate.Add(time.Since(*userSub.PausedAt))
+		userSub.Status = models.SubStatusActive
+		userSub.IsActive = true
+		userSub.PausedAt = nil
+		return tx.Model(userSub).Updates(map[string]any{
+			"status":             userSub.Status,
+			"is_active":          true,
+			"paused_at":          nil,
+			"end_date":           userSub.EndDate,
+			"expiry_notified_at": nil,
+		}).Error
+	})
+}
+
+func (s *userSubscriptionService) Cancel(userID, userSubID uint) (*models.UserSubscription, error) {
+	return s.setCancelAtPeriodEnd(userID, userSubID, true)
+}
+
+func (s *userSubscriptionService) Reactivate(userID, userSubID uint) (*models.UserSubscription, error) {
+	return s.setCancelAtPeriodEnd(userID, userSubID, false)
+}
+
+func (s *userSubscriptionService) setCancelAtPeriodEnd(userID, userSubID uint, cancel bool) (*models.UserSubscription, error) {
+	return s.update(userID, userSubID, func(tx *gorm.DB, userSub *models.UserSubscription) error {
+		if userSub.Status != models.SubStatusActive && userSub.Status != models.SubStatusPaused {
+			return fmt.Errorf("подписка уже завершена")
+		}
+
+		userSub.CancelAtPeriodEnd = cancel
+		return tx.Model(userSub).UpdateColumn("cancel_at_period_end", cancel).Error
+	})
+}
+
+func (s *userSubscriptionService) update(userID, userSubID uint, apply func(tx *gorm.DB, userSub *models.UserSubscription) error) (*models.UserSubscription, error) {
+	var userSub *models.UserSubscription
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		userSub, err = lockUserSubscription(tx, userID, userSubID)
+		if err != nil {
+			return err
+		}
+
+		return apply(tx, userSub)
+	})
+	if err != nil {
+		s.log.Warn("Ошибка при изменении подписки", "user_id", userID, "user_subscription_id", userSubID, "error", err.Error())
+		return nil, err
+	}
+
+	return userSub, nil
+}
+
+// RenewDue closes every active subscription whose period is over. It is renewed
+// for another period from the wallet unless it was cancelled at period end or
+// the wallet cannot cover the price. An ending trial converts to paid the same way.
+func (s *userSubscriptionService) RenewDue() (int, error) {
+	ids, err := s.repo.ListDueRenewals(100)
+	if err != nil {
+		return 0, err
+	}
+
+	renewed := 0
+	for _, id := range ids {
+		ok, err := s.renew(id)
+		if err != nil {
+			s.log.Error("error renew in user_subscription_service.go", "user_subscription_id", id, "err", err)
+			continue
+		}
+		if ok {
+			renewed++
+		}
+	}
+
+	return renewed, nil
+}
+
+func (s *userSubscriptionService) renew(userSubID uint) (bool, error) {
+	renewed := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var userSub models.UserSubscription
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND end_date <= ?", models.SubStatusActive, time.Now()).
+			First(&userSub, userSubID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		expire := func(reason string) error {
+			s.log.Info("Подписка завершена", "user_subscription_id", userSub.ID, "reason", reason)
+			return tx.Model(&userSub).Updates(map[string]any{
+				"status":    models.SubStatusExpired,
+				"is_active": false,
+			}).Error
+		}
+
+		if userSub.CancelAtPeriodEnd {
+			return expire("cancelled")
+		}
+
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userSub.UserID).Error; err != nil {
+			return err
+		}
+
+		var plan models.Subscription
+		if err := tx.First(&plan, userSub.SubscriptionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return expire("plan removed")
+			}
+			return err
+		}
+		if plan.DurationDays < 1 {
+			return expire("plan has no duration")
+		}
+
+		price, err := priceFor(tx, models.TargetSubscription, plan.ID, plan.Price, userSub.Currency)
+		if err != nil {
+			return expire(err.Error())
+		}
+
+		// a failed debit must not undo the expiry below
+		if err := tx.Transaction(func(debit *gorm.DB) error {
+			return debitWallet(debit, &user, price, userSub.Currency)
+		}); err != nil {
+			return expire(err.Error())
+		}
+
+		start := userSub.EndDate
+		userSub.StartDate = start
+		userSub.EndDate = start.Add(time.Duration(plan.DurationDays) * 24 * time.Hour)
+		userSub.PricePaid = price
+		userSub.Discount = 0
+		userSub.PromoCodeID = nil
+		userSub.Trial = false
+		if err := tx.Model(&userSub).Updates(map[string]any{
+			"trial":              false,
+			"start_date":         userSub.StartDate,
+			"end_date":           userSub.EndDate,
+			"price_paid":         price,
+			"discount":           0,
+			"promo_code_id":      nil,
+			"expiry_notified_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := createOrder(tx, &models.Order{
+			UserID:   userSub.UserID,
+			Currency: userSub.Currency,
+			Items: []models.OrderItem{{
+				TargetType:         models.TargetSubscription,
+				TargetID:           plan.ID,
+				Name:               plan.Name,
+				ListPrice:          price,
+				FinalPrice:         price,
+				UserSubscriptionID: &userSub.ID,
+			}},
+		}); err != nil {
+			return err
+		}
+
+		renewed = true
+		return enqueueNotification(tx, models.NotificationEvent{
+			EventType: models.EventSubscriptionActivated,
+			UserID:    userSub.UserID,
+			Data: map[string]any{
+				"subscription_name": plan.Name,
+				"end_date":          userSub.EndDate.Format("02.01.2006"),
+			},
+		})
+	})
+
+	return renewed, err
+}
+
+func (s *userSubscriptionService) RunRenewals(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if count, err := s.RenewDue(); err == nil && count > 0 {
+			s.log.Info("Подписки продлены", "count", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func lockUserSubscription(tx *gorm.DB, userID, userSubID uint) (*models.UserSubscription, error) {
+	var userSub models.UserSubscription
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).First(&userSub, userSubID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("подписка не найдена")
+		}
+		return nil, err
+	}
+
+	return &userSub, nil
+}
+
+// activateSubscription records a new subscription period together with the
+// category entitlement it grants. A user holds at most one running
+// subscription per category; switching plans within it goes through Change.
+// Callers lock the user row.
+func activateSubscription(tx *gorm.DB, userSub *models.UserSubscription, plan *models.Subscription) error {
+	if err := ensureOnSale(tx, plan); err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	var count int64
+	if err := tx.Model(&models.UserSubscription{}).
+		Where("user_id = ? AND categories_id = ? AND status IN ? AND end_date > ?",
+			userSub.UserID, plan.CategoriesID, []string{models.SubStatusActive, models.SubStatusPaused}, now).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSubscriptionExists
+	}
+
+	userSub.SubscriptionID = plan.ID
+	userSub.CategoriesID = plan.CategoriesID
+	userSub.StartDate = now
+	userSub.EndDate = now.Add(time.Hour * 24 * time.Duration(plan.DurationDays))
+	if userSub.Trial {
+		userSub.EndDate = now.Add(time.Hour * 24 * time.Duration(plan.TrialDays))
+		userSub.TrialEndsAt = &userSub.EndDate
+	}
+	userSub.IsActive = true
+	userSub.Status = models.SubStatusActive
+
+	return tx.Create(userSub).Error
+}
+
+// ensureOnSale rejects plans whose category is not published: drafts and
+// archived categories cannot be bought or switched to.
+func ensureOnSale(tx *gorm.DB, plan *models.Subscription) error {
+	var category models.Categories
+	if err := tx.Select("id", "status").First(&category, plan.CategoriesID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotOnSale
+		}
+		return err
+	}
+	if category.Status != models.StatusPublished {
+		return ErrNotOnSale
+	}
+	return nil
+}
+
+// trialAvailable reports whether the user may start the plan's free trial.
+// A trial is offered once per user and plan, cancelled trials included.
+func trialAvailable(tx *gorm.DB, userID uint, plan *models.Subscription) (bool, error) {
+	if plan.TrialDays < 1 {
+		return false, nil
+	}
+
+	var count int64
+	if err := tx.Unscoped().Model(&models.UserSubscription{}).
+		Where("user_id = ? AND subscription_id = ? AND trial_ends_at IS NOT NULL", userID, plan.ID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count == 0, nil
+}
+
+// prorate returns the share of amount that corresponds to part of period.
+func prorate(amount int64, part, period time.Duration) int64 {
+	if period <= 0 || part <= 0 {
+		return 0
+	}
+	if part >= period {
+		return amount
+	}
+
+	return amount * int64(part/time.Second) / int64(period/time.Second)
+}
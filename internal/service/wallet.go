@@ -0,0 +1,160 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"log/slog"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WalletService interface {
+	Balances(userID uint) ([]models.Money, error)
+	TopUp(userID uint, req models.TopUpRequest) ([]models.Money, error)
+}
+
+type walletService struct {
+	users repository.UserRepository
+	db    *gorm.DB
+	log   *slog.Logger
+}
+
+func NewWalletService(users repository.UserRepository, db *gorm.DB, log *slog.Logger) WalletService {
+	return &walletService{
+		users: users,
+		db:    db,
+		log:   log,
+	}
+}
+
+func (s *walletService) Balances(userID uint) ([]models.Money, error) {
+	user, err := s.users.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var balances []models.UserBalance
+	if err := s.db.Where("user_id = ?", user.ID).Order("currency").Find(&balances).Error; err != nil {
+		s.log.Error("Ошибка при получении балансов", "user_id", userID, "error", err.Error())
+		return nil, err
+	}
+
+	result := []models.Money{{Amount: user.Balance, Currency: models.CurrencyRUB}}
+	for _, balance := range balances {
+		result = append(result, models.Money{Amount: balance.Amount, Currency: balance.Currency})
+	}
+
+	return result, nil
+}
+
+func (s *walletService) TopUp(userID uint, req models.TopUpRequest) ([]models.Money, error) {
+	currency := models.NormalizeCurrency(req.Currency)
+	if !models.IsSupportedCurrency(currency) {
+		return nil, fmt.Errorf("неподдерживаемая валюта %s", req.Currency)
+	}
+	if req.Amount < 1 {
+		return nil, fmt.Errorf("сумма пополнения должна быть больше нуля")
+	}
+
+	if _, err := s.users.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return creditWallet(tx, userID, req.Amount, currency)
+	}); err != nil {
+		s.log.Error("Ошибка при пополнении счета", "user_id", userID, "error", err.Error())
+		return nil, err
+	}
+
+	s.log.Info("Счет пополнен", "user_id", userID, "amount", req.Amount, "currency", currency)
+	return s.Balances(userID)
+}
+
+// priceFor returns the list price of a catalog item in minor units of currency.
+// The RUB price is the item's Price field, other currencies come from the price
+// list.
+func priceFor(tx *gorm.DB, targetType string, targetID uint, basePrice int64, currency string) (int64, error) {
+	if currency == models.CurrencyRUB {
+		return basePrice, nil
+	}
+
+	var price models.CatalogPrice
+	err := tx.Where("target_type = ? AND target_id = ? AND currency = ?", targetType, targetID, currency).
+		First(&price).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("цена в валюте %s не задана", currency)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return price.Amount, nil
+}
+
+// purchaseCurrency resolves the currency a user pays in. A requested currency
+// must match the user's wallet currency.
+func purchaseCurrency(user *models.User, requested string) (string, error) {
+	currency := user.Currency
+	if currency == "" {
+		currency = models.CurrencyRUB
+	}
+
+	requested = models.NormalizeCurrency(requested)
+	if requested != "" && requested != currency {
+		return "", fmt.Errorf("валюта оплаты %s не совпадает с валютой кошелька %s: %w", requested, currency, models.ErrCurrencyMismatch)
+	}
+
+	return currency, nil
+}
+
+// debitWallet charges amount minor units from the user's wallet. The RUB wallet
+// is User.Balance, other currencies live in user_balances.
+func debitWallet(tx *gorm.DB, user *models.User, amount int64, currency string) error {
+	if currency == models.CurrencyRUB {
+		// the balance is checked by the update itself, so a stale user row
+		// cannot overdraw it
+		result := tx.Model(&models.User{}).Where("id = ? AND balance_minor >= ?", user.ID, amount).
+			UpdateColumn("balance_minor", gorm.Expr("balance_minor - ?", amount))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("недостаточно средств на счету")
+		}
+		user.Balance -= amount
+		return nil
+	}
+
+	var balance models.UserBalance
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND currency = ?", user.ID, currency).
+		First(&balance).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("недостаточно средств на счету")
+	}
+	if err != nil {
+		return err
+	}
+
+	if balance.Amount < amount {
+		return fmt.Errorf("недостаточно средств на счету")
+	}
+
+	return tx.Model(&balance).UpdateColumn("amount", gorm.Expr("amount - ?", amount)).Error
+}
+
+func creditWallet(tx *gorm.DB, userID uint, amount int64, currency string) error {
+	if currency == models.CurrencyRUB {
+		return tx.Model(&models.User{}).Where("id = ?", userID).
+			UpdateColumn("balance_minor", gorm.Expr("balance_minor + ?", amount)).Error
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "currency"}},
+		DoUpdates: clause.Assignments(map[string]any{"amount": gorm.Expr("user_balances.amount + ?", amount)}),
+	}).Create(&models.UserBalance{UserID: userID, Currency: currency, Amount: amount}).Error
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type localStorage struct {
+	root string
+}
+
+// NewLocalStorage stores objects as files below root.
+func NewLocalStorage(root string) (Storage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("storage: create %s: %w", root, err)
+	}
+
+	return &localStorage{root: root}, nil
+}
+
+// path maps a key to a file inside root; cleaning it as an absolute path
+// drops any ".." that would escape the directory.
+func (s *localStorage) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(filepath.Clean("/"+key)))
+}
+
+func (s *localStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *localStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return file, err
+}
+
+func (s *localStorage) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
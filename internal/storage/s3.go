@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"path"
+	"strings"
+)
+
+// ObjectClient is the part of an S3-compatible API the storage relies on.
+// Wrap an SDK client (AWS, MinIO, ...) to satisfy it; GetObject should return
+// ErrNotFound for a missing key.
+type ObjectClient interface {
+	PutObject(ctx context.Context, bucket, key string, body io.Reader, contentType string) error
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	DeleteObject(ctx context.Context, bucket, key string) error
+}
+
+type s3Storage struct {
+	client ObjectClient
+	bucket string
+	prefix string
+}
+
+// NewS3Storage stores objects in bucket, with every key placed under prefix.
+func NewS3Storage(client ObjectClient, bucket, prefix string) Storage {
+	return &s3Storage{client: client, bucket: bucket, prefix: prefix}
+}
+
+func (s *s3Storage) key(key string) string {
+	return strings.TrimPrefix(path.Join(s.prefix, path.Clean("/"+key)), "/")
+}
+
+func (s *s3Storage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	return s.client.PutObject(ctx, s.bucket, s.key(key), body, contentType)
+}
+
+func (s *s3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.client.GetObject(ctx, s.bucket, s.key(key))
+}
+
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	return s.client.DeleteObject(ctx, s.bucket, s.key(key))
+}
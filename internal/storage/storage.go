@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage keeps uploaded files as opaque objects addressed by key.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,89 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	texttemplate "text/template"
+)
+
+const DefaultLanguage = "ru"
+
+//go:embed *.txt *.html
+var files embed.FS
+
+type Message struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+func Render(eventType, language string, data any) (*Message, error) {
+	if language == "" || !exists(fileName(eventType, language, "txt")) {
+		language = DefaultLanguage
+	}
+
+	name := fileName(eventType, language, "txt")
+	text, err := texttemplate.ParseFS(files, name)
+	if err != nil {
+		return nil, fmt.Errorf("no template for event %q: %w", eventType, err)
+	}
+
+	var subject, body bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := text.ExecuteTemplate(&body, "body", data); err != nil {
+		return nil, err
+	}
+
+	message := &Message{
+		Subject: subject.String(),
+		Text:    body.String(),
+	}
+
+	name = fileName(eventType, language, "html")
+	if exists(name) {
+		html, err := htmltemplate.ParseFS(files, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var htmlBody bytes.Buffer
+		if err := html.Execute(&htmlBody, data); err != nil {
+			return nil, err
+		}
+		message.HTML = htmlBody.String()
+	}
+
+	return message, nil
+}
+
+func RenderHTML(name, language string, data any) (string, error) {
+	if language == "" || !exists(fileName(name, language, "html")) {
+		language = DefaultLanguage
+	}
+
+	html, err := htmltemplate.ParseFS(files, fileName(name, language, "html"))
+	if err != nil {
+		return "", fmt.Errorf("no template %q: %w", name, err)
+	}
+
+	var body bytes.Buffer
+	if err := html.Execute(&body, data); err != nil {
+		return "", err
+	}
+
+	return body.String(), nil
+}
+
+func fileName(eventType, language, ext string) string {
+	return fmt.Sprintf("%s.%s.%s", eventType, language, ext)
+}
+
+func exists(name string) bool {
+	_, err := fs.Stat(files, name)
+	return err == nil
+}
@@ -0,0 +1,150 @@
+package transport
+
+import (
+	"errors"
+	"healthy_body/internal/service"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttachmentHandler struct {
+	attachments service.AttachmentService
+	log         *slog.Logger
+}
+
+func NewAttachmentHandler(attachments service.AttachmentService, log *slog.Logger) *AttachmentHandler {
+	return &AttachmentHandler{attachments: attachments, log: log}
+}
+
+func (h *AttachmentHandler) RegisterRoutes(r *gin.Engine) {
+	r.POST("/reviews/:id/photos", h.UploadReviewPhoto)
+	r.DELETE("/reviews/:id/photos/:photoID", h.DeleteReviewPhoto)
+	r.GET("/attachments/:id", h.serve(false))
+	r.GET("/attachments/:id/thumbnail", h.serve(true))
+}
+
+func (h *AttachmentHandler) UploadReviewPhoto(c *gin.Context) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	// leave room for the multipart envelope around the photo itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxPhotoSize+1<<20)
+
+	userID, err := strconv.ParseUint(c.PostForm("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "некорректный UserID"})
+		return
+	}
+
+	header, err := c.FormFile("photo")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrPhotoTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"message": "файл photo не передан", "error": err.Error()})
+		return
+	}
+	if header.Size > service.MaxPhotoSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrPhotoTooLarge.Error()})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	photo, err := h.attachments.UploadReviewPhoto(c.Request.Context(), id, uint(userID), file)
+	if err != nil {
+		h.log.Warn("Ошибка при загрузке фото",
+			"review_id", id,
+			"error", err.Error())
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, photo)
+}
+
+func (h *AttachmentHandler) DeleteReviewPhoto(c *gin.Context) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	photoID, err := strconv.ParseUint(c.Param("photoID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "некорректный ID фото"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "некорректный UserID"})
+		return
+	}
+
+	if err := h.attachments.DeleteReviewPhoto(c.Request.Context(), id, uint(photoID), uint(userID)); err != nil {
+		h.log.Warn("Ошибка при удалении фото",
+			"review_id", id,
+			"photo_id", photoID,
+			"error", err.Error())
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "фото удалено"})
+}
+
+func (h *AttachmentHandler) serve(thumbnail bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "некорректный ID вложения"})
+			return
+		}
+
+		body, contentType, err := h.attachments.Open(c.Request.Context(), uint(id), thumbnail)
+		if err != nil {
+			c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		defer body.Close()
+
+		c.Header("Content-Type", contentType)
+		c.Header("Cache-Control", "public, max-age=86400")
+		c.Status(http.StatusOK)
+		if _, err := io.Copy(c.Writer, body); err != nil {
+			h.log.Warn("Ошибка при отдаче вложения",
+				"id", id,
+				"error", err.Error())
+		}
+	}
+}
+
+func attachmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrAttachmentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrPhotoTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrPhotoType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, service.ErrPhotoLimit):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrPhotoForbidden):
+		return http.StatusForbidden
+	}
+
+	return reviewErrorStatus(err)
+}
@@ -1,8 +1,9 @@
 package transport
 
 import (
+	"healthy_body/internal/calculator"
+	"healthy_body/internal/models"
 	"log/slog"
-	"math"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -37,26 +38,22 @@ func (h *BmiHandler) BmiInput(r *gin.Context) {
 		return
 	}
 
-	category, result := BmiCalc(input.Weigth, input.Heigth)
+	result, err := calculator.BMI(calculator.BMIInput{
+		Units:  calculator.UnitsMetric,
+		Height: input.Heigth,
+		Weight: input.Weigth,
+	}, models.LanguageRU)
+	if err != nil {
+		h.log.Warn("invalid bmi input", "error", err)
+		r.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	h.log.Info("operation succes")
 
 	r.IndentedJSON(http.StatusOK, gin.H{
-		"category": category,
-		"result":   result,
+		"category": result.Label,
+		"class":    result.Class,
+		"result":   result.BMI,
 	})
 }
-
-func BmiCalc(weight, height float64) (string, float64) {
-	hMeters := height / 100
-	bmi := weight / (hMeters * hMeters)
-	rounded := math.Round(bmi*100) / 100
-	switch {
-	case rounded < 18.5:
-		return "Недостаточный вес", rounded
-	case rounded >= 18.5 && rounded <= 25:
-		return "Нормальный вес", rounded
-	default:
-		return "Избыточный вес или ожирение", rounded
-	}
-}
@@ -0,0 +1,125 @@
+package transport
+
+import (
+	"errors"
+	"healthy_body/internal/calculator"
+	"healthy_body/internal/models"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalculatorHandler struct {
+	log *slog.Logger
+}
+
+func NewCalculatorHandler(log *slog.Logger) *CalculatorHandler {
+	return &CalculatorHandler{log: log}
+}
+
+// RegisterRoutes exposes the calculators. Labels in the results follow the
+// ?lang= parameter, ru by default.
+func (h *CalculatorHandler) RegisterRoutes(r *gin.Engine) {
+	calculators := r.Group("/calculators")
+	{
+		calculators.POST("/bmi", h.BMI)
+		calculators.POST("/body-fat", h.BodyFat)
+		calculators.POST("/waist-to-height", h.WaistHeight)
+		calculators.POST("/ideal-weight", h.IdealWeight)
+		calculators.POST("/one-rep-max", h.OneRepMax)
+		calculators.POST("/heart-rate-zones", h.HeartRateZones)
+	}
+}
+
+func (h *CalculatorHandler) BMI(c *gin.Context) {
+	var input calculator.BMIInput
+	if !h.bind(c, &input) {
+		return
+	}
+
+	result, err := calculator.BMI(input, calculatorLanguage(c))
+	h.respond(c, result, err)
+}
+
+func (h *CalculatorHandler) BodyFat(c *gin.Context) {
+	var input calculator.BodyFatInput
+	if !h.bind(c, &input) {
+		return
+	}
+
+	result, err := calculator.BodyFat(input, calculatorLanguage(c))
+	h.respond(c, result, err)
+}
+
+func (h *CalculatorHandler) WaistHeight(c *gin.Context) {
+	var input calculator.WaistHeightInput
+	if !h.bind(c, &input) {
+		return
+	}
+
+	result, err := calculator.WaistHeight(input, calculatorLanguage(c))
+	h.respond(c, result, err)
+}
+
+func (h *CalculatorHandler) IdealWeight(c *gin.Context) {
+	var input calculator.IdealWeightInput
+	if !h.bind(c, &input) {
+		return
+	}
+
+	result, err := calculator.IdealWeight(input)
+	h.respond(c, result, err)
+}
+
+func (h *CalculatorHandler) OneRepMax(c *gin.Context) {
+	var input calculator.OneRepMaxInput
+	if !h.bind(c, &input) {
+		return
+	}
+
+	result, err := calculator.OneRepMax(input)
+	h.respond(c, result, err)
+}
+
+func (h *CalculatorHandler) HeartRateZones(c *gin.Context) {
+	var input calculator.HeartRateInput
+	if !h.bind(c, &input) {
+		return
+	}
+
+	result, err := calculator.HeartRateZones(input)
+	h.respond(c, result, err)
+}
+
+func (h *CalculatorHandler) bind(c *gin.Context, input any) bool {
+	if err := c.ShouldBindJSON(input); err != nil {
+		h.log.Warn("invalid calculator input", "path", c.FullPath(), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	return true
+}
+
+func (h *CalculatorHandler) respond(c *gin.Context, result any, err error) {
+	if err != nil {
+		if errors.Is(err, calculator.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.log.Error("calculator failed", "path", c.FullPath(), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func calculatorLanguage(c *gin.Context) string {
+	if c.Query("lang") == models.LanguageEN {
+		return models.LanguageEN
+	}
+
+	return models.LanguageRU
+}
@@ -0,0 +1,116 @@
+package transport
+
+import (
+	"errors"
+	"healthy_body/internal/models"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CartHandler struct {
+	cart service.CartService
+	log  *slog.Logger
+}
+
+func NewCartHandler(cart service.CartService, log *slog.Logger) *CartHandler {
+	return &CartHandler{
+		cart: cart,
+		log:  log,
+	}
+}
+
+func (h *CartHandler) RegisterRoutes(r *gin.Engine) {
+	cart := r.Group("/users/:id/cart")
+	{
+		cart.GET("/", h.Get)
+		cart.POST("/items", h.AddItem)
+		cart.DELETE("/items/:itemID", h.RemoveItem)
+		cart.POST("/checkout", h.Checkout)
+	}
+}
+
+func (h *CartHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	quote, err := h.cart.Quote(uint(id), c.Query("promo_code"), c.Query("currency"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+func (h *CartHandler) AddItem(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	var req models.AddCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Введены неверные данные", "err", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := h.cart.AddItem(uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("itemID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID товара"})
+		return
+	}
+
+	quote, err := h.cart.RemoveItem(uint(id), uint(itemID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "товар не найден в корзине"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+func (h *CartHandler) Checkout(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	result, err := h.cart.Checkout(uint(id), c.Query("promo_code"), c.Query("currency"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
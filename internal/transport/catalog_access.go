@@ -0,0 +1,156 @@
+package transport
+
+import (
+	"errors"
+	"healthy_body/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	catalogActorKey = "catalog_actor"
+	catalogOwnerKey = "catalog_owner"
+	catalogAdminKey = "catalog_admin"
+)
+
+// requireAuthor lets coaches and admins, named by the user_id query
+// parameter, create catalog items.
+func requireAuthor(coaches service.CoachService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := catalogActor(c, coaches); !ok {
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireOwner guards changes to the catalog item in the :id parameter: admins
+// pass, coaches only for the items they own.
+func requireOwner(coaches service.CoachService, targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := catalogActor(c, coaches); !ok {
+			return
+		}
+
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		if !canEditCatalog(c, coaches, targetType, uint(id)) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// canEditCatalog checks, after requireAuthor or requireOwner, that the actor
+// may change the catalog item a new or moved child is attached to.
+func canEditCatalog(c *gin.Context, coaches service.CoachService, targetType string, targetID uint) bool {
+	if err := coaches.CanEdit(c.GetUint(catalogActorKey), targetType, targetID); err != nil {
+		c.AbortWithStatusJSON(catalogAccessStatus(err), gin.H{"error": err.Error()})
+		return false
+	}
+
+	return true
+}
+
+// requireAdmin lets only admins, named by the user_id query parameter, through.
+func requireAdmin(coaches service.CoachService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := catalogActor(c, coaches); !ok {
+			return
+		}
+		if !isCatalogAdmin(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": service.ErrAdminOnly.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireAccess guards the content of the catalog item in the :id parameter:
+// the user named by user_id has to be entitled to its category, own the item
+// or be an admin.
+func requireAccess(coaches service.CoachService, subs service.UserSubscriptionService, targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+			return
+		}
+
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		if err := canView(coaches, subs, uint(userID), targetType, uint(id)); err != nil {
+			c.AbortWithStatusJSON(catalogAccessStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// canView checks that userID may open the content of a catalog item. Owners
+// and admins always can, everybody else needs an entitlement.
+func canView(coaches service.CoachService, subs service.UserSubscriptionService, userID uint, targetType string, targetID uint) error {
+	err := coaches.CanEdit(userID, targetType, targetID)
+	if !errors.Is(err, service.ErrNotOwner) {
+		return err
+	}
+
+	return subs.CanView(userID, targetType, targetID)
+}
+
+func catalogActor(c *gin.Context, coaches service.CoachService) (uint, bool) {
+	actorID, err := strconv.ParseUint(c.Query("user_id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+		return 0, false
+	}
+
+	owner, admin, err := coaches.Author(uint(actorID))
+	if err != nil {
+		c.AbortWithStatusJSON(catalogAccessStatus(err), gin.H{"error": err.Error()})
+		return 0, false
+	}
+
+	c.Set(catalogActorKey, uint(actorID))
+	c.Set(catalogOwnerKey, owner)
+	c.Set(catalogAdminKey, admin)
+	return uint(actorID), true
+}
+
+// catalogOwner is the owner a new catalog item gets: the coach creating it,
+// or whatever an admin asked for.
+func catalogOwner(c *gin.Context, requested *uint) *uint {
+	if c.GetBool(catalogAdminKey) {
+		return requested
+	}
+
+	owner, _ := c.Get(catalogOwnerKey)
+	id, _ := owner.(*uint)
+	return id
+}
+
+func isCatalogAdmin(c *gin.Context) bool {
+	return c.GetBool(catalogAdminKey)
+}
+
+func catalogAccessStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrNotOwner), errors.Is(err, service.ErrNoAccess):
+		return http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"healthy_body/internal/models"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CatalogTransferHandler struct {
+	transfer service.CatalogTransferService
+	coaches  service.CoachService
+	log      *slog.Logger
+}
+
+func NewCatalogTransferHandler(transfer service.CatalogTransferService, coaches service.CoachService, log *slog.Logger) *CatalogTransferHandler {
+	return &CatalogTransferHandler{
+		transfer: transfer,
+		coaches:  coaches,
+		log:      log,
+	}
+}
+
+// RegisterRoutes keeps the whole-catalog export and import to admins: the
+// documents cover every owner's items and their paid content.
+func (h *CatalogTransferHandler) RegisterRoutes(r *gin.Engine) {
+	catalog := r.Group("/catalog", requireAdmin(h.coaches))
+	{
+		catalog.GET("/export", h.Export)
+		catalog.POST("/import", h.Import)
+	}
+}
+
+func (h *CatalogTransferHandler) Export(c *gin.Context) {
+	if c.DefaultQuery("format", "json") == "csv" {
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", `attachment; filename="catalog.zip"`)
+		if err := h.transfer.ExportCSV(c.Writer); err != nil {
+			h.log.Error("failed to export catalog as csv", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export catalog"})
+		}
+		return
+	}
+
+	doc, err := h.transfer.Export()
+	if err != nil {
+		h.log.Error("failed to export catalog", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export catalog"})
+		return
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
+func (h *CatalogTransferHandler) Import(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		h.log.Warn("invalid dry_run", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+		return
+	}
+
+	var doc models.CatalogDocument
+	if err := c.ShouldBindJSON(&doc); err != nil {
+		h.log.Warn("invalid catalog document", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.transfer.Import(&doc, dryRun)
+	if err != nil {
+		h.log.Error("failed to import catalog", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if len(report.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+
+	c.JSON(status, report)
+}
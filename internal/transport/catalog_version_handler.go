@@ -0,0 +1,151 @@
+package transport
+
+import (
+	"healthy_body/internal/models"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CatalogVersionHandler struct {
+	versions service.CatalogVersionService
+	coaches  service.CoachService
+	log      *slog.Logger
+}
+
+func NewCatalogVersionHandler(versions service.CatalogVersionService, coaches service.CoachService, log *slog.Logger) *CatalogVersionHandler {
+	return &CatalogVersionHandler{
+		versions: versions,
+		coaches:  coaches,
+		log:      log,
+	}
+}
+
+func (h *CatalogVersionHandler) RegisterRoutes(r *gin.Engine) {
+	groups := map[string]string{
+		"/category":  models.CatalogCategory,
+		"/plan":      models.CatalogExercisePlan,
+		"/mealPlans": models.CatalogMealPlan,
+	}
+
+	for path, entityType := range groups {
+		owner := requireOwner(h.coaches, entityType)
+
+		group := r.Group(path)
+		{
+			group.POST("/:id/publish", owner, h.Publish(entityType))
+			group.POST("/:id/unpublish", owner, h.Unpublish(entityType))
+			group.POST("/:id/archive", owner, h.Archive(entityType))
+			group.GET("/:id/versions", owner, h.ListVersions(entityType))
+			group.GET("/:id/versions/:version", owner, h.GetVersion(entityType))
+		}
+	}
+}
+
+func (h *CatalogVersionHandler) Publish(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			h.log.Warn("invalid id", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		version, err := h.versions.Publish(entityType, uint(id))
+		if err != nil {
+			h.log.Error("failed to publish", "entity_type", entityType, "id", id, "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, version)
+	}
+}
+
+func (h *CatalogVersionHandler) Unpublish(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			h.log.Warn("invalid id", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		if err := h.versions.Unpublish(entityType, uint(id)); err != nil {
+			h.log.Error("failed to unpublish", "entity_type", entityType, "id", id, "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": models.StatusDraft})
+	}
+}
+
+func (h *CatalogVersionHandler) Archive(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			h.log.Warn("invalid id", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		if err := h.versions.Archive(entityType, uint(id)); err != nil {
+			h.log.Error("failed to archive", "entity_type", entityType, "id", id, "error", err)
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found or archive failed"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": models.StatusArchived})
+	}
+}
+
+func (h *CatalogVersionHandler) ListVersions(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			h.log.Warn("invalid id", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		versions, err := h.versions.ListVersions(entityType, uint(id))
+		if err != nil {
+			h.log.Error("failed to get versions", "entity_type", entityType, "id", id, "error", err)
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, versions)
+	}
+}
+
+func (h *CatalogVersionHandler) GetVersion(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			h.log.Warn("invalid id", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		version, err := strconv.Atoi(c.Param("version"))
+		if err != nil {
+			h.log.Warn("invalid version", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
+			return
+		}
+
+		result, err := h.versions.GetVersion(entityType, uint(id), version)
+		if err != nil {
+			h.log.Error("failed to get version", "entity_type", entityType, "id", id, "version", version, "error", err)
+			c.JSON(http.StatusNotFound, gin.H{"error": "version not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
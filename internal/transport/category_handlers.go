@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"errors"
 	"healthy_body/internal/models"
 	"healthy_body/internal/service"
 	"log/slog"
@@ -17,12 +18,16 @@ type CategoryResponse struct {
 
 type CategoryHandler struct {
 	category service.CategoryServices
+	coaches  service.CoachService
+	subs     service.UserSubscriptionService
 	log      *slog.Logger
 }
 
-func NewCategoryHandler(category service.CategoryServices, log *slog.Logger) *CategoryHandler {
+func NewCategoryHandler(category service.CategoryServices, coaches service.CoachService, subs service.UserSubscriptionService, log *slog.Logger) *CategoryHandler {
 	return &CategoryHandler{
 		category: category,
+		coaches:  coaches,
+		subs:     subs,
 		log:      log,
 	}
 }
@@ -30,11 +35,12 @@ func NewCategoryHandler(category service.CategoryServices, log *slog.Logger) *Ca
 func (h *CategoryHandler) RegisterRoutes(r *gin.Engine) {
 	group := r.Group("/category")
 	{
-		group.POST("/", h.CreateCategory)
+		group.POST("/", requireAuthor(h.coaches), h.CreateCategory)
 		group.GET("/", h.GetList)
 		group.GET("/:id", h.GetByID)
-		group.PATCH("/:id", h.UpdateCategory)
-		group.DELETE("/:id", h.DeleteCategory)
+		group.PATCH("/:id", requireOwner(h.coaches, models.CatalogCategory), h.UpdateCategory)
+		group.DELETE("/:id", requireOwner(h.coaches, models.CatalogCategory), h.DeleteCategory)
+		group.POST("/:id/clone", requireOwner(h.coaches, models.CatalogCategory), h.CloneCategory)
 	}
 }
 
@@ -45,6 +51,7 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	input.OwnerID = catalogOwner(c, input.OwnerID)
 
 	cat, err := h.category.CreateCategory(input)
 	if err != nil {
@@ -73,11 +80,57 @@ func (h *CategoryHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	// the plans are listed for everybody, their content only for users who
+	// may open it
+	if !h.canViewContent(c, cat.ID) {
+		hideCategoryContent(cat)
+	}
+
 	c.JSON(http.StatusOK, cat)
 }
 
+func (h *CategoryHandler) canViewContent(c *gin.Context, categoryID uint) bool {
+	userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64)
+	if err != nil {
+		return false
+	}
+
+	err = canView(h.coaches, h.subs, uint(userID), models.CatalogCategory, categoryID)
+	if err != nil && !errors.Is(err, service.ErrNoAccess) {
+		h.log.Error("failed to check category access", "id", categoryID, "user_id", userID, "error", err)
+	}
+	return err == nil
+}
+
+func hideCategoryContent(cat *models.Categories) {
+	for i := range cat.ExercisePlans {
+		cat.ExercisePlans[i].Exercises = nil
+		cat.ExercisePlans[i].ProgressionRules = nil
+	}
+	for i := range cat.MealPlans {
+		cat.MealPlans[i].Meals = nil
+	}
+}
+
 func (h *CategoryHandler) GetList(c *gin.Context) {
-	list, err := h.category.GetCategoryList()
+	sort := c.Query("sort")
+	if sort != "" && sort != models.SortRating {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported sort, use sort=rating"})
+		return
+	}
+
+	// Only published categories are public; owners see their other rows and
+	// admins see everything.
+	status := c.DefaultQuery("status", models.StatusPublished)
+	var owner *uint
+	if status != models.StatusPublished {
+		if _, ok := catalogActor(c, h.coaches); !ok {
+			return
+		}
+		owner = catalogOwner(c, nil)
+	}
+
+	list, err := h.category.GetCategoryList(status, owner, sort)
 	if err != nil {
 		h.log.Error("failed to get category list", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get categories"})
@@ -101,9 +154,16 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !isCatalogAdmin(c) {
+		input.OwnerID = nil
+	}
 
 	cat, err := h.category.UpdateCategory(uint(id), input)
 	if err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.log.Error("failed to update category", "error", err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "category not found or update failed"})
 		return
@@ -121,6 +181,10 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	}
 
 	if err := h.category.DeleteCategory(uint(id)); err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.log.Error("failed to delete category", "error", err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "category not found or delete failed"})
 		return
@@ -128,3 +192,21 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"deleted": true})
 }
+
+func (h *CategoryHandler) CloneCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.Warn("invalid id", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	cat, err := h.category.CloneCategory(uint(id))
+	if err != nil {
+		h.log.Error("failed to clone category", "error", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "category not found or clone failed"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, cat)
+}
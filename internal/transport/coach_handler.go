@@ -0,0 +1,341 @@
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"healthy_body/internal/models"
+	"healthy_body/internal/repository"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CoachHandler struct {
+	coaches service.CoachService
+	log     *slog.Logger
+}
+
+func NewCoachHandler(coaches service.CoachService, log *slog.Logger) *CoachHandler {
+	return &CoachHandler{coaches: coaches, log: log}
+}
+
+func (h *CoachHandler) RegisterRoutes(r *gin.Engine) {
+	self := requireCoachSelf(h.coaches)
+	admin := requireAdmin(h.coaches)
+
+	coaches := r.Group("/coaches")
+	{
+		coaches.GET("/", h.List)
+		coaches.GET("/:id", h.GetPage)
+		coaches.PUT("/:id", self, h.UpdateProfile)
+		coaches.POST("/:id/photo", self, h.UploadPhoto)
+		coaches.GET("/:id/revenue", self, h.Revenue)
+		coaches.PUT("/:id/revenue-share", admin, h.SetRevenueShare)
+		coaches.GET("/:id/earnings", self, h.Earnings)
+		coaches.GET("/:id/statements/:period", self, h.Statement)
+		coaches.POST("/:id/statements/:period/close", admin, h.ClosePeriod)
+		coaches.POST("/:id/payouts/:payoutID/paid", admin, h.MarkPaid)
+	}
+}
+
+func (h *CoachHandler) List(c *gin.Context) {
+	coaches, err := h.coaches.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, coaches)
+}
+
+func (h *CoachHandler) GetPage(c *gin.Context) {
+	id, ok := parseCoachID(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.coaches.GetPage(id)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *CoachHandler) UpdateProfile(c *gin.Context) {
+	id, ok := parseCoachID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCoachProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.coaches.UpdateProfile(id, req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *CoachHandler) UploadPhoto(c *gin.Context) {
+	id, ok := parseCoachID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxPhotoSize+1<<20)
+	header, err := c.FormFile("photo")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrPhotoTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "photo file is required"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	page, err := h.coaches.UploadPhoto(c.Request.Context(), id, file)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Revenue reports attributed sales for the month in ?month=YYYY-MM, or for an
+// explicit ?from=&to= date range; the current month by default.
+func (h *CoachHandler) Revenue(c *gin.Context) {
+	id, ok := parseCoachID(c)
+	if !ok {
+		return
+	}
+
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revenue, err := h.coaches.Revenue(id, from, to)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"coach_id": id,
+		"from":     from.Format(time.DateOnly),
+		"to":       to.AddDate(0, 0, -1).Format(time.DateOnly),
+		"revenue":  revenue,
+	})
+}
+
+func (h *CoachHandler) SetRevenueShare(c *gin.Context) {
+	id, ok := parseCoachID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SetRevenueShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.coaches.SetRevenueShare(id, *req.RevenueShare); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"coach_id": id, "revenue_share": *req.RevenueShare})
+}
+
+// Earnings lists the coach ledger for the same periods as Revenue;
+// ?format=csv downloads it as a CSV file.
+func (h *CoachHandler) Earnings(c *gin.Context) {
+	id, ok := parseCoachID(c)
+	if !ok {
+		return
+	}
+
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.DefaultQuery("format", "json") == "csv" {
+		var buf bytes.Buffer
+		if err := h.coaches.ExportEarnings(&buf, id, from, to); err != nil {
+			h.respondError(c, err)
+			return
+		}
+
+		filename := fmt.Sprintf("coach-%d-earnings-%s-%s.csv", id,
+			from.Format(time.DateOnly), to.AddDate(0, 0, -1).Format(time.DateOnly))
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+		return
+	}
+
+	earnings, err := h.coaches.Earnings(id, from, to)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"coach_id": id,
+		"from":     from.Format(time.DateOnly),
+		"to":       to.AddDate(0, 0, -1).Format(time.DateOnly),
+		"earnings": earnings,
+	})
+}
+
+func (h *CoachHandler) Statement(c *gin.Context) {
+	id, ok := parseCoachID(c)
+	if !ok {
+		return
+	}
+
+	statement, err := h.coaches.Statement(id, c.Param("period"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+func (h *CoachHandler) ClosePeriod(c *gin.Context) {
+	id, ok := parseCoachID(c)
+	if !ok {
+		return
+	}
+
+	statement, err := h.coaches.ClosePeriod(id, c.Param("period"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+func (h *CoachHandler) MarkPaid(c *gin.Context) {
+	id, ok := parseCoachID(c)
+	if !ok {
+		return
+	}
+
+	payoutID, err := strconv.ParseUint(c.Param("payoutID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payout id"})
+		return
+	}
+
+	payout, err := h.coaches.MarkPaid(id, uint(payoutID))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payout)
+}
+
+func (h *CoachHandler) respondError(c *gin.Context, err error) {
+	var status int
+	switch {
+	case errors.Is(err, service.ErrCoachNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidPeriod), errors.Is(err, service.ErrInvalidRange),
+		errors.Is(err, service.ErrInvalidShare), errors.Is(err, service.ErrPeriodNotOver):
+		status = http.StatusBadRequest
+	case errors.Is(err, repository.ErrPeriodClosed), errors.Is(err, repository.ErrPayoutPaid):
+		status = http.StatusConflict
+	default:
+		status = attachmentErrorStatus(err)
+	}
+
+	h.log.Warn("coach request failed", "error", err)
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// requireCoachSelf lets the coach in :id, named by the user_id query
+// parameter, or an admin through.
+func requireCoachSelf(coaches service.CoachService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid coach id"})
+			return
+		}
+
+		if _, ok := catalogActor(c, coaches); !ok {
+			return
+		}
+		if owner := catalogOwner(c, nil); !isCatalogAdmin(c) && (owner == nil || *owner != uint(id)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": service.ErrNotOwner.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+func parseCoachID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid coach id"})
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+func reportPeriod(c *gin.Context) (time.Time, time.Time, error) {
+	if month := c.Query("month"); month != "" {
+		from, err := time.Parse("2006-01", month)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("month must be YYYY-MM")
+		}
+		return from, from.AddDate(0, 1, 0), nil
+	}
+
+	if c.Query("from") != "" || c.Query("to") != "" {
+		from, err := time.Parse(time.DateOnly, c.Query("from"))
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("from must be YYYY-MM-DD")
+		}
+		to, err := time.Parse(time.DateOnly, c.Query("to"))
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("to must be YYYY-MM-DD")
+		}
+		return from, to.AddDate(0, 0, 1), nil
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 1, 0), nil
+}
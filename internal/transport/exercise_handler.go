@@ -1,13 +1,16 @@
 package transport
 
 import (
+	"errors"
 	"healthy_body/internal/models"
 	"healthy_body/internal/service"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type ExercisePlanResponse struct {
@@ -19,31 +22,45 @@ type ExercisePlanResponse struct {
 }
 
 type ExercisePlanHandler struct {
-	exer service.ExercisePlanServices
-	log  *slog.Logger
+	exer    service.ExercisePlanServices
+	coaches service.CoachService
+	subs    service.UserSubscriptionService
+	log     *slog.Logger
 }
 
-func NewExercisePlanHandler(exer service.ExercisePlanServices, log *slog.Logger) *ExercisePlanHandler {
+func NewExercisePlanHandler(exer service.ExercisePlanServices, coaches service.CoachService, subs service.UserSubscriptionService, log *slog.Logger) *ExercisePlanHandler {
 	return &ExercisePlanHandler{
-		exer: exer,
-		log:  log,
+		exer:    exer,
+		coaches: coaches,
+		subs:    subs,
+		log:     log,
 	}
 }
 
 func (h *ExercisePlanHandler) RegisterRoutes(r *gin.Engine) {
+	owner := requireOwner(h.coaches, models.CatalogExercisePlan)
+	itemOwner := requireOwner(h.coaches, models.CatalogExercisePlanItem)
+	access := requireAccess(h.coaches, h.subs, models.CatalogExercisePlan)
+	itemAccess := requireAccess(h.coaches, h.subs, models.CatalogExercisePlanItem)
+
 	planGroup := r.Group("/plan")
 	{
-		planGroup.POST("/", h.CreatePlan)
-		planGroup.GET("/:id", h.GetByID)
+		planGroup.POST("/", requireAuthor(h.coaches), h.CreatePlan)
+		planGroup.GET("/:id", access, h.GetByID)
 		planGroup.GET("/", h.GetAllPlan)
-		planGroup.PATCH("/:id", h.UpdatePlan)
-		planGroup.DELETE("/:id", h.DeletePlan)
-
-		planGroup.POST("/planItem", h.CreatePlanItem)
-		planGroup.GET("/planItem/:id", h.GetPlanItemByID)
-		planGroup.GET("/planItem/", h.GetListPlanItem)
-		planGroup.PATCH("/planItem/:id", h.UpdatePlanItem)
-		planGroup.DELETE("/planItem/:id", h.DeletePlanItem)
+		planGroup.PATCH("/:id", owner, h.UpdatePlan)
+		planGroup.DELETE("/:id", owner, h.DeletePlan)
+		planGroup.POST("/:id/clone", owner, h.ClonePlan)
+		planGroup.GET("/:id/analytics", access, h.GetPlanAnalytics)
+		planGroup.GET("/:id/progression", access, h.GetProgression)
+		planGroup.POST("/:id/progression-rules", owner, h.CreateProgressionRule)
+		planGroup.DELETE("/:id/progression-rules/:ruleID", owner, h.DeleteProgressionRule)
+
+		planGroup.POST("/planItem", requireAuthor(h.coaches), h.CreatePlanItem)
+		planGroup.GET("/planItem/:id", itemAccess, h.GetPlanItemByID)
+		planGroup.GET("/planItem/", requireAdmin(h.coaches), h.GetListPlanItem)
+		planGroup.PATCH("/planItem/:id", itemOwner, h.UpdatePlanItem)
+		planGroup.DELETE("/planItem/:id", itemOwner, h.DeletePlanItem)
 	}
 }
 
@@ -55,6 +72,7 @@ func (h *ExercisePlanHandler) CreatePlan(c *gin.Context) {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	inputPlan.OwnerID = catalogOwner(c, inputPlan.OwnerID)
 
 	plan, err := h.exer.CreatePlan(inputPlan)
 	if err != nil {
@@ -118,6 +136,10 @@ func (h *ExercisePlanHandler) UpdatePlan(c *gin.Context) {
 
 	plan, err := h.exer.UpdatePlan(uint(id), updatePlan)
 	if err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.IndentedJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.log.Error("error update plan in db")
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid id or update"})
 		return
@@ -137,6 +159,10 @@ func (h *ExercisePlanHandler) DeletePlan(c *gin.Context) {
 	}
 
 	if err := h.exer.DeletePlan(uint(id)); err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.IndentedJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.log.Error("error delete plan in db")
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid id or update"})
 		return
@@ -146,6 +172,141 @@ func (h *ExercisePlanHandler) DeletePlan(c *gin.Context) {
 	c.IndentedJSON(http.StatusOK, gin.H{"deleted": true})
 }
 
+func (h *ExercisePlanHandler) ClonePlan(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("error parse id")
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var inputClone models.ClonePlanRequest
+	if err := c.ShouldBindJSON(&inputClone); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Warn("error invalid input type information")
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.exer.ClonePlan(uint(id), inputClone)
+	if err != nil {
+		h.log.Error("error clone plan in db")
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("success plan cloned", "plan_id", id, "clone_id", plan.ID)
+	c.IndentedJSON(http.StatusCreated, plan)
+}
+
+func (h *ExercisePlanHandler) GetPlanAnalytics(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("error parse id")
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	analytics, err := h.exer.GetPlanAnalytics(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.IndentedJSON(http.StatusNotFound, gin.H{"error": "plan not found"})
+			return
+		}
+		h.log.Error("error loading plan for analytics", "plan_id", id, "error", err)
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "failed to load plan analytics"})
+		return
+	}
+
+	h.log.Info("success plan analytics", "plan_id", analytics.PlanID)
+	c.IndentedJSON(http.StatusOK, analytics)
+}
+
+func (h *ExercisePlanHandler) GetProgression(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("error parse id")
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	weeks, err := h.exer.GetProgression(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.IndentedJSON(http.StatusNotFound, gin.H{"error": "plan not found"})
+			return
+		}
+		h.log.Error("error loading plan for progression", "plan_id", id, "error", err)
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "failed to load plan progression"})
+		return
+	}
+
+	h.log.Info("success plan progression", "plan_id", id, "weeks", len(weeks))
+	c.IndentedJSON(http.StatusOK, weeks)
+}
+
+func (h *ExercisePlanHandler) CreateProgressionRule(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("error parse id")
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var inputRule models.CreateProgressionRuleRequest
+	if err := c.ShouldBindJSON(&inputRule); err != nil {
+		h.log.Warn("error invalid input type information")
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.exer.CreateProgressionRule(uint(id), inputRule)
+	if err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.IndentedJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		h.log.Error("error create progression rule")
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("success create progression rule", "rule_id", rule.ID)
+	c.IndentedJSON(http.StatusOK, rule)
+}
+
+func (h *ExercisePlanHandler) DeleteProgressionRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.Warn("error parse id")
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	ruleID, err := strconv.ParseUint(c.Param("ruleID"), 10, 64)
+	if err != nil {
+		h.log.Warn("error parse rule id")
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	if err := h.exer.DeleteProgressionRule(uint(id), uint(ruleID)); err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.IndentedJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		h.log.Error("error delete progression rule in db")
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("success progression rule deleted")
+	c.IndentedJSON(http.StatusOK, gin.H{"deleted": true})
+}
+
 func (h *ExercisePlanHandler) CreatePlanItem(c *gin.Context) {
 	var inputPlanItem models.CreateExercisePlanItemRequest
 
@@ -154,9 +315,16 @@ func (h *ExercisePlanHandler) CreatePlanItem(c *gin.Context) {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !canEditCatalog(c, h.coaches, models.CatalogExercisePlan, inputPlanItem.ExercisePlanID) {
+		return
+	}
 
 	plan, err := h.exer.CreatePlanItem(inputPlanItem)
 	if err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.IndentedJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.log.Error("error in db")
 		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -214,9 +382,16 @@ func (h *ExercisePlanHandler) UpdatePlanItem(c *gin.Context) {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
+	if updatePlan.ExercisePlanID != nil && !canEditCatalog(c, h.coaches, models.CatalogExercisePlan, *updatePlan.ExercisePlanID) {
+		return
+	}
 
 	plan, err := h.exer.UpdatePlanItem(uint(id), updatePlan)
 	if err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.IndentedJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.log.Error("error update planItem in db")
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid id or update"})
 		return
@@ -236,6 +411,10 @@ func (h *ExercisePlanHandler) DeletePlanItem(c *gin.Context) {
 	}
 
 	if err := h.exer.DeletePlanItem(uint(id)); err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.IndentedJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.log.Error("error delete planItem in db")
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid id or update"})
 		return
@@ -0,0 +1,142 @@
+package transport
+
+import (
+	"healthy_body/internal/models"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GiftHandler struct {
+	gifts service.GiftService
+	log   *slog.Logger
+}
+
+func NewGiftHandler(gifts service.GiftService, log *slog.Logger) *GiftHandler {
+	return &GiftHandler{
+		gifts: gifts,
+		log:   log,
+	}
+}
+
+func (h *GiftHandler) RegisterRoutes(r *gin.Engine) {
+	users := r.Group("/users/:id")
+	{
+		users.POST("/gifts", h.Send)
+		users.GET("/gifts", h.History)
+	}
+
+	gifts := r.Group("/gifts")
+	{
+		gifts.POST("/redeem", h.Redeem)
+		gifts.POST("/:id/accept", h.Accept)
+		gifts.POST("/:id/decline", h.Decline)
+	}
+}
+
+func (h *GiftHandler) Send(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	var req models.CreateGiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Введены неверные данные", "err", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	gift, err := h.gifts.Send(uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gift)
+}
+
+func (h *GiftHandler) History(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	history, err := h.gifts.History(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *GiftHandler) Redeem(c *gin.Context) {
+	var req models.RedeemGiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Введены неверные данные", "err", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	gift, err := h.gifts.Redeem(req.Code, req.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gift)
+}
+
+func (h *GiftHandler) Accept(c *gin.Context) {
+	id, req, ok := h.parseAction(c)
+	if !ok {
+		return
+	}
+
+	gift, err := h.gifts.Accept(id, req.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gift)
+}
+
+func (h *GiftHandler) Decline(c *gin.Context) {
+	id, req, ok := h.parseAction(c)
+	if !ok {
+		return
+	}
+
+	gift, err := h.gifts.Decline(id, req.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gift)
+}
+
+func (h *GiftHandler) parseAction(c *gin.Context) (uint, models.GiftActionRequest, bool) {
+	var req models.GiftActionRequest
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return 0, req, false
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Введены неверные данные", "err", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, req, false
+	}
+
+	return uint(id), req, true
+}
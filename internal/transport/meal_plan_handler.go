@@ -1,8 +1,10 @@
 package transport
 
 import (
+	"errors"
 	"healthy_body/internal/models"
 	"healthy_body/internal/service"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -20,24 +22,31 @@ type MealPlanResponse struct {
 
 type MealPlanHandler struct {
 	mealPlans service.MealPlanService
+	coaches   service.CoachService
+	subs      service.UserSubscriptionService
 	logger    *slog.Logger
 }
 
-func NewMealPlanHandler(mealPlans service.MealPlanService, logger *slog.Logger) *MealPlanHandler {
+func NewMealPlanHandler(mealPlans service.MealPlanService, coaches service.CoachService, subs service.UserSubscriptionService, logger *slog.Logger) *MealPlanHandler {
 	return &MealPlanHandler{
 		mealPlans: mealPlans,
+		coaches:   coaches,
+		subs:      subs,
 		logger:    logger,
 	}
 }
 
 func (h *MealPlanHandler) RegisterRoutes(r *gin.Engine) {
+	owner := requireOwner(h.coaches, models.CatalogMealPlan)
+
 	mealPlans := r.Group("/mealPlans")
 	{
-		mealPlans.POST("/", h.Create)
+		mealPlans.POST("/", requireAuthor(h.coaches), h.Create)
 		mealPlans.GET("/", h.GetAllMealPlans)
-		mealPlans.GET("/:id", h.GetMealPlanByID)
-		mealPlans.PATCH("/:id", h.Update)
-		mealPlans.DELETE("/:id", h.Delete)
+		mealPlans.GET("/:id", requireAccess(h.coaches, h.subs, models.CatalogMealPlan), h.GetMealPlanByID)
+		mealPlans.PATCH("/:id", owner, h.Update)
+		mealPlans.DELETE("/:id", owner, h.Delete)
+		mealPlans.POST("/:id/clone", owner, h.Clone)
 	}
 }
 
@@ -48,6 +57,7 @@ func (h *MealPlanHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	req.OwnerID = catalogOwner(c, req.OwnerID)
 	mealPlan, err := h.mealPlans.CreateMealPlan(req)
 	if err != nil {
 		h.logger.Error("handler: failed to create meal plan", "err", err)
@@ -90,6 +100,10 @@ func (h *MealPlanHandler) Update(c *gin.Context) {
 
 	mealPlan, err := h.mealPlans.UpdateMealPlan(uint(id), &req)
 	if err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("handler: failed to update meal plan")
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -110,6 +124,10 @@ func (h *MealPlanHandler) Delete(c *gin.Context) {
 	}
 
 	if err := h.mealPlans.DeleteMealPlan(uint(id)); err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("handler: failed to delete meal plan", "id", id)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ошибка при удалении плана"})
 		return
@@ -135,3 +153,30 @@ func (h *MealPlanHandler) GetMealPlanByID(c *gin.Context) {
 	h.logger.Info("handler: fetch to meal plan successfully")
 	c.JSON(http.StatusOK, mealPlan)
 }
+
+func (h *MealPlanHandler) Clone(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.logger.Error("handler: invalid meal plan id", "id", idStr)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный id"})
+		return
+	}
+
+	var req models.ClonePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Error("handler: failed to bind clone request", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mealPlan, err := h.mealPlans.CloneMealPlan(uint(id), req)
+	if err != nil {
+		h.logger.Error("handler: failed to clone meal plan", "id", id)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("handler: meal plan cloned successfully", "id", id, "clone_id", mealPlan.ID)
+	c.JSON(http.StatusCreated, mealPlan)
+}
@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"errors"
 	"healthy_body/internal/models"
 	"healthy_body/internal/service"
 	"log/slog"
@@ -22,24 +23,30 @@ type MealPlanItemResponse struct {
 
 type MealPlanItemHandler struct {
 	mealPlanItems service.MealPlanItemsService
+	coaches       service.CoachService
+	subs          service.UserSubscriptionService
 	logger        *slog.Logger
 }
 
-func NewMealPlanItemHandler(mealPlanItems service.MealPlanItemsService, logger *slog.Logger) *MealPlanItemHandler {
+func NewMealPlanItemHandler(mealPlanItems service.MealPlanItemsService, coaches service.CoachService, subs service.UserSubscriptionService, logger *slog.Logger) *MealPlanItemHandler {
 	return &MealPlanItemHandler{
 		mealPlanItems: mealPlanItems,
+		coaches:       coaches,
+		subs:          subs,
 		logger:        logger,
 	}
 }
 
 func (h *MealPlanItemHandler) RegisterRoutes(r *gin.Engine) {
+	owner := requireOwner(h.coaches, models.CatalogMealPlanItem)
+
 	mealPlanItems := r.Group("/mealPlanItems")
 	{
-		mealPlanItems.POST("/", h.Create)
-		mealPlanItems.GET("/", h.ListMealPlanItems)
-		mealPlanItems.PATCH("/:id", h.Update)
-		mealPlanItems.GET("/:id", h.GetMealPlanItemById)
-		mealPlanItems.DELETE("/:id", h.DeleteMealPlanItem)
+		mealPlanItems.POST("/", requireAuthor(h.coaches), h.Create)
+		mealPlanItems.GET("/", requireAdmin(h.coaches), h.ListMealPlanItems)
+		mealPlanItems.PATCH("/:id", owner, h.Update)
+		mealPlanItems.GET("/:id", requireAccess(h.coaches, h.subs, models.CatalogMealPlanItem), h.GetMealPlanItemById)
+		mealPlanItems.DELETE("/:id", owner, h.DeleteMealPlanItem)
 	}
 }
 
@@ -50,8 +57,15 @@ func (h *MealPlanItemHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !canEditCatalog(c, h.coaches, models.CatalogMealPlan, req.MealPlanId) {
+		return
+	}
 	mealPlanItem, err := h.mealPlanItems.CreateMealPlanItem(req)
 	if err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("handler: failed to create meal plan item", "err", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -89,9 +103,16 @@ func (h *MealPlanItemHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.MealPlanId != nil && !canEditCatalog(c, h.coaches, models.CatalogMealPlan, *req.MealPlanId) {
+		return
+	}
 
 	mealPlanItem, err := h.mealPlanItems.UpdateMealPlanItem(uint(id), &req)
 	if err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("handler: failed to update meal plan item")
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -131,6 +152,10 @@ func (h *MealPlanItemHandler) DeleteMealPlanItem(c *gin.Context) {
 	}
 
 	if err := h.mealPlanItems.DeleteMealPlanItem(uint(id)); err != nil {
+		if errors.Is(err, service.ErrPublished) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("handler: failed to delete meal plan item", "id", id)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ошибка при удалении"})
 		return
@@ -0,0 +1,147 @@
+package transport
+
+import (
+	"errors"
+	"healthy_body/internal/models"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type NotificationHandler struct {
+	notifications service.NotificationService
+	log           *slog.Logger
+}
+
+func NewNotificationHandler(notifications service.NotificationService, log *slog.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notifications: notifications,
+		log:           log,
+	}
+}
+
+func (h *NotificationHandler) RegisterRoutes(r *gin.Engine) {
+	users := r.Group("/users/:id")
+	{
+		users.GET("/notification-preferences", h.GetPreferences)
+		users.PUT("/notification-preferences", h.UpdatePreference)
+		users.GET("/notifications", h.ListInbox)
+		users.POST("/notifications/read-all", h.MarkAllRead)
+		users.POST("/notifications/:notificationID/read", h.MarkRead)
+	}
+}
+
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	prefs, err := h.notifications.GetPreferences(uint(id))
+	if err != nil {
+		h.log.Error("failed to get notification preferences", "user_id", id, "error", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+func (h *NotificationHandler) UpdatePreference(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req models.UpdateNotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("invalid notification preference", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pref, err := h.notifications.UpdatePreference(uint(id), req)
+	if err != nil {
+		h.log.Warn("failed to update notification preference", "user_id", id, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pref)
+}
+
+func (h *NotificationHandler) ListInbox(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+		return
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page_size"})
+		return
+	}
+
+	inbox, err := h.notifications.ListInbox(uint(id), page, pageSize)
+	if err != nil {
+		h.log.Error("failed to list notifications", "user_id", id, "error", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, inbox)
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	notificationID, err := strconv.ParseUint(c.Param("notificationID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification id"})
+		return
+	}
+
+	if err := h.notifications.MarkRead(uint(id), uint(notificationID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to mark notification as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "notification marked as read"})
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	count, err := h.notifications.MarkAllRead(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to mark notifications as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked": count})
+}
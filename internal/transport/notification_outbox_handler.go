@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"errors"
+	"healthy_body/internal/repository"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type NotificationOutboxHandler struct {
+	outbox  service.NotificationOutboxService
+	coaches service.CoachService
+	log     *slog.Logger
+}
+
+func NewNotificationOutboxHandler(outbox service.NotificationOutboxService, coaches service.CoachService, log *slog.Logger) *NotificationOutboxHandler {
+	return &NotificationOutboxHandler{
+		outbox:  outbox,
+		coaches: coaches,
+		log:     log,
+	}
+}
+
+func (h *NotificationOutboxHandler) RegisterRoutes(r *gin.Engine) {
+	notifications := r.Group("/admin/notifications", requireAdmin(h.coaches))
+	{
+		notifications.GET("/", h.List)
+		notifications.POST("/:id/retry", h.Retry)
+	}
+}
+
+func (h *NotificationOutboxHandler) List(c *gin.Context) {
+	messages, err := h.outbox.List(c.Query("status"))
+	if err != nil {
+		h.log.Warn("failed to list notifications", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+func (h *NotificationOutboxHandler) Retry(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	message, err := h.outbox.Retry(uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+		case errors.Is(err, repository.ErrOutboxAlreadySent):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retry notification"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, message)
+}
@@ -0,0 +1,78 @@
+package transport
+
+import (
+	"errors"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type OrderHandler struct {
+	orders service.OrderService
+	log    *slog.Logger
+}
+
+func NewOrderHandler(orders service.OrderService, log *slog.Logger) *OrderHandler {
+	return &OrderHandler{
+		orders: orders,
+		log:    log,
+	}
+}
+
+func (h *OrderHandler) RegisterRoutes(r *gin.Engine) {
+	orders := r.Group("/users/:id/orders")
+	{
+		orders.GET("/", h.List)
+		orders.GET("/:orderID/receipt", h.Receipt)
+	}
+}
+
+func (h *OrderHandler) List(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	orders, err := h.orders.List(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+func (h *OrderHandler) Receipt(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	orderID, err := strconv.ParseUint(c.Param("orderID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID заказа"})
+		return
+	}
+
+	receipt, err := h.orders.Receipt(uint(id), uint(orderID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "заказ не найден"})
+			return
+		}
+		h.log.Error("Ошибка при формировании чека", "order_id", orderID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось сформировать чек"})
+		return
+	}
+
+	if c.Query("download") == "true" {
+		c.Header("Content-Disposition", "attachment; filename=\"receipt-"+c.Param("orderID")+".html\"")
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(receipt))
+}
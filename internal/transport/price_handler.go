@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"errors"
+	"healthy_body/internal/models"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PriceHandler struct {
+	prices  service.PriceService
+	coaches service.CoachService
+	log     *slog.Logger
+}
+
+func NewPriceHandler(prices service.PriceService, coaches service.CoachService, log *slog.Logger) *PriceHandler {
+	return &PriceHandler{
+		prices:  prices,
+		coaches: coaches,
+		log:     log,
+	}
+}
+
+func (h *PriceHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/category/:id/prices", h.Get(models.TargetCategory))
+	r.PUT("/category/:id/prices", requireOwner(h.coaches, models.CatalogCategory), h.Set(models.TargetCategory))
+	r.GET("/sub/:id/prices", h.Get(models.TargetSubscription))
+	r.PUT("/sub/:id/prices", requireOwner(h.coaches, models.CatalogSubscription), h.Set(models.TargetSubscription))
+}
+
+func (h *PriceHandler) Get(targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		prices, err := h.prices.GetPrices(targetType, uint(id))
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, prices)
+	}
+}
+
+func (h *PriceHandler) Set(targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		var req models.SetPricesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.log.Warn("invalid prices request", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		prices, err := h.prices.SetPrices(targetType, uint(id), req)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, prices)
+	}
+}
+
+func (h *PriceHandler) respondError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
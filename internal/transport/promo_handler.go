@@ -0,0 +1,112 @@
+package transport
+
+import (
+	"errors"
+	"healthy_body/internal/models"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PromoHandler struct {
+	promo   service.PromoService
+	coaches service.CoachService
+	log     *slog.Logger
+}
+
+func NewPromoHandler(promo service.PromoService, coaches service.CoachService, log *slog.Logger) *PromoHandler {
+	return &PromoHandler{
+		promo:   promo,
+		coaches: coaches,
+		log:     log,
+	}
+}
+
+// RegisterRoutes leaves only quotes public; campaigns are run by admins.
+func (h *PromoHandler) RegisterRoutes(r *gin.Engine) {
+	admin := requireAdmin(h.coaches)
+
+	promo := r.Group("/promo-codes")
+	{
+		promo.POST("/", admin, h.Create)
+		promo.GET("/", admin, h.List)
+		promo.GET("/quote", h.Quote)
+		promo.DELETE("/:id", admin, h.Delete)
+	}
+}
+
+func (h *PromoHandler) Create(c *gin.Context) {
+	var req models.CreatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("invalid promo code request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promo, err := h.promo.Create(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, promo)
+}
+
+func (h *PromoHandler) List(c *gin.Context) {
+	promos, err := h.promo.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list promo codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, promos)
+}
+
+func (h *PromoHandler) Quote(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.DefaultQuery("user_id", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Query("target_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid target_id"})
+		return
+	}
+
+	quote, err := h.promo.Quote(uint(userID), c.Query("target_type"), uint(targetID), c.Query("code"), c.Query("currency"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "target not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+func (h *PromoHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.promo.Delete(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "promo code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete promo code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "promo code deleted"})
+}
@@ -0,0 +1,54 @@
+package transport
+
+import (
+	"errors"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecommendationHandler struct {
+	recommendations service.RecommendationService
+	log             *slog.Logger
+}
+
+func NewRecommendationHandler(recommendations service.RecommendationService, log *slog.Logger) *RecommendationHandler {
+	return &RecommendationHandler{
+		recommendations: recommendations,
+		log:             log,
+	}
+}
+
+func (h *RecommendationHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/users/:id/recommendations", h.List)
+}
+
+func (h *RecommendationHandler) List(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(service.DefaultRecommendations)))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный limit"})
+		return
+	}
+
+	recommendations, err := h.recommendations.Recommend(uint(id), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.log.Error("failed to build recommendations", "user_id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendations)
+}
@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"errors"
+	"healthy_body/internal/models"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReviewModerationHandler struct {
+	reviews service.ReviewsService
+	coaches service.CoachService
+	log     *slog.Logger
+}
+
+func NewReviewModerationHandler(reviews service.ReviewsService, coaches service.CoachService, log *slog.Logger) *ReviewModerationHandler {
+	return &ReviewModerationHandler{
+		reviews: reviews,
+		coaches: coaches,
+		log:     log,
+	}
+}
+
+func (h *ReviewModerationHandler) RegisterRoutes(r *gin.Engine) {
+	reviews := r.Group("/admin/reviews", requireAdmin(h.coaches))
+	{
+		reviews.GET("/", h.List)
+		reviews.POST("/:id/approve", h.Approve)
+		reviews.POST("/:id/reject", h.Reject)
+	}
+}
+
+func (h *ReviewModerationHandler) List(c *gin.Context) {
+	reviews, err := h.reviews.ListModeration(c.Query("status"))
+	if err != nil {
+		h.log.Warn("failed to list reviews for moderation", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
+
+func (h *ReviewModerationHandler) Approve(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	review, err := h.reviews.Approve(uint(id))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+func (h *ReviewModerationHandler) Reject(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req models.ModerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := h.reviews.Reject(uint(id), req.Reason)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+func (h *ReviewModerationHandler) respondError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+		return
+	}
+
+	h.log.Warn("failed to moderate review", "error", err)
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"errors"
 	"healthy_body/internal/models"
 	"healthy_body/internal/service"
 	"log/slog"
@@ -43,7 +44,8 @@ func (h *ReviewsHandler) CreateReview(c *gin.Context) {
 	if err != nil {
 		h.log.Error("Ошибка при создании отзыва",
 			"error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{
+
+		c.JSON(reviewErrorStatus(err), gin.H{
 			"error":   err.Error(),
 			"message": "ошибка при создании отзыва",
 		})
@@ -78,7 +80,7 @@ func (h *ReviewsHandler) GetReview(c *gin.Context) {
 		h.log.Error("Ошибка при получении отзыва",
 			"id", id,
 			"error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(reviewErrorStatus(err), gin.H{
 			"error":   err.Error(),
 			"message": "ошибка при получении отзыва",
 		})
@@ -127,37 +129,70 @@ func (h *ReviewsHandler) GetReviewsByUser(c *gin.Context) {
 }
 
 func (h *ReviewsHandler) GetReviewsByCategory(c *gin.Context) {
-	categoryIDStr := c.Param("categoryID")
+	h.listByTarget(c, models.ReviewTargetCategory, "categoryID")
+}
+
+func (h *ReviewsHandler) GetReviewsByExercisePlan(c *gin.Context) {
+	h.listByTarget(c, models.ReviewTargetExercisePlan, "planID")
+}
+
+func (h *ReviewsHandler) GetReviewsByMealPlan(c *gin.Context) {
+	h.listByTarget(c, models.ReviewTargetMealPlan, "planID")
+}
 
-	categoryID, err := strconv.ParseUint(categoryIDStr, 10, 64)
+func (h *ReviewsHandler) listByTarget(c *gin.Context, targetType, param string) {
+	targetIDStr := c.Param(param)
+
+	targetID, err := strconv.ParseUint(targetIDStr, 10, 64)
 	if err != nil {
-		h.log.Warn("Некорректный ID категории",
-			"category_id", categoryIDStr)
+		h.log.Warn("Некорректный ID объекта отзыва",
+			"target_type", targetType,
+			"target_id", targetIDStr)
 		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "некорректный ID категории",
+			"message": "некорректный ID",
 		})
 		return
 	}
 
-	reviews, err := h.review.GetReviewsByCategory(uint(categoryID))
+	sort := c.Query("sort")
+	if sort != "" && sort != models.SortHelpful && sort != models.SortRecent {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "сортировка должна быть helpful или recent",
+		})
+		return
+	}
+
+	reviews, err := h.review.GetReviewsByTarget(targetType, uint(targetID), sort)
 	if err != nil {
-		h.log.Error("Ошибка при получении отзывов по категории",
-			"category_id", categoryID,
+		h.log.Error("Ошибка при получении отзывов",
+			"target_type", targetType,
+			"target_id", targetID,
 			"error", err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   err.Error(),
-			"message": "ошибка при получении отзывов по категории",
+			"message": "ошибка при получении отзывов",
 		})
 		return
 	}
 
-	h.log.Info("Отзывы по категории получены",
-		"category_id", categoryID,
+	rating, err := h.review.GetRating(targetType, uint(targetID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "ошибка при получении рейтинга",
+		})
+		return
+	}
+
+	h.log.Info("Отзывы получены",
+		"target_type", targetType,
+		"target_id", targetID,
 		"count", len(reviews))
 
 	c.JSON(http.StatusOK, gin.H{
 		"reviews": reviews,
 		"total":   len(reviews),
+		"rating":  rating,
 	})
 }
 
@@ -308,7 +343,169 @@ func (h *ReviewsHandler) RegisterRoutes(r *gin.Engine) {
 		reviews.GET("/:id", h.GetReview)
 		reviews.GET("/user/:userID", h.GetReviewsByUser)
 		reviews.GET("/category/:categoryID", h.GetReviewsByCategory)
+		reviews.GET("/exercise-plan/:planID", h.GetReviewsByExercisePlan)
+		reviews.GET("/meal-plan/:planID", h.GetReviewsByMealPlan)
 		reviews.PUT("/:id", h.UpdateReview)
 		reviews.DELETE("/:id", h.DeleteReview)
+		reviews.POST("/:id/replies", h.Reply)
+		reviews.DELETE("/:id/replies/:replyID", h.DeleteReply)
+		reviews.PUT("/:id/vote", h.Vote)
+		reviews.DELETE("/:id/vote", h.Unvote)
+		reviews.POST("/:id/report", h.Report)
+	}
+}
+
+func (h *ReviewsHandler) Reply(c *gin.Context) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Неверный формат данных",
+			"error":   err.Error()})
+		return
 	}
+
+	reply, err := h.review.ReplyToReview(id, req)
+	if err != nil {
+		h.log.Warn("Ошибка при ответе на отзыв",
+			"id", id,
+			"error", err.Error())
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, reply)
+}
+
+func (h *ReviewsHandler) DeleteReply(c *gin.Context) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	replyID, err := strconv.ParseUint(c.Param("replyID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "некорректный ID ответа"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "некорректный UserID"})
+		return
+	}
+
+	if err := h.review.DeleteReply(id, uint(replyID), uint(userID)); err != nil {
+		h.log.Warn("Ошибка при удалении ответа на отзыв",
+			"id", id,
+			"reply_id", replyID,
+			"error", err.Error())
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "ответ удален"})
+}
+
+func (h *ReviewsHandler) Vote(c *gin.Context) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	var req models.VoteReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Неверный формат данных",
+			"error":   err.Error()})
+		return
+	}
+	if req.Helpful == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "поле helpful обязательно"})
+		return
+	}
+
+	h.vote(c, id, req)
+}
+
+func (h *ReviewsHandler) Unvote(c *gin.Context) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "некорректный UserID"})
+		return
+	}
+
+	h.vote(c, id, models.VoteReviewRequest{UserID: uint(userID)})
+}
+
+func (h *ReviewsHandler) vote(c *gin.Context, id uint, req models.VoteReviewRequest) {
+	review, err := h.review.VoteReview(id, req)
+	if err != nil {
+		h.log.Warn("Ошибка при голосовании за отзыв",
+			"id", id,
+			"error", err.Error())
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+func (h *ReviewsHandler) Report(c *gin.Context) {
+	id, ok := parseReviewID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReportReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Неверный формат данных",
+			"error":   err.Error()})
+		return
+	}
+
+	if err := h.review.ReportReview(id, req); err != nil {
+		h.log.Warn("Ошибка при отправке жалобы",
+			"id", id,
+			"error", err.Error())
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "жалоба отправлена"})
+}
+
+func parseReviewID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "некорректный ID отзыва"})
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrReviewNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrReviewNotPurchased), errors.Is(err, service.ErrReplyForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrReviewExists), errors.Is(err, service.ErrReviewReported):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrOwnReview):
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
 }
@@ -17,24 +17,70 @@ func RegisterRoutes(
 	user service.UserService,
 	sub service.SubscriptionService,
 	reviews service.ReviewsService,
+	versions service.CatalogVersionService,
+	transfer service.CatalogTransferService,
+	outbox service.NotificationOutboxService,
+	notifications service.NotificationService,
+	gifts service.GiftService,
+	promo service.PromoService,
+	cart service.CartService,
+	orders service.OrderService,
+	prices service.PriceService,
+	wallet service.WalletService,
+	userSubs service.UserSubscriptionService,
+	attachments service.AttachmentService,
+	coaches service.CoachService,
+	recommendations service.RecommendationService,
 ) {
 
-	subHandler := NewSubscriptionHandler(sub, log)
-	categoryHandler := NewCategoryHandler(category, log)
-	planHandler := NewExercisePlanHandler(plan, log)
+	subHandler := NewSubscriptionHandler(sub, coaches, log)
+	categoryHandler := NewCategoryHandler(category, coaches, userSubs, log)
+	planHandler := NewExercisePlanHandler(plan, coaches, userSubs, log)
 	bmiHand := NewBmiHandler(log)
-	userHandler := NewUserHandler(user, log)
-	mealPlanHandler := NewMealPlanHandler(mealPlan, log)
-	mealPlanItemHandler := NewMealPlanItemHandler(mealPlanItem, log)
+	calculatorHandler := NewCalculatorHandler(log)
+	userHandler := NewUserHandler(user, coaches, log)
+	mealPlanHandler := NewMealPlanHandler(mealPlan, coaches, userSubs, log)
+	mealPlanItemHandler := NewMealPlanItemHandler(mealPlanItem, coaches, userSubs, log)
 	reviewsHandler := NewReviewsHandler(reviews, log)
+	moderationHandler := NewReviewModerationHandler(reviews, coaches, log)
+	versionsHandler := NewCatalogVersionHandler(versions, coaches, log)
+	transferHandler := NewCatalogTransferHandler(transfer, coaches, log)
+	outboxHandler := NewNotificationOutboxHandler(outbox, coaches, log)
+	notificationHandler := NewNotificationHandler(notifications, log)
+	giftHandler := NewGiftHandler(gifts, log)
+	promoHandler := NewPromoHandler(promo, coaches, log)
+	cartHandler := NewCartHandler(cart, log)
+	orderHandler := NewOrderHandler(orders, log)
+	priceHandler := NewPriceHandler(prices, coaches, log)
+	walletHandler := NewWalletHandler(wallet, log)
+	userSubHandler := NewUserSubscriptionHandler(userSubs, log)
+	attachmentHandler := NewAttachmentHandler(attachments, log)
+	coachHandler := NewCoachHandler(coaches, log)
+	recommendationHandler := NewRecommendationHandler(recommendations, log)
 
 	mealPlanHandler.RegisterRoutes(router)
 	mealPlanItemHandler.RegisterRoutes(router)
 	categoryHandler.RegisterRoutes(router)
 	planHandler.RegisterRoutes(router)
 	bmiHand.RegisterRoutes(router)
+	calculatorHandler.RegisterRoutes(router)
 	userHandler.UserRoutes(router)
 	subHandler.RegisterRoutes(router)
 	reviewsHandler.RegisterRoutes(router)
+	moderationHandler.RegisterRoutes(router)
+	versionsHandler.RegisterRoutes(router)
+	transferHandler.RegisterRoutes(router)
+	outboxHandler.RegisterRoutes(router)
+	notificationHandler.RegisterRoutes(router)
+	giftHandler.RegisterRoutes(router)
+	promoHandler.RegisterRoutes(router)
+	cartHandler.RegisterRoutes(router)
+	orderHandler.RegisterRoutes(router)
+	priceHandler.RegisterRoutes(router)
+	walletHandler.RegisterRoutes(router)
+	userSubHandler.RegisterRoutes(router)
+	attachmentHandler.RegisterRoutes(router)
+	coachHandler.RegisterRoutes(router)
+	recommendationHandler.RegisterRoutes(router)
 
 }
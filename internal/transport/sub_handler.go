@@ -20,25 +20,29 @@ type SubscriptionResponse struct {
 }
 
 type SubscriptionHandler struct {
-	sub service.SubscriptionService
-	log *slog.Logger
+	sub     service.SubscriptionService
+	coaches service.CoachService
+	log     *slog.Logger
 }
 
-func NewSubscriptionHandler(sub service.SubscriptionService, log *slog.Logger) *SubscriptionHandler {
+func NewSubscriptionHandler(sub service.SubscriptionService, coaches service.CoachService, log *slog.Logger) *SubscriptionHandler {
 	return &SubscriptionHandler{
-		sub: sub,
-		log: log,
+		sub:     sub,
+		coaches: coaches,
+		log:     log,
 	}
 }
 
 func (h *SubscriptionHandler) RegisterRoutes(r *gin.Engine) {
+	owner := requireOwner(h.coaches, models.CatalogSubscription)
+
 	subGroup := r.Group("/sub")
 	{
-		subGroup.POST("/", h.CreateSub)
+		subGroup.POST("/", requireAuthor(h.coaches), h.CreateSub)
 		subGroup.GET("/", h.GetListSub)
 		subGroup.GET("/:id", h.GetByID)
-		subGroup.PATCH("/:id", h.Update)
-		subGroup.DELETE("/:id", h.Delete)
+		subGroup.PATCH("/:id", owner, h.Update)
+		subGroup.DELETE("/:id", owner, h.Delete)
 	}
 }
 
@@ -49,6 +53,9 @@ func (h *SubscriptionHandler) CreateSub(r *gin.Context) {
 		r.IndentedJSON(http.StatusBadRequest, err.Error())
 		return
 	}
+	if !canEditCatalog(r, h.coaches, models.CatalogCategory, inputSub.CategoriesID) {
+		return
+	}
 
 	sub, err := h.sub.CreateSub(&inputSub)
 	if err != nil {
@@ -108,6 +115,9 @@ func (h *SubscriptionHandler) Update(r *gin.Context) {
 		r.IndentedJSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
+	if upSub.CategoriesID != nil && !canEditCatalog(r, h.coaches, models.CatalogCategory, *upSub.CategoriesID) {
+		return
+	}
 
 	sub, err := h.sub.UpdateSub(uint(id), upSub)
 	if err != nil {
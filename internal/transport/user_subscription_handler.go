@@ -0,0 +1,146 @@
+package transport
+
+import (
+	"healthy_body/internal/models"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserSubscriptionHandler struct {
+	subs service.UserSubscriptionService
+	log  *slog.Logger
+}
+
+func NewUserSubscriptionHandler(subs service.UserSubscriptionService, log *slog.Logger) *UserSubscriptionHandler {
+	return &UserSubscriptionHandler{
+		subs: subs,
+		log:  log,
+	}
+}
+
+func (h *UserSubscriptionHandler) RegisterRoutes(r *gin.Engine) {
+	users := r.Group("/users/:id")
+	{
+		users.GET("/entitlements", h.Entitlements)
+		users.GET("/access/:categoryID", h.Access)
+		users.GET("/subscriptions", h.List)
+		users.POST("/subscriptions/:subID/change", h.Change)
+		users.POST("/subscriptions/:subID/pause", h.action(service.UserSubscriptionService.Pause))
+		users.POST("/subscriptions/:subID/resume", h.action(service.UserSubscriptionService.Resume))
+		users.POST("/subscriptions/:subID/cancel", h.action(service.UserSubscriptionService.Cancel))
+		users.DELETE("/subscriptions/:subID/cancel", h.action(service.UserSubscriptionService.Reactivate))
+	}
+}
+
+func (h *UserSubscriptionHandler) List(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	subs, err := h.subs.List(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subs)
+}
+
+func (h *UserSubscriptionHandler) Entitlements(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	entitlements, err := h.subs.Entitlements(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entitlements)
+}
+
+func (h *UserSubscriptionHandler) Access(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	categoryID, err := strconv.ParseUint(c.Param("categoryID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID категории"})
+		return
+	}
+
+	access, err := h.subs.HasAccess(uint(id), uint(categoryID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories_id": categoryID, "access": access})
+}
+
+func (h *UserSubscriptionHandler) Change(c *gin.Context) {
+	id, subID, ok := parseUserSubscriptionIDs(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangeSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Некорректный запрос смены тарифа", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.subs.Change(id, subID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *UserSubscriptionHandler) action(apply func(subs service.UserSubscriptionService, userID, userSubID uint) (*models.UserSubscription, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, subID, ok := parseUserSubscriptionIDs(c)
+		if !ok {
+			return
+		}
+
+		userSub, err := apply(h.subs, id, subID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, userSub)
+	}
+}
+
+func parseUserSubscriptionIDs(c *gin.Context) (uint, uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return 0, 0, false
+	}
+
+	subID, err := strconv.ParseUint(c.Param("subID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID подписки"})
+		return 0, 0, false
+	}
+
+	return uint(id), uint(subID), true
+}
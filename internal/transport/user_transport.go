@@ -11,12 +11,13 @@ import (
 )
 
 type UserHandler struct {
-	user service.UserService
-	log  *slog.Logger
+	user    service.UserService
+	coaches service.CoachService
+	log     *slog.Logger
 }
 
-func NewUserHandler(user service.UserService, log *slog.Logger) *UserHandler {
-	return &UserHandler{user: user, log: log}
+func NewUserHandler(user service.UserService, coaches service.CoachService, log *slog.Logger) *UserHandler {
+	return &UserHandler{user: user, coaches: coaches, log: log}
 }
 
 func (h *UserHandler) Create(c *gin.Context) {
@@ -97,6 +98,34 @@ func (h *UserHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// SetRole changes the role of the user in :id; only an admin, named by the
+// user_id query parameter, gets here.
+func (h *UserHandler) SetRole(c *gin.Context) {
+	var req models.SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Введены неверные данные", "err", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Неверный формат данных", "error": err.Error()})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.Warn("Некорректный ID")
+		c.JSON(http.StatusBadRequest, gin.H{"message": "некорректный ID"})
+		return
+	}
+
+	result, err := h.user.SetRole(uint(id), req.Role)
+	if err != nil {
+		h.log.Error("ошибка при смене роли", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Роль изменена", "id", id, "role", req.Role)
+	c.JSON(http.StatusOK, result)
+}
+
 func (h *UserHandler) Delete(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
@@ -140,7 +169,7 @@ func (h *UserHandler) Payment(c *gin.Context) {
 		return
 	}
 
-	if err := h.user.Payment(uint(userID), uint(categoryID)); err != nil {
+	if err := h.user.Payment(uint(userID), uint(categoryID), c.Query("promo_code"), c.Query("currency")); err != nil {
 		h.log.Error("Ошибка при оплате",
 			"error", err)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -299,7 +328,7 @@ func (h *UserHandler) SubPayment(c *gin.Context) {
 		})
 		return
 	}
-	if err := h.user.SubPayment(uint(userID), uint(subID)); err != nil {
+	if err := h.user.SubPayment(uint(userID), uint(subID), c.Query("promo_code"), c.Query("currency")); err != nil {
 		h.log.Error("Ошибка при оплате подписки")
 		c.JSON(http.StatusBadRequest, gin.H{
 			"err": err,
@@ -325,6 +354,7 @@ func (h *UserHandler) UserRoutes(r *gin.Engine) {
 		userGroup.GET("/userplans/:id", h.GetUserCategory)
 		userGroup.GET("/usersub/:userID", h.GetUserSubs)
 		userGroup.PATCH("/:id", h.Update)
+		userGroup.PUT("/:id/role", requireAdmin(h.coaches), h.SetRole)
 		userGroup.DELETE("/:id", h.Delete)
 	}
 }
@@ -0,0 +1,70 @@
+package transport
+
+import (
+	"healthy_body/internal/models"
+	"healthy_body/internal/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WalletHandler struct {
+	wallet service.WalletService
+	log    *slog.Logger
+}
+
+func NewWalletHandler(wallet service.WalletService, log *slog.Logger) *WalletHandler {
+	return &WalletHandler{
+		wallet: wallet,
+		log:    log,
+	}
+}
+
+func (h *WalletHandler) RegisterRoutes(r *gin.Engine) {
+	users := r.Group("/users/:id")
+	{
+		users.GET("/balances", h.List)
+		users.POST("/balances", h.TopUp)
+	}
+}
+
+func (h *WalletHandler) List(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	balances, err := h.wallet.Balances(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, balances)
+}
+
+func (h *WalletHandler) TopUp(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		return
+	}
+
+	var req models.TopUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Некорректный запрос пополнения", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	balances, err := h.wallet.TopUp(uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, balances)
+}